@@ -106,6 +106,13 @@ func setDefaultValue(v *viper.Viper) {
 	v.SetDefault("SWAGGER_DOCS_DIR", "resources/swagger")
 	v.SetDefault("RESUME_PIPELINES", true)
 	v.SetDefault("CORS_ALLOW_ORIGIN", "*")
+	v.SetDefault("METRICS_ALLOW_ANONYMOUS", false)
+	v.SetDefault("WORKER_LEASE_TTL", "60s")
+	v.SetDefault("WORKER_HEARTBEAT_INTERVAL", "15s")
+	v.SetDefault("WORKER_SCHEDULE_BLUEPRINTS", true)
+	v.SetDefault("NOTIFICATION_MAX_ATTEMPTS", 5)
+	v.SetDefault("NOTIFICATION_SUBTASK_RETRIED_THRESHOLD", 2)
+	v.SetDefault("SMTP_PORT", 587)
 }
 
 func init() {
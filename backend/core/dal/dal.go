@@ -143,6 +143,9 @@ type Dal interface {
 	UpdateColumn(entityOrTable interface{}, columnName string, value interface{}, clauses ...Clause) errors.Error
 	// UpdateColumns allows you to update multiple columns of multiple records
 	UpdateColumns(entityOrTable interface{}, set []DalSet, clauses ...Clause) errors.Error
+	// UpdateColumnsAffected is UpdateColumns returning the number of records updated, so a conditional update
+	// could tell if it won against the concurrent ones
+	UpdateColumnsAffected(entityOrTable interface{}, set []DalSet, clauses ...Clause) (int64, errors.Error)
 	// UpdateAllColumn updated all Columns of entity
 	UpdateAllColumn(entity interface{}, clauses ...Clause) errors.Error
 	// CreateOrUpdate tries to create the record, or fallback to update all if failed
@@ -0,0 +1,141 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "devlake"
+
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Registry holds all the metrics exported by the /metrics endpoint
+var Registry = prometheus.NewRegistry()
+
+var (
+	taskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_duration_seconds",
+		Help:      "Duration of the tasks by plugin and status.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"plugin", "status"})
+	taskFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_failures_total",
+		Help:      "Number of failed tasks by plugin.",
+	}, []string{"plugin"})
+	subtaskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "subtask_duration_seconds",
+		Help:      "Duration of the subtask attempts by plugin, subtask and status.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 4, 10),
+	}, []string{"plugin", "subtask", "status"})
+	subtaskFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "subtask_failures_total",
+		Help:      "Number of failed subtask attempts by plugin and subtask.",
+	}, []string{"plugin", "subtask"})
+	subtaskRecords = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "subtask_records_total",
+		Help:      "Number of records processed by plugin and subtask, as reported by the subtask progress.",
+	}, []string{"plugin", "subtask"})
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_client_requests_total",
+		Help:      "Number of requests sent by the api clients by plugin, connection, method and status code, 0 if no response was received.",
+	}, []string{"plugin", "connection_id", "method", "code"})
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_client_request_duration_seconds",
+		Help:      "Latency of the requests sent by the api clients by plugin and connection.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"plugin", "connection_id"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: namespace}),
+		taskDuration,
+		taskFailures,
+		subtaskDuration,
+		subtaskFailures,
+		subtaskRecords,
+		apiRequests,
+		apiRequestDuration,
+	)
+}
+
+// MustRegister registers extra collectors, i.e. the ones reading their values from the database when scraped
+func MustRegister(cs ...prometheus.Collector) {
+	Registry.MustRegister(cs...)
+}
+
+// Handler serves the metrics in the Prometheus text format, or OpenMetrics if the scraper accepts it
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// ObserveTask records the duration and the outcome of a task
+func ObserveTask(plugin string, duration time.Duration, err error) {
+	status := statusOf(err)
+	taskDuration.WithLabelValues(plugin, status).Observe(duration.Seconds())
+	if err != nil {
+		taskFailures.WithLabelValues(plugin).Inc()
+	}
+}
+
+// ObserveSubtask records the duration and the outcome of a subtask attempt
+func ObserveSubtask(plugin, subtask string, duration time.Duration, err error) {
+	status := statusOf(err)
+	subtaskDuration.WithLabelValues(plugin, subtask, status).Observe(duration.Seconds())
+	if err != nil {
+		subtaskFailures.WithLabelValues(plugin, subtask).Inc()
+	}
+}
+
+// AddSubtaskRecords counts the records processed by a subtask
+func AddSubtaskRecords(plugin, subtask string, quantity int) {
+	if quantity > 0 {
+		subtaskRecords.WithLabelValues(plugin, subtask).Add(float64(quantity))
+	}
+}
+
+// ObserveApiRequest records a request sent by an api client, statusCode is 0 when no response was received
+func ObserveApiRequest(plugin, connectionId, method string, statusCode int, duration time.Duration) {
+	apiRequests.WithLabelValues(plugin, connectionId, method, strconv.Itoa(statusCode)).Inc()
+	apiRequestDuration.WithLabelValues(plugin, connectionId).Observe(duration.Seconds())
+}
+
+func statusOf(err error) string {
+	if err != nil {
+		return StatusFailed
+	}
+	return StatusSucceeded
+}
@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveTask("jira", 2*time.Minute, fmt.Errorf("boom"))
+	ObserveSubtask("jira", "collectIssues", time.Second, nil)
+	AddSubtaskRecords("jira", "collectIssues", 100)
+	AddSubtaskRecords("jira", "collectIssues", 0)
+	ObserveApiRequest("jira", "1", http.MethodGet, http.StatusOK, 200*time.Millisecond)
+
+	res := httptest.NewRecorder()
+	Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+	body, err := io.ReadAll(res.Body)
+	assert.Nil(t, err)
+	output := string(body)
+	assert.Contains(t, output, `devlake_task_duration_seconds_count{plugin="jira",status="failed"} 1`)
+	assert.Contains(t, output, `devlake_task_failures_total{plugin="jira"} 1`)
+	assert.Contains(t, output, `devlake_subtask_duration_seconds_count{plugin="jira",status="succeeded",subtask="collectIssues"} 1`)
+	assert.Contains(t, output, `devlake_subtask_records_total{plugin="jira",subtask="collectIssues"} 100`)
+	assert.Contains(t, output, `devlake_api_client_requests_total{code="200",connection_id="1",method="GET",plugin="jira"} 1`)
+}
@@ -0,0 +1,50 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	AUDIT_ALLOWED = "ALLOWED"
+	AUDIT_DENIED  = "DENIED"
+)
+
+// AuditLog records an api call made against DevLake and who made it. For calls changing an entity, `Before`
+// and `After` hold the changed fields of the entity with the secrets redacted.
+type AuditLog struct {
+	common.Model
+	Actor       string                 `json:"actor" gorm:"type:varchar(255);index"`
+	ActorEmail  string                 `json:"actorEmail" gorm:"type:varchar(255)"`
+	ApiKeyId    uint64                 `json:"apiKeyId"`
+	Method      string                 `json:"method" gorm:"type:varchar(10)"`
+	Endpoint    string                 `json:"endpoint"`
+	TargetType  string                 `json:"targetType" gorm:"type:varchar(255);index"`
+	TargetId    string                 `json:"targetId" gorm:"type:varchar(255);index"`
+	ProjectName string                 `json:"projectName" gorm:"type:varchar(255);index"`
+	Outcome     string                 `json:"outcome" gorm:"type:varchar(20);index"`
+	Status      int                    `json:"status"`
+	Message     string                 `json:"message"`
+	Before      map[string]interface{} `json:"before" gorm:"type:json;serializer:json"`
+	After       map[string]interface{} `json:"after" gorm:"type:json;serializer:json"`
+}
+
+func (AuditLog) TableName() string {
+	return "_devlake_audit_logs"
+}
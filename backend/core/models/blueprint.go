@@ -87,4 +87,7 @@ type SyncPolicy struct {
 	SkipCollectors bool       `json:"skipCollectors"`
 	FullSync       bool       `json:"fullSync"`
 	TimeAfter      *time.Time `json:"timeAfter"`
+	// Reprocess reruns only the subtasks depending on the raw data already collected, without hitting the source
+	// APIs, and reports how many domain rows were changed
+	Reprocess bool `json:"reprocess"`
 }
@@ -0,0 +1,56 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package code
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// CodeOwner is an owner of a rule of the CODEOWNERS file found at the HEAD of the repo, a rule without owner unsets
+// the ownership of the files it matches and is stored with an empty Owner.
+type CodeOwner struct {
+	RepoId    string `gorm:"primaryKey;type:varchar(255)" json:"repoId"`
+	LineNo    int    `gorm:"primaryKey" json:"lineNo"`
+	Owner     string `gorm:"primaryKey;type:varchar(255)" json:"owner"`
+	CommitSha string `gorm:"type:varchar(40)" json:"commitSha"`
+	FilePath  string `gorm:"type:varchar(255)" json:"filePath"`
+	Section   string `gorm:"type:varchar(255)" json:"section"`
+	Optional  bool   `json:"optional"`
+	Pattern   string `gorm:"type:varchar(255)" json:"pattern"`
+	common.NoPKModel
+}
+
+func (CodeOwner) TableName() string {
+	return "code_owners"
+}
+
+// PullRequestCodeOwner is an owner required to review a pull request because it owns some of the files changed by
+// the pull request
+type PullRequestCodeOwner struct {
+	PullRequestId              string `gorm:"primaryKey;type:varchar(255)" json:"pullRequestId"`
+	Owner                      string `gorm:"primaryKey;type:varchar(255)" json:"owner"`
+	FileCount                  int    `json:"fileCount"`
+	Reviewed                   bool   `json:"reviewed"`
+	Approved                   bool   `json:"approved"`
+	MergedWithoutOwnerApproval bool   `json:"mergedWithoutOwnerApproval"`
+	common.NoPKModel
+}
+
+func (PullRequestCodeOwner) TableName() string {
+	return "pull_request_code_owners"
+}
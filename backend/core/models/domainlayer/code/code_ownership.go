@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package code
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	CODE_OWNERSHIP_FILE      = "FILE"
+	CODE_OWNERSHIP_DIRECTORY = "DIRECTORY"
+)
+
+// CodeOwnership is the share of the surviving lines of a file or a directory written by an author, according to the
+// blame of the repo at SnapshotCommitSha (see RepoSnapshot). The root directory of the repo is ".".
+type CodeOwnership struct {
+	Id                uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	RepoId            string  `gorm:"type:varchar(255);index:idx_code_ownerships_snapshot" json:"repoId"`
+	SnapshotCommitSha string  `gorm:"type:varchar(40);index:idx_code_ownerships_snapshot" json:"snapshotCommitSha"`
+	Path              string  `gorm:"type:varchar(255)" json:"path"`
+	PathType          string  `gorm:"type:varchar(20)" json:"pathType"`
+	AuthorId          string  `gorm:"type:varchar(255)" json:"authorId"`
+	AuthorName        string  `gorm:"type:varchar(255)" json:"authorName"`
+	LineCount         int     `json:"lineCount"`
+	TotalLineCount    int     `json:"totalLineCount"`
+	Share             float64 `json:"share"`
+	Rank              int     `json:"rank"`
+	common.NoPKModel
+}
+
+func (CodeOwnership) TableName() string {
+	return "code_ownerships"
+}
+
+// ComponentBusFactor tells how much the knowledge of a component is concentrated on a few authors. BusFactor is the
+// smallest number of authors owning together more than half of the surviving lines, KnowledgeConcentration is the
+// sum of the squared shares of the authors, 1 when a single author wrote everything.
+type ComponentBusFactor struct {
+	Id                     uint64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	RepoId                 string  `gorm:"type:varchar(255);index:idx_component_bus_factors_snapshot" json:"repoId"`
+	SnapshotCommitSha      string  `gorm:"type:varchar(40);index:idx_component_bus_factors_snapshot" json:"snapshotCommitSha"`
+	ComponentName          string  `gorm:"type:varchar(255)" json:"componentName"`
+	FileCount              int     `json:"fileCount"`
+	LineCount              int     `json:"lineCount"`
+	AuthorCount            int     `json:"authorCount"`
+	BusFactor              int     `json:"busFactor"`
+	KnowledgeConcentration float64 `json:"knowledgeConcentration"`
+	TopAuthorId            string  `gorm:"type:varchar(255)" json:"topAuthorId"`
+	TopAuthorName          string  `gorm:"type:varchar(255)" json:"topAuthorName"`
+	TopAuthorShare         float64 `json:"topAuthorShare"`
+	common.NoPKModel
+}
+
+func (ComponentBusFactor) TableName() string {
+	return "component_bus_factors"
+}
+
+// FileChurn is the amount of changes made to a file during a month, the files changed the most are the hotspots
+type FileChurn struct {
+	Id                uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	RepoId            string    `gorm:"type:varchar(255);index:idx_file_churns_snapshot" json:"repoId"`
+	SnapshotCommitSha string    `gorm:"type:varchar(40);index:idx_file_churns_snapshot" json:"snapshotCommitSha"`
+	FilePath          string    `gorm:"type:varchar(255)" json:"filePath"`
+	PeriodStart       time.Time `json:"periodStart"`
+	CommitCount       int       `json:"commitCount"`
+	AuthorCount       int       `json:"authorCount"`
+	Additions         int       `json:"additions"`
+	Deletions         int       `json:"deletions"`
+	Churn             int       `json:"churn"`
+	common.NoPKModel
+}
+
+func (FileChurn) TableName() string {
+	return "file_churns"
+}
@@ -0,0 +1,54 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crossdomain
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	DORA_PERIOD_WEEK  = "WEEK"
+	DORA_PERIOD_MONTH = "MONTH"
+)
+
+// ProjectDoraMetric is the rollup of the four key DORA metrics of a project and environment over a week or month
+type ProjectDoraMetric struct {
+	ProjectName string    `gorm:"primaryKey;type:varchar(100)" json:"projectName"`
+	Environment string    `gorm:"primaryKey;type:varchar(255)" json:"environment"`
+	PeriodType  string    `gorm:"primaryKey;type:varchar(20)" json:"periodType"`
+	PeriodStart time.Time `gorm:"primaryKey" json:"periodStart"`
+	// deployment frequency
+	DeploymentCount int `json:"deploymentCount"`
+	DeploymentDays  int `json:"deploymentDays"`
+	// lead time for changes, of the pull requests deployed in the period
+	ChangeCount           int    `json:"changeCount"`
+	MedianLeadTimeMinutes *int64 `json:"medianLeadTimeMinutes"`
+	// change failure rate, of the deployments finished in the period
+	FailedDeploymentCount int      `json:"failedDeploymentCount"`
+	ChangeFailureRate     *float64 `json:"changeFailureRate"`
+	// time to restore service, of the incidents resolved in the period
+	IncidentCount              int    `json:"incidentCount"`
+	MedianTimeToRestoreMinutes *int64 `json:"medianTimeToRestoreMinutes"`
+	common.NoPKModel
+}
+
+func (ProjectDoraMetric) TableName() string {
+	return "project_dora_metrics"
+}
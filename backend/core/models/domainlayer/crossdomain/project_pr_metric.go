@@ -32,6 +32,8 @@ type ProjectPrMetric struct {
 	DeploymentCommitId string
 	PrDeployTime       *int64
 	PrCycleTime        *int64
+	// AuthorTeamId is the team the author belonged to when the pull request was created
+	AuthorTeamId string `gorm:"type:varchar(255)"`
 }
 
 func (ProjectPrMetric) TableName() string {
@@ -0,0 +1,83 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crossdomain
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+)
+
+// ProjectPrReviewMetric describes how a pull request of a project was reviewed, a review being any comment or
+// review left by someone other than the author of the pull request
+type ProjectPrReviewMetric struct {
+	domainlayer.DomainEntity
+	ProjectName   string `gorm:"primaryKey;type:varchar(100)"`
+	ReviewerCount int
+	CommentCount  int
+	// ReviewRounds is the number of reviews separated by commits pushed in between, CommitsAfterFirstReview the
+	// number of commits authored after the first review
+	ReviewRounds            int
+	CommitsAfterFirstReview int
+	ApprovalsBeforeMerge    int
+	FirstReviewDate         *time.Time
+	MergedWithoutReview     bool
+}
+
+func (ProjectPrReviewMetric) TableName() string {
+	return "project_pr_review_metrics"
+}
+
+// ProjectPrReviewerMetric describes the reviews of a pull request by one of its requested reviewers or commenters
+type ProjectPrReviewerMetric struct {
+	ProjectName         string `gorm:"primaryKey;type:varchar(100)"`
+	PullRequestId       string `gorm:"primaryKey;type:varchar(255)"`
+	ReviewerId          string `gorm:"primaryKey;type:varchar(255)"`
+	Requested           bool
+	CommentCount        int
+	Approved            bool
+	FirstResponseDate   *time.Time
+	ResponseTimeMinutes *int64
+	common.NoPKModel
+}
+
+func (ProjectPrReviewerMetric) TableName() string {
+	return "project_pr_reviewer_metrics"
+}
+
+// ProjectReviewerWeeklyMetric is the review load of a reviewer over a week starting on Monday
+type ProjectReviewerWeeklyMetric struct {
+	ProjectName string    `gorm:"primaryKey;type:varchar(100)" json:"projectName"`
+	ReviewerId  string    `gorm:"primaryKey;type:varchar(255)" json:"reviewerId"`
+	WeekStart   time.Time `gorm:"primaryKey" json:"weekStart"`
+	// RequestedPrCount counts the pull requests created in the week the reviewer was requested on
+	RequestedPrCount int `json:"requestedPrCount"`
+	// ReviewedPrCount counts the pull requests the reviewer commented on during the week
+	ReviewedPrCount int `json:"reviewedPrCount"`
+	CommentCount    int `json:"commentCount"`
+	ApprovalCount   int `json:"approvalCount"`
+	// MedianResponseTimeMinutes is the median time to the first response of the reviewer, of the pull requests
+	// first responded to during the week
+	MedianResponseTimeMinutes *int64 `json:"medianResponseTimeMinutes"`
+	common.NoPKModel
+}
+
+func (ProjectReviewerWeeklyMetric) TableName() string {
+	return "project_reviewer_weekly_metrics"
+}
@@ -0,0 +1,39 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crossdomain
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// ProjectTeamAttribution attributes a commit, a pull request or an issue of a project to the team its author
+// belonged to at the time, and to the ancestors of that team so that work rolls up the team hierarchy
+type ProjectTeamAttribution struct {
+	ProjectName string `gorm:"primaryKey;type:varchar(100)"`
+	// Table is commits, pull_requests or issues
+	Table  string `gorm:"primaryKey;type:varchar(50)"`
+	RowId  string `gorm:"primaryKey;type:varchar(255)"`
+	TeamId string `gorm:"primaryKey;type:varchar(255)"`
+	// Depth is 0 for the team of the author, 1 for its parent team and so on
+	Depth int
+	common.NoPKModel
+}
+
+func (ProjectTeamAttribution) TableName() string {
+	return "project_team_attributions"
+}
@@ -0,0 +1,51 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crossdomain
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// TeamMembership is the period a user belongs to a team, from StartDate inclusive to EndDate exclusive. A nil
+// date leaves the period open on that side. Unlike team_users, which only tells the current teams of a user,
+// it keeps the history of the moves between teams.
+type TeamMembership struct {
+	Id        uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TeamId    string     `gorm:"type:varchar(255);index" json:"teamId"`
+	UserId    string     `gorm:"type:varchar(255);index" json:"userId"`
+	StartDate *time.Time `json:"startDate"`
+	EndDate   *time.Time `json:"endDate"`
+	common.NoPKModel
+}
+
+func (TeamMembership) TableName() string {
+	return "team_memberships"
+}
+
+// IsEffectiveAt tells whether the user belongs to the team at the given time
+func (m TeamMembership) IsEffectiveAt(t time.Time) bool {
+	return (m.StartDate == nil || !t.Before(*m.StartDate)) && (m.EndDate == nil || t.Before(*m.EndDate))
+}
+
+// Overlaps tells whether the two periods have any time in common
+func (m TeamMembership) Overlaps(other TeamMembership) bool {
+	return (m.StartDate == nil || other.EndDate == nil || m.StartDate.Before(*other.EndDate)) &&
+		(other.StartDate == nil || m.EndDate == nil || other.StartDate.Before(*m.EndDate))
+}
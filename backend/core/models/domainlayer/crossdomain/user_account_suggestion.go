@@ -0,0 +1,45 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crossdomain
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	USER_ACCOUNT_SUGGESTION_PENDING  = "PENDING"
+	USER_ACCOUNT_SUGGESTION_APPROVED = "APPROVED"
+	USER_ACCOUNT_SUGGESTION_REJECTED = "REJECTED"
+)
+
+// UserAccountSuggestion is a user_accounts row proposed by identity resolution, waiting to be reviewed.
+// The user does not exist yet when it is proposed for a cluster of accounts no user is known for, it is created
+// from the account on approval.
+type UserAccountSuggestion struct {
+	AccountId  string  `gorm:"primaryKey;type:varchar(255)" json:"accountId"`
+	UserId     string  `gorm:"primaryKey;type:varchar(255)" json:"userId"`
+	Confidence float64 `json:"confidence"`
+	// Reasons lists the signals linking the account to the user, separated by commas
+	Reasons string `gorm:"type:varchar(255)" json:"reasons"`
+	Status  string `gorm:"type:varchar(20);index" json:"status"`
+	common.NoPKModel
+}
+
+func (UserAccountSuggestion) TableName() string {
+	return "user_account_suggestions"
+}
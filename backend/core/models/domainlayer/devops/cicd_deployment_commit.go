@@ -43,9 +43,16 @@ type CicdDeploymentCommit struct {
 	RepoId                        string `gorm:"type:varchar(255)"`
 	RepoUrl                       string `gorm:"index;not null"`
 	PrevSuccessDeploymentCommitId string `gorm:"type:varchar(255)"`
+	DeploymentType                string `gorm:"type:varchar(20)"`
 	SubtaskName                   string `gorm:"type:varchar(255)"`
 }
 
+const (
+	DEPLOYMENT_TYPE_FORWARD  = "FORWARD"
+	DEPLOYMENT_TYPE_ROLLBACK = "ROLLBACK"
+	DEPLOYMENT_TYPE_HOTFIX   = "HOTFIX"
+)
+
 func (cicdDeploymentCommit CicdDeploymentCommit) TableName() string {
 	return "cicd_deployment_commits"
 }
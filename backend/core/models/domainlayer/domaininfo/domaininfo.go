@@ -29,14 +29,19 @@ import (
 func GetDomainTablesInfo() []dal.Tabler {
 	return []dal.Tabler{
 		// code
+		&code.CodeOwner{},
+		&code.CodeOwnership{},
 		&code.Commit{},
 		&code.CommitFile{},
 		&code.CommitFileComponent{},
 		&code.CommitParent{},
 		&code.Component{},
+		&code.ComponentBusFactor{},
 		&code.CommitLineChange{},
+		&code.FileChurn{},
 		&code.PullRequest{},
 		&code.PullRequestComment{},
+		&code.PullRequestCodeOwner{},
 		&code.PullRequestCommit{},
 		&code.PullRequestLabel{},
 		&code.PullRequestReviewer{},
@@ -60,14 +65,21 @@ func GetDomainTablesInfo() []dal.Tabler {
 		&crossdomain.IssueCommit{},
 		&crossdomain.IssueRepoCommit{},
 		&crossdomain.ProjectMapping{},
+		&crossdomain.ProjectDoraMetric{},
 		&crossdomain.ProjectIssueMetric{},
 		&crossdomain.ProjectPrMetric{},
+		&crossdomain.ProjectPrReviewMetric{},
+		&crossdomain.ProjectPrReviewerMetric{},
+		&crossdomain.ProjectReviewerWeeklyMetric{},
+		&crossdomain.ProjectTeamAttribution{},
 		&crossdomain.PullRequestIssue{},
 		&crossdomain.RefsIssuesDiffs{},
 		&crossdomain.Team{},
+		&crossdomain.TeamMembership{},
 		&crossdomain.TeamUser{},
 		&crossdomain.User{},
 		&crossdomain.UserAccount{},
+		&crossdomain.UserAccountSuggestion{},
 		// devops
 		&devops.CICDPipeline{},
 		&devops.CICDTask{},
@@ -0,0 +1,61 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	ROTATION_RUNNING = "RUNNING"
+	ROTATION_DONE    = "DONE"
+	ROTATION_FAILED  = "FAILED"
+)
+
+// EncryptionKeyRotation tracks the re-encryption of all `serializer:encdec` columns with the key `KeyId`.
+// Each table is rotated in its own transaction and recorded in `CompletedTables` once committed, so a failed
+// or interrupted rotation resumes from the first table not completed.
+type EncryptionKeyRotation struct {
+	common.Model
+	KeyId           string                        `json:"keyId" gorm:"type:varchar(255);index"`
+	DryRun          bool                          `json:"dryRun"`
+	Status          string                        `json:"status" gorm:"type:varchar(20)"`
+	CompletedTables []string                      `json:"completedTables" gorm:"type:json;serializer:json"`
+	Tables          []*EncryptionKeyRotationTable `json:"tables" gorm:"type:json;serializer:json"`
+	Message         string                        `json:"message"`
+	FinishedAt      *time.Time                    `json:"finishedAt"`
+}
+
+func (EncryptionKeyRotation) TableName() string {
+	return "_devlake_encryption_key_rotations"
+}
+
+// EncryptionKeyRotationTable is the progress of a table in an EncryptionKeyRotation
+type EncryptionKeyRotationTable struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	// Scanned is the number of non-empty values found
+	Scanned int `json:"scanned"`
+	// Rotated is the number of values re-encrypted, or to be re-encrypted in dry-run mode
+	Rotated int `json:"rotated"`
+	// Failed is the number of values which could not be decrypted by any key in the ring
+	Failed int    `json:"failed"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,53 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addAuditLogTargets)(nil)
+
+type auditLog20241018Target struct {
+	TargetType string `gorm:"type:varchar(255);index"`
+	TargetId   string `gorm:"type:varchar(255);index"`
+	Status     int
+	Before     string `gorm:"type:json"`
+	After      string `gorm:"type:json"`
+}
+
+func (auditLog20241018Target) TableName() string {
+	return "_devlake_audit_logs"
+}
+
+type addAuditLogTargets struct{}
+
+func (*addAuditLogTargets) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &auditLog20241018Target{})
+}
+
+func (*addAuditLogTargets) Version() uint64 {
+	return 20241018201746
+}
+
+func (*addAuditLogTargets) Name() string {
+	return "add target and before/after diff to _devlake_audit_logs"
+}
@@ -0,0 +1,76 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addNotificationChannels)(nil)
+
+type notificationChannel20241018 struct {
+	archived.Model
+	Name        string
+	Type        string `gorm:"type:varchar(20)"`
+	Endpoint    string
+	Secret      string
+	Events      string `gorm:"type:json"`
+	BlueprintId uint64 `gorm:"index"`
+	ProjectName string `gorm:"type:varchar(255);index"`
+	Enable      bool
+}
+
+func (notificationChannel20241018) TableName() string {
+	return "_devlake_notification_channels"
+}
+
+type notification20241018 struct {
+	ChannelId   uint64 `gorm:"index"`
+	Status      string `gorm:"type:varchar(20);index"`
+	Attempts    int
+	NextRetryAt *time.Time
+	Message     string
+}
+
+func (notification20241018) TableName() string {
+	return "_devlake_notifications"
+}
+
+type addNotificationChannels struct{}
+
+func (*addNotificationChannels) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&notificationChannel20241018{},
+		&notification20241018{},
+	)
+}
+
+func (*addNotificationChannels) Version() uint64 {
+	return 20241018142207
+}
+
+func (*addNotificationChannels) Name() string {
+	return "add _devlake_notification_channels and delivery status of _devlake_notifications"
+}
@@ -0,0 +1,68 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addPipelineLeases)(nil)
+
+type worker20241018 struct {
+	ID          string `gorm:"primaryKey;type:varchar(255)"`
+	HostName    string
+	Version     string
+	HeartbeatAt time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (worker20241018) TableName() string {
+	return "_devlake_workers"
+}
+
+type pipeline20241018 struct {
+	WorkerId       string `gorm:"type:varchar(255);index"`
+	LeaseExpiresAt *time.Time
+}
+
+func (pipeline20241018) TableName() string {
+	return "_devlake_pipelines"
+}
+
+type addPipelineLeases struct{}
+
+func (*addPipelineLeases) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	if err := db.AutoMigrate(&worker20241018{}); err != nil {
+		return err
+	}
+	return db.AutoMigrate(&pipeline20241018{})
+}
+
+func (*addPipelineLeases) Version() uint64 {
+	return 20241018093512
+}
+
+func (*addPipelineLeases) Name() string {
+	return "add _devlake_workers and lease columns to _devlake_pipelines"
+}
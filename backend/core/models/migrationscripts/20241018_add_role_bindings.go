@@ -0,0 +1,76 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addRoleBindings)(nil)
+
+type roleBinding20241018 struct {
+	archived.Model
+	Creator      string
+	CreatorEmail string
+	SubjectType  string `gorm:"type:varchar(20);index"`
+	Subject      string `gorm:"type:varchar(255);index"`
+	Role         string `gorm:"type:varchar(50)"`
+	ProjectName  string `gorm:"type:varchar(255);index"`
+}
+
+func (roleBinding20241018) TableName() string {
+	return "_devlake_role_bindings"
+}
+
+type auditLog20241018 struct {
+	archived.Model
+	Actor       string `gorm:"type:varchar(255);index"`
+	ActorEmail  string `gorm:"type:varchar(255)"`
+	ApiKeyId    uint64
+	Method      string `gorm:"type:varchar(10)"`
+	Endpoint    string
+	ProjectName string `gorm:"type:varchar(255);index"`
+	Outcome     string `gorm:"type:varchar(20);index"`
+	Message     string
+}
+
+func (auditLog20241018) TableName() string {
+	return "_devlake_audit_logs"
+}
+
+type addRoleBindings struct{}
+
+func (*addRoleBindings) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&roleBinding20241018{},
+		&auditLog20241018{},
+	)
+}
+
+func (*addRoleBindings) Version() uint64 {
+	return 20241018165531
+}
+
+func (*addRoleBindings) Name() string {
+	return "add _devlake_role_bindings and _devlake_audit_logs"
+}
@@ -0,0 +1,59 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addRawDataRetentionPolicies)(nil)
+
+type rawDataRetentionPolicy20241019 struct {
+	archived.Model
+	Plugin          string `gorm:"type:varchar(100);index"`
+	RawTable        string `gorm:"type:varchar(255);index"`
+	KeepCollections int
+	KeepDays        int
+	Archive         bool
+	Compress        bool
+}
+
+func (rawDataRetentionPolicy20241019) TableName() string {
+	return "_devlake_raw_data_retention_policies"
+}
+
+type addRawDataRetentionPolicies struct{}
+
+func (*addRawDataRetentionPolicies) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&rawDataRetentionPolicy20241019{},
+	)
+}
+
+func (*addRawDataRetentionPolicies) Version() uint64 {
+	return 20241019153412
+}
+
+func (*addRawDataRetentionPolicies) Name() string {
+	return "add _devlake_raw_data_retention_policies"
+}
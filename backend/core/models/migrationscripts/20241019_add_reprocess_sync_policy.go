@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addReprocessSyncPolicy)(nil)
+
+type blueprint20241019 struct {
+	Reprocess bool
+}
+
+func (blueprint20241019) TableName() string {
+	return "_devlake_blueprints"
+}
+
+type pipeline20241019 struct {
+	Reprocess bool
+}
+
+func (pipeline20241019) TableName() string {
+	return "_devlake_pipelines"
+}
+
+type reprocessReport20241019 struct {
+	PipelineId  uint64 `gorm:"primaryKey"`
+	DomainTable string `gorm:"primaryKey;type:varchar(255)"`
+	Inserted    int64
+	Updated     int64
+	Deleted     int64
+	CreatedAt   time.Time
+}
+
+func (reprocessReport20241019) TableName() string {
+	return "_devlake_reprocess_reports"
+}
+
+type addReprocessSyncPolicy struct{}
+
+func (*addReprocessSyncPolicy) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&blueprint20241019{},
+		&pipeline20241019{},
+		&reprocessReport20241019{},
+	)
+}
+
+func (*addReprocessSyncPolicy) Version() uint64 {
+	return 20241019101025
+}
+
+func (*addReprocessSyncPolicy) Name() string {
+	return "add reprocess to the sync policy of _devlake_blueprints and _devlake_pipelines"
+}
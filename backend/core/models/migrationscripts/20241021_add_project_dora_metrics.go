@@ -0,0 +1,67 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addProjectDoraMetrics)(nil)
+
+type projectDoraMetric20241021 struct {
+	ProjectName                string    `gorm:"primaryKey;type:varchar(100)"`
+	Environment                string    `gorm:"primaryKey;type:varchar(255)"`
+	PeriodType                 string    `gorm:"primaryKey;type:varchar(20)"`
+	PeriodStart                time.Time `gorm:"primaryKey"`
+	DeploymentCount            int
+	DeploymentDays             int
+	ChangeCount                int
+	MedianLeadTimeMinutes      *int64
+	FailedDeploymentCount      int
+	ChangeFailureRate          *float64
+	IncidentCount              int
+	MedianTimeToRestoreMinutes *int64
+	archived.NoPKModel
+}
+
+func (projectDoraMetric20241021) TableName() string {
+	return "project_dora_metrics"
+}
+
+type addProjectDoraMetrics struct{}
+
+func (*addProjectDoraMetrics) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&projectDoraMetric20241021{},
+	)
+}
+
+func (*addProjectDoraMetrics) Version() uint64 {
+	return 20241021093027
+}
+
+func (*addProjectDoraMetrics) Name() string {
+	return "add project_dora_metrics"
+}
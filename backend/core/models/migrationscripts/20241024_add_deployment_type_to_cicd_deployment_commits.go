@@ -0,0 +1,48 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addDeploymentTypeToCicdDeploymentCommits)(nil)
+
+type cicdDeploymentCommit20241024 struct {
+	DeploymentType string `gorm:"type:varchar(20)"`
+}
+
+func (cicdDeploymentCommit20241024) TableName() string {
+	return "cicd_deployment_commits"
+}
+
+type addDeploymentTypeToCicdDeploymentCommits struct{}
+
+func (*addDeploymentTypeToCicdDeploymentCommits) Up(basicRes context.BasicRes) errors.Error {
+	return basicRes.GetDal().AutoMigrate(&cicdDeploymentCommit20241024{})
+}
+
+func (*addDeploymentTypeToCicdDeploymentCommits) Version() uint64 {
+	return 20241024094512
+}
+
+func (*addDeploymentTypeToCicdDeploymentCommits) Name() string {
+	return "add deployment_type to cicd_deployment_commits"
+}
@@ -0,0 +1,97 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addProjectReviewMetrics)(nil)
+
+type projectPrReviewMetric20241025 struct {
+	archived.DomainEntity
+	ProjectName             string `gorm:"primaryKey;type:varchar(100)"`
+	ReviewerCount           int
+	CommentCount            int
+	ReviewRounds            int
+	CommitsAfterFirstReview int
+	ApprovalsBeforeMerge    int
+	FirstReviewDate         *time.Time
+	MergedWithoutReview     bool
+}
+
+func (projectPrReviewMetric20241025) TableName() string {
+	return "project_pr_review_metrics"
+}
+
+type projectPrReviewerMetric20241025 struct {
+	ProjectName         string `gorm:"primaryKey;type:varchar(100)"`
+	PullRequestId       string `gorm:"primaryKey;type:varchar(255)"`
+	ReviewerId          string `gorm:"primaryKey;type:varchar(255)"`
+	Requested           bool
+	CommentCount        int
+	Approved            bool
+	FirstResponseDate   *time.Time
+	ResponseTimeMinutes *int64
+	archived.NoPKModel
+}
+
+func (projectPrReviewerMetric20241025) TableName() string {
+	return "project_pr_reviewer_metrics"
+}
+
+type projectReviewerWeeklyMetric20241025 struct {
+	ProjectName               string    `gorm:"primaryKey;type:varchar(100)"`
+	ReviewerId                string    `gorm:"primaryKey;type:varchar(255)"`
+	WeekStart                 time.Time `gorm:"primaryKey"`
+	RequestedPrCount          int
+	ReviewedPrCount           int
+	CommentCount              int
+	ApprovalCount             int
+	MedianResponseTimeMinutes *int64
+	archived.NoPKModel
+}
+
+func (projectReviewerWeeklyMetric20241025) TableName() string {
+	return "project_reviewer_weekly_metrics"
+}
+
+type addProjectReviewMetrics struct{}
+
+func (*addProjectReviewMetrics) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&projectPrReviewMetric20241025{},
+		&projectPrReviewerMetric20241025{},
+		&projectReviewerWeeklyMetric20241025{},
+	)
+}
+
+func (*addProjectReviewMetrics) Version() uint64 {
+	return 20241025101204
+}
+
+func (*addProjectReviewMetrics) Name() string {
+	return "add project_pr_review_metrics, project_pr_reviewer_metrics and project_reviewer_weekly_metrics"
+}
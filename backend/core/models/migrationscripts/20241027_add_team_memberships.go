@@ -0,0 +1,74 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addTeamMemberships)(nil)
+
+type teamMembership20241027 struct {
+	Id        uint64 `gorm:"primaryKey;autoIncrement"`
+	TeamId    string `gorm:"type:varchar(255);index"`
+	UserId    string `gorm:"type:varchar(255);index"`
+	StartDate *time.Time
+	EndDate   *time.Time
+	archived.NoPKModel
+}
+
+func (teamMembership20241027) TableName() string {
+	return "team_memberships"
+}
+
+type projectPrMetric20241027 struct {
+	AuthorTeamId string `gorm:"type:varchar(255)"`
+}
+
+func (projectPrMetric20241027) TableName() string {
+	return "project_pr_metrics"
+}
+
+type addTeamMemberships struct{}
+
+func (*addTeamMemberships) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	if err := db.AutoMigrate(&teamMembership20241027{}); err != nil {
+		return err
+	}
+	// the current members of the teams have always been there as far as we know
+	err := db.Exec(`INSERT INTO team_memberships (team_id, user_id, created_at, updated_at)
+		SELECT team_id, user_id, created_at, updated_at FROM team_users`)
+	if err != nil {
+		return err
+	}
+	return db.AutoMigrate(&projectPrMetric20241027{})
+}
+
+func (*addTeamMemberships) Version() uint64 {
+	return 20241027100841
+}
+
+func (*addTeamMemberships) Name() string {
+	return "add team_memberships and author_team_id to project_pr_metrics"
+}
@@ -0,0 +1,106 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addCodeOwnership)(nil)
+
+type codeOwnership20241028 struct {
+	Id                uint64 `gorm:"primaryKey;autoIncrement"`
+	RepoId            string `gorm:"type:varchar(255);index:idx_code_ownerships_snapshot"`
+	SnapshotCommitSha string `gorm:"type:varchar(40);index:idx_code_ownerships_snapshot"`
+	Path              string `gorm:"type:varchar(255)"`
+	PathType          string `gorm:"type:varchar(20)"`
+	AuthorId          string `gorm:"type:varchar(255)"`
+	AuthorName        string `gorm:"type:varchar(255)"`
+	LineCount         int
+	TotalLineCount    int
+	Share             float64
+	Rank              int
+	archived.NoPKModel
+}
+
+func (codeOwnership20241028) TableName() string {
+	return "code_ownerships"
+}
+
+type componentBusFactor20241028 struct {
+	Id                     uint64 `gorm:"primaryKey;autoIncrement"`
+	RepoId                 string `gorm:"type:varchar(255);index:idx_component_bus_factors_snapshot"`
+	SnapshotCommitSha      string `gorm:"type:varchar(40);index:idx_component_bus_factors_snapshot"`
+	ComponentName          string `gorm:"type:varchar(255)"`
+	FileCount              int
+	LineCount              int
+	AuthorCount            int
+	BusFactor              int
+	KnowledgeConcentration float64
+	TopAuthorId            string `gorm:"type:varchar(255)"`
+	TopAuthorName          string `gorm:"type:varchar(255)"`
+	TopAuthorShare         float64
+	archived.NoPKModel
+}
+
+func (componentBusFactor20241028) TableName() string {
+	return "component_bus_factors"
+}
+
+type fileChurn20241028 struct {
+	Id                uint64 `gorm:"primaryKey;autoIncrement"`
+	RepoId            string `gorm:"type:varchar(255);index:idx_file_churns_snapshot"`
+	SnapshotCommitSha string `gorm:"type:varchar(40);index:idx_file_churns_snapshot"`
+	FilePath          string `gorm:"type:varchar(255)"`
+	PeriodStart       time.Time
+	CommitCount       int
+	AuthorCount       int
+	Additions         int
+	Deletions         int
+	Churn             int
+	archived.NoPKModel
+}
+
+func (fileChurn20241028) TableName() string {
+	return "file_churns"
+}
+
+type addCodeOwnership struct{}
+
+func (*addCodeOwnership) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&codeOwnership20241028{},
+		&componentBusFactor20241028{},
+		&fileChurn20241028{},
+	)
+}
+
+func (*addCodeOwnership) Version() uint64 {
+	return 20241028093716
+}
+
+func (*addCodeOwnership) Name() string {
+	return "add code_ownerships, component_bus_factors and file_churns"
+}
@@ -0,0 +1,76 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addCodeOwners)(nil)
+
+type codeOwner20241029 struct {
+	RepoId    string `gorm:"primaryKey;type:varchar(255)"`
+	LineNo    int    `gorm:"primaryKey"`
+	Owner     string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha string `gorm:"type:varchar(40)"`
+	FilePath  string `gorm:"type:varchar(255)"`
+	Section   string `gorm:"type:varchar(255)"`
+	Optional  bool
+	Pattern   string `gorm:"type:varchar(255)"`
+	archived.NoPKModel
+}
+
+func (codeOwner20241029) TableName() string {
+	return "code_owners"
+}
+
+type pullRequestCodeOwner20241029 struct {
+	PullRequestId              string `gorm:"primaryKey;type:varchar(255)"`
+	Owner                      string `gorm:"primaryKey;type:varchar(255)"`
+	FileCount                  int
+	Reviewed                   bool
+	Approved                   bool
+	MergedWithoutOwnerApproval bool
+	archived.NoPKModel
+}
+
+func (pullRequestCodeOwner20241029) TableName() string {
+	return "pull_request_code_owners"
+}
+
+type addCodeOwners struct{}
+
+func (*addCodeOwners) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&codeOwner20241029{},
+		&pullRequestCodeOwner20241029{},
+	)
+}
+
+func (*addCodeOwners) Version() uint64 {
+	return 20241029104522
+}
+
+func (*addCodeOwners) Name() string {
+	return "add code_owners and pull_request_code_owners"
+}
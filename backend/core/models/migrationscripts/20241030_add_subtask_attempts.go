@@ -0,0 +1,71 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addSubtaskAttempts)(nil)
+
+type subtask20241030 struct {
+	Attempts int
+}
+
+func (subtask20241030) TableName() string {
+	return "_devlake_subtasks"
+}
+
+type subtaskAttempt20241030 struct {
+	archived.Model
+	TaskID     uint64 `gorm:"index"`
+	Name       string `gorm:"type:varchar(255)"`
+	Attempt    int
+	BeganAt    *time.Time
+	FinishedAt *time.Time
+	IsFailed   bool
+	Transient  bool
+	Message    string
+}
+
+func (subtaskAttempt20241030) TableName() string {
+	return "_devlake_subtask_attempts"
+}
+
+type addSubtaskAttempts struct{}
+
+func (*addSubtaskAttempts) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	if err := db.AutoMigrate(&subtask20241030{}); err != nil {
+		return err
+	}
+	return db.AutoMigrate(&subtaskAttempt20241030{})
+}
+
+func (*addSubtaskAttempts) Version() uint64 {
+	return 20241030091853
+}
+
+func (*addSubtaskAttempts) Name() string {
+	return "add attempts to _devlake_subtasks and _devlake_subtask_attempts"
+}
@@ -0,0 +1,54 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addProjectTeamAttributions)(nil)
+
+type projectTeamAttribution20241101 struct {
+	ProjectName string `gorm:"primaryKey;type:varchar(100)"`
+	Table       string `gorm:"primaryKey;type:varchar(50)"`
+	RowId       string `gorm:"primaryKey;type:varchar(255)"`
+	TeamId      string `gorm:"primaryKey;type:varchar(255)"`
+	Depth       int
+	archived.NoPKModel
+}
+
+func (projectTeamAttribution20241101) TableName() string {
+	return "project_team_attributions"
+}
+
+type addProjectTeamAttributions struct{}
+
+func (*addProjectTeamAttributions) Up(basicRes context.BasicRes) errors.Error {
+	return basicRes.GetDal().AutoMigrate(&projectTeamAttribution20241101{})
+}
+
+func (*addProjectTeamAttributions) Version() uint64 {
+	return 20241101093512
+}
+
+func (*addProjectTeamAttributions) Name() string {
+	return "add project_team_attributions"
+}
@@ -123,5 +123,22 @@ func All() []plugin.MigrationScript {
 		new(modifyPrAssigneeAndReviewerId),
 		new(addPullRequestIdIndexToPullRequestCommits),
 		new(addPullRequestIdIndexToPullRequestComments),
+		new(addPipelineLeases),
+		new(addNotificationChannels),
+		new(addRoleBindings),
+		new(addAuditLogTargets),
+		new(addEncryptionKeyRotations),
+		new(addReprocessSyncPolicy),
+		new(addRawDataRetentionPolicies),
+		new(addProjectDoraMetrics),
+		new(addDeploymentTypeToCicdDeploymentCommits),
+		new(addProjectReviewMetrics),
+		new(addUserAccountSuggestions),
+		new(addTeamMemberships),
+		new(addCodeOwnership),
+		new(addCodeOwners),
+		new(addSubtaskAttempts),
+		new(addRawDataCollections),
+		new(addProjectTeamAttributions),
 	}
 }
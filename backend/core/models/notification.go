@@ -18,6 +18,8 @@ limitations under the License.
 package models
 
 import (
+	"time"
+
 	"github.com/apache/incubator-devlake/core/models/common"
 )
 
@@ -25,19 +27,83 @@ type NotificationType string
 
 const (
 	NotificationPipelineStatusChanged NotificationType = "PipelineStatusChanged"
+	NotificationPipelineFailed        NotificationType = "PipelineFailed"
+	NotificationTaskFailed            NotificationType = "TaskFailed"
+	NotificationSubtaskRetried        NotificationType = "SubtaskRetried"
+	NotificationRateLimited           NotificationType = "RateLimited"
+)
+
+const (
+	NOTIFICATION_PENDING = "PENDING"
+	NOTIFICATION_SENT    = "SENT"
+	NOTIFICATION_FAILED  = "FAILED"
 )
 
 // Notification records notifications sent by lake
 type Notification struct {
 	common.Model
-	Type         NotificationType
-	Endpoint     string
-	Nonce        string
-	ResponseCode int
-	Response     string
-	Data         string
+	Type         NotificationType `json:"type"`
+	ChannelId    uint64           `json:"channelId" gorm:"index"`
+	Endpoint     string           `json:"endpoint"`
+	Nonce        string           `json:"nonce"`
+	ResponseCode int              `json:"responseCode"`
+	Response     string           `json:"response"`
+	Data         string           `json:"data"`
+	Status       string           `json:"status" gorm:"type:varchar(20);index"`
+	Attempts     int              `json:"attempts"`
+	NextRetryAt  *time.Time       `json:"nextRetryAt"`
+	// Message is the human-readable summary sent to the chat and email channels
+	Message string `json:"message"`
 }
 
 func (Notification) TableName() string {
 	return "_devlake_notifications"
 }
+
+type NotificationChannelType string
+
+const (
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+	NotificationChannelSlack   NotificationChannelType = "slack"
+	NotificationChannelFeishu  NotificationChannelType = "feishu"
+	NotificationChannelEmail   NotificationChannelType = "email"
+)
+
+// NotificationChannel subscribes a destination to the events of a blueprint, a project or everything if
+// neither BlueprintId nor ProjectName was specified
+type NotificationChannel struct {
+	common.Model
+	Name string                  `json:"name" validate:"required"`
+	Type NotificationChannelType `json:"type" gorm:"type:varchar(20)" validate:"required,oneof=webhook slack feishu email"`
+	// Endpoint is the url of the webhooks or the comma separated recipients of the email
+	Endpoint string `json:"endpoint" validate:"required"`
+	// Secret is used to sign the webhook (generic or feishu) requests
+	Secret      string   `json:"secret" gorm:"serializer:encdec"`
+	Events      []string `json:"events" gorm:"type:json;serializer:json" validate:"required"`
+	BlueprintId uint64   `json:"blueprintId" gorm:"index"`
+	ProjectName string   `json:"projectName" gorm:"type:varchar(255);index"`
+	Enable      bool     `json:"enable"`
+}
+
+func (NotificationChannel) TableName() string {
+	return "_devlake_notification_channels"
+}
+
+// Subscribes checks if the channel should be notified about the event
+func (c *NotificationChannel) Subscribes(eventType NotificationType, blueprintId uint64, projectName string) bool {
+	if !c.Enable {
+		return false
+	}
+	if c.BlueprintId != 0 && c.BlueprintId != blueprintId {
+		return false
+	}
+	if c.ProjectName != "" && c.ProjectName != projectName {
+		return false
+	}
+	for _, event := range c.Events {
+		if event == string(eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationChannel_Subscribes(t *testing.T) {
+	events := []string{string(NotificationPipelineFailed)}
+	tests := []struct {
+		name        string
+		channel     NotificationChannel
+		eventType   NotificationType
+		blueprintId uint64
+		projectName string
+		want        bool
+	}{
+		{
+			name:        "global channel",
+			channel:     NotificationChannel{Enable: true, Events: events},
+			eventType:   NotificationPipelineFailed,
+			blueprintId: 1,
+			projectName: "foo",
+			want:        true,
+		},
+		{
+			name:      "disabled channel",
+			channel:   NotificationChannel{Enable: false, Events: events},
+			eventType: NotificationPipelineFailed,
+			want:      false,
+		},
+		{
+			name:      "not subscribed event",
+			channel:   NotificationChannel{Enable: true, Events: events},
+			eventType: NotificationTaskFailed,
+			want:      false,
+		},
+		{
+			name:        "other blueprint",
+			channel:     NotificationChannel{Enable: true, Events: events, BlueprintId: 2},
+			eventType:   NotificationPipelineFailed,
+			blueprintId: 1,
+			want:        false,
+		},
+		{
+			name:        "same project",
+			channel:     NotificationChannel{Enable: true, Events: events, ProjectName: "foo"},
+			eventType:   NotificationPipelineFailed,
+			blueprintId: 1,
+			projectName: "foo",
+			want:        true,
+		},
+		{
+			name:        "other project",
+			channel:     NotificationChannel{Enable: true, Events: events, ProjectName: "foo"},
+			eventType:   NotificationPipelineFailed,
+			blueprintId: 1,
+			projectName: "bar",
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.channel.Subscribes(tt.eventType, tt.blueprintId, tt.projectName))
+		})
+	}
+}
@@ -67,6 +67,9 @@ type Pipeline struct {
 	Stage         int          `json:"stage"`
 	Labels        []string     `json:"labels" gorm:"-"`
 	SyncPolicy    `gorm:"embedded"`
+	// WorkerId and LeaseExpiresAt are maintained by the worker running the pipeline in `WORKER_MODE`
+	WorkerId       string     `json:"workerId" gorm:"type:varchar(255);index"`
+	LeaseExpiresAt *time.Time `json:"leaseExpiresAt"`
 }
 
 // We use a 2D array because the request body must be an array of a set of tasks
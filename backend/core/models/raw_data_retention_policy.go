@@ -0,0 +1,61 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// RawDataRetentionPolicy limits how long the rows of the `_raw_` tables are kept. A policy applies to all raw tables
+// of `Plugin`, or to the single raw table `RawTable` which takes precedence over the policy of its plugin.
+type RawDataRetentionPolicy struct {
+	common.Model
+	Plugin   string `json:"plugin" gorm:"type:varchar(100);index"`
+	RawTable string `json:"rawTable" gorm:"type:varchar(255);index"`
+	// KeepCollections keeps the latest N collections of each `params`, 0 means unlimited. The incremental
+	// collections depend on the ones before them, so only the collections superseded by a later full collection
+	// are pruned
+	KeepCollections int `json:"keepCollections" validate:"min=0"`
+	// KeepDays keeps the rows created in the last N days, 0 means unlimited
+	KeepDays int `json:"keepDays" validate:"min=0"`
+	// Archive writes the pruned rows into compressed files under `RAW_DATA_ARCHIVE_DIR` before deleting them,
+	// so they could be imported back for reprocessing
+	Archive bool `json:"archive"`
+	// Compress compresses the `data` of the rows kept when `RAW_DATA_COMPRESSION` is enabled, the rows collected
+	// after enabling it are always compressed
+	Compress bool `json:"compress"`
+}
+
+func (RawDataRetentionPolicy) TableName() string {
+	return "_devlake_raw_data_retention_policies"
+}
+
+// RawDataCollection is a run of a collector writing the rows of `RawTable` with `Params`, it tells the raw data
+// retention whether the run superseded the collections before it
+type RawDataCollection struct {
+	RawTable    string    `json:"rawTable" gorm:"primaryKey;type:varchar(255)"`
+	Params      string    `json:"params" gorm:"primaryKey;type:varchar(255)"`
+	CollectedAt time.Time `json:"collectedAt" gorm:"primaryKey"`
+	Incremental bool      `json:"incremental"`
+}
+
+func (RawDataCollection) TableName() string {
+	return "_devlake_raw_data_collections"
+}
@@ -0,0 +1,34 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import "time"
+
+// ReprocessReport counts the rows of a domain table changed by a reprocess pipeline
+type ReprocessReport struct {
+	PipelineId  uint64    `json:"pipelineId" gorm:"primaryKey"`
+	DomainTable string    `json:"domainTable" gorm:"primaryKey;type:varchar(255)"`
+	Inserted    int64     `json:"inserted"`
+	Updated     int64     `json:"updated"`
+	Deleted     int64     `json:"deleted"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (ReprocessReport) TableName() string {
+	return "_devlake_reprocess_reports"
+}
@@ -0,0 +1,119 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+type Role string
+
+const (
+	// ROLE_VIEWER can read everything of the projects it is bound to, connection secrets are always sanitized
+	ROLE_VIEWER Role = "viewer"
+	// ROLE_PROJECT_MAINTAINER can also modify, trigger and delete the projects, blueprints and pipelines it is bound to
+	ROLE_PROJECT_MAINTAINER Role = "project-maintainer"
+	// ROLE_ADMIN can do anything including managing connections, api keys and role bindings
+	ROLE_ADMIN Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	ROLE_VIEWER:             1,
+	ROLE_PROJECT_MAINTAINER: 2,
+	ROLE_ADMIN:              3,
+}
+
+// IsValid reports whether the role is one of the predefined roles
+func (r Role) IsValid() bool {
+	return roleRanks[r] > 0
+}
+
+// Covers reports whether the role grants everything the `required` role does
+func (r Role) Covers(required Role) bool {
+	return r.IsValid() && roleRanks[r] >= roleRanks[required]
+}
+
+const (
+	ROLE_SUBJECT_USER   = "user"
+	ROLE_SUBJECT_APIKEY = "apikey"
+)
+
+// RoleBinding grants a Role to a user (matched by the name or email from the proxy headers) or to an api key
+// (matched by its id), either on a single project or on all projects when `ProjectName` is empty.
+type RoleBinding struct {
+	common.Model
+	common.Creator
+	SubjectType string `json:"subjectType" gorm:"type:varchar(20);index" validate:"required,oneof=user apikey"`
+	Subject     string `json:"subject" gorm:"type:varchar(255);index" validate:"required"`
+	Role        Role   `json:"role" gorm:"type:varchar(50)" validate:"required"`
+	ProjectName string `json:"projectName" gorm:"type:varchar(255);index"`
+}
+
+func (RoleBinding) TableName() string {
+	return "_devlake_role_bindings"
+}
+
+// RoleBindings are all the bindings of a single subject
+type RoleBindings []*RoleBinding
+
+// Allows reports whether the bindings grant the `required` role on every project of `projectNames`. An empty
+// `projectNames` means the resource does not belong to any project, only global bindings are taken into account
+// for it, except for the viewer role which is granted to anyone bound to at least one project.
+func (bindings RoleBindings) Allows(required Role, projectNames ...string) bool {
+	if len(projectNames) == 0 {
+		for _, binding := range bindings {
+			if binding.Role.Covers(required) && (binding.ProjectName == "" || required == ROLE_VIEWER) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, projectName := range projectNames {
+		granted := false
+		for _, binding := range bindings {
+			if binding.Role.Covers(required) && (binding.ProjectName == "" || binding.ProjectName == projectName) {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
+
+// ProjectScope returns the projects the bindings grant the `required` role on, `all` is true when a global
+// binding grants it on every project
+func (bindings RoleBindings) ProjectScope(required Role) (projectNames []string, all bool) {
+	projectNames = make([]string, 0)
+	seen := make(map[string]bool)
+	for _, binding := range bindings {
+		if !binding.Role.Covers(required) {
+			continue
+		}
+		if binding.ProjectName == "" {
+			return nil, true
+		}
+		if !seen[binding.ProjectName] {
+			seen[binding.ProjectName] = true
+			projectNames = append(projectNames, binding.ProjectName)
+		}
+	}
+	return projectNames, false
+}
@@ -0,0 +1,125 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleBindings_Allows(t *testing.T) {
+	maintainerOfFoo := RoleBindings{
+		{Role: ROLE_PROJECT_MAINTAINER, ProjectName: "foo"},
+	}
+	tests := []struct {
+		name         string
+		bindings     RoleBindings
+		required     Role
+		projectNames []string
+		want         bool
+	}{
+		{
+			name:     "no bindings",
+			bindings: RoleBindings{},
+			required: ROLE_VIEWER,
+			want:     false,
+		},
+		{
+			name:         "trigger own blueprint",
+			bindings:     maintainerOfFoo,
+			required:     ROLE_PROJECT_MAINTAINER,
+			projectNames: []string{"foo"},
+			want:         true,
+		},
+		{
+			name:         "delete project of another team",
+			bindings:     maintainerOfFoo,
+			required:     ROLE_PROJECT_MAINTAINER,
+			projectNames: []string{"bar"},
+			want:         false,
+		},
+		{
+			name:         "read connection shared with another team",
+			bindings:     maintainerOfFoo,
+			required:     ROLE_VIEWER,
+			projectNames: []string{"foo", "bar"},
+			want:         false,
+		},
+		{
+			name:     "read resources not scoped to any project",
+			bindings: maintainerOfFoo,
+			required: ROLE_VIEWER,
+			want:     true,
+		},
+		{
+			name:     "modify resources not scoped to any project",
+			bindings: maintainerOfFoo,
+			required: ROLE_PROJECT_MAINTAINER,
+			want:     false,
+		},
+		{
+			name:         "global viewer",
+			bindings:     RoleBindings{{Role: ROLE_VIEWER}},
+			required:     ROLE_PROJECT_MAINTAINER,
+			projectNames: []string{"foo"},
+			want:         false,
+		},
+		{
+			name:         "admin",
+			bindings:     RoleBindings{{Role: ROLE_ADMIN}},
+			required:     ROLE_ADMIN,
+			projectNames: []string{"foo", "bar"},
+			want:         true,
+		},
+		{
+			name:     "unknown role",
+			bindings: RoleBindings{{Role: "owner"}},
+			required: ROLE_VIEWER,
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.bindings.Allows(tt.required, tt.projectNames...))
+		})
+	}
+}
+
+func TestRoleBindingsProjectScope(t *testing.T) {
+	bindings := RoleBindings{
+		{Role: ROLE_VIEWER, ProjectName: "foo"},
+		{Role: ROLE_PROJECT_MAINTAINER, ProjectName: "bar"},
+		{Role: ROLE_VIEWER, ProjectName: "bar"},
+	}
+	projectNames, all := bindings.ProjectScope(ROLE_VIEWER)
+	assert.False(t, all)
+	assert.Equal(t, []string{"foo", "bar"}, projectNames)
+	projectNames, all = bindings.ProjectScope(ROLE_PROJECT_MAINTAINER)
+	assert.False(t, all)
+	assert.Equal(t, []string{"bar"}, projectNames)
+
+	projectNames, all = append(bindings, &RoleBinding{Role: ROLE_VIEWER}).ProjectScope(ROLE_VIEWER)
+	assert.True(t, all)
+	assert.Nil(t, projectNames)
+
+	projectNames, all = RoleBindings{}.ProjectScope(ROLE_VIEWER)
+	assert.False(t, all)
+	assert.Empty(t, projectNames)
+	assert.NotNil(t, projectNames)
+}
@@ -93,12 +93,31 @@ type Subtask struct {
 	IsCollector     bool       `json:"isCollector"`
 	IsFailed        bool       `json:"isFailed"`
 	Message         string     `json:"message"`
+	Attempts        int        `json:"attempts"`
 }
 
 func (Subtask) TableName() string {
 	return "_devlake_subtasks"
 }
 
+// SubtaskAttempt is a run of a subtask, a subtask is run more than once when it fails with a transient error and
+// declares a retry policy
+type SubtaskAttempt struct {
+	common.Model
+	TaskID     uint64     `json:"taskId" gorm:"index"`
+	Name       string     `json:"name" gorm:"type:varchar(255)"`
+	Attempt    int        `json:"attempt"`
+	BeganAt    *time.Time `json:"beganAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+	IsFailed   bool       `json:"isFailed"`
+	Transient  bool       `json:"transient"`
+	Message    string     `json:"message"`
+}
+
+func (SubtaskAttempt) TableName() string {
+	return "_devlake_subtask_attempts"
+}
+
 type SubtaskDetails struct {
 	ID              uint64     `json:"id"`
 	CreatedAt       time.Time  `json:"createdAt"`
@@ -114,6 +133,7 @@ type SubtaskDetails struct {
 	IsCollector     bool       `json:"isCollector"`
 	IsFailed        bool       `json:"isFailed"`
 	Message         string     `json:"message"`
+	Attempts        int        `json:"attempts"`
 }
 
 type SubtasksInfo struct {
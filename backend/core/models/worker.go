@@ -0,0 +1,36 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import "time"
+
+// Worker is a devlake instance consuming pipelines from the shared database when `WORKER_MODE` is enabled.
+// Each worker refreshes its `HeartbeatAt` and the leases of the pipelines it is running periodically, a pipeline
+// whose lease expired (e.g. the worker crashed) would be put back to the queue and picked up by another worker.
+type Worker struct {
+	ID          string    `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	HostName    string    `json:"hostName"`
+	Version     string    `json:"version"`
+	HeartbeatAt time.Time `json:"heartbeatAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (Worker) TableName() string {
+	return "_devlake_workers"
+}
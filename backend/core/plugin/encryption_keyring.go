@@ -0,0 +1,144 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+const (
+	EncryptionKeyIdEnvStr           = "ENCRYPTION_KEY_ID"
+	EncryptionPreviousSecretsEnvStr = "ENCRYPTION_PREVIOUS_SECRETS"
+	encryptionKeyIdSeparator        = ":"
+	encryptionKeyIdPatternLiteral   = `^[A-Za-z0-9_.-]+$`
+)
+
+var encryptionKeyIdPattern = regexp.MustCompile(encryptionKeyIdPatternLiteral)
+
+// EncryptionKeyRing encrypts with the current key and decrypts with any key in the ring. Ciphertexts are
+// tagged with the id of the key as `<keyId>:<base64>`, since `:` is not part of the base64 alphabet untagged
+// ciphertexts, which were written before a key id was configured, can still be told apart. They are decrypted
+// by trying every key of the ring.
+type EncryptionKeyRing struct {
+	currentKeyId string
+	keyIds       []string
+	secrets      map[string]string
+}
+
+// NewEncryptionKeyRing creates a key ring from the current key and the previous ones formatted as
+// `keyId1:secret1,keyId2:secret2`. An empty `currentKeyId` keeps writing untagged ciphertexts like before.
+func NewEncryptionKeyRing(currentKeyId, currentSecret, previousSecrets string) (*EncryptionKeyRing, errors.Error) {
+	ring := &EncryptionKeyRing{
+		currentKeyId: currentKeyId,
+		secrets:      make(map[string]string),
+	}
+	if err := ring.add(currentKeyId, currentSecret); err != nil {
+		return nil, err
+	}
+	for _, previous := range strings.Split(previousSecrets, ",") {
+		previous = strings.TrimSpace(previous)
+		if previous == "" {
+			continue
+		}
+		parts := strings.SplitN(previous, encryptionKeyIdSeparator, 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.BadInput.New(fmt.Sprintf("%s must be formatted as keyId1:secret1,keyId2:secret2", EncryptionPreviousSecretsEnvStr))
+		}
+		if err := ring.add(parts[0], parts[1]); err != nil {
+			return nil, err
+		}
+	}
+	return ring, nil
+}
+
+func (ring *EncryptionKeyRing) add(keyId, secret string) errors.Error {
+	if keyId != "" && !encryptionKeyIdPattern.MatchString(keyId) {
+		return errors.BadInput.New(fmt.Sprintf("encryption key id %s must match %s", keyId, encryptionKeyIdPatternLiteral))
+	}
+	if _, ok := ring.secrets[keyId]; ok {
+		return errors.BadInput.New(fmt.Sprintf("duplicated encryption key id %s", keyId))
+	}
+	ring.keyIds = append(ring.keyIds, keyId)
+	ring.secrets[keyId] = secret
+	return nil
+}
+
+// CurrentKeyId returns the id of the key used for encryption
+func (ring *EncryptionKeyRing) CurrentKeyId() string {
+	return ring.currentKeyId
+}
+
+// PreviousSecrets returns the secrets of the keys other than the current one in the configured order
+func (ring *EncryptionKeyRing) PreviousSecrets() []string {
+	secrets := make([]string, 0, len(ring.keyIds)-1)
+	for _, id := range ring.keyIds {
+		if id != ring.currentKeyId {
+			secrets = append(secrets, ring.secrets[id])
+		}
+	}
+	return secrets
+}
+
+// Encrypt encrypts the `plainText` with the current key and tags the result with the key id
+func (ring *EncryptionKeyRing) Encrypt(plainText string) (string, errors.Error) {
+	encrypted, err := Encrypt(ring.secrets[ring.currentKeyId], plainText)
+	if err != nil || ring.currentKeyId == "" {
+		return encrypted, err
+	}
+	return ring.currentKeyId + encryptionKeyIdSeparator + encrypted, nil
+}
+
+// Decrypt decrypts the `encryptedText` with the key it was tagged with
+func (ring *EncryptionKeyRing) Decrypt(encryptedText string) (string, errors.Error) {
+	keyId, payload, tagged := SplitEncryptionKeyId(encryptedText)
+	if tagged {
+		secret, ok := ring.secrets[keyId]
+		if !ok {
+			return encryptedText, errors.Default.New(fmt.Sprintf("encryption key %s is not in the key ring", keyId))
+		}
+		return Decrypt(secret, payload)
+	}
+	var err errors.Error
+	for _, id := range ring.keyIds {
+		var decrypted string
+		decrypted, err = Decrypt(ring.secrets[id], payload)
+		if err == nil {
+			return decrypted, nil
+		}
+	}
+	return encryptedText, err
+}
+
+// IsCurrent reports whether the `encryptedText` was encrypted by the current key
+func (ring *EncryptionKeyRing) IsCurrent(encryptedText string) bool {
+	keyId, _, tagged := SplitEncryptionKeyId(encryptedText)
+	return tagged && ring.currentKeyId != "" && keyId == ring.currentKeyId
+}
+
+// SplitEncryptionKeyId splits a tagged ciphertext into the key id and the base64 payload
+func SplitEncryptionKeyId(encryptedText string) (keyId string, payload string, tagged bool) {
+	idx := strings.Index(encryptedText, encryptionKeyIdSeparator)
+	if idx <= 0 {
+		return "", encryptedText, false
+	}
+	return encryptedText[:idx], encryptedText[idx+1:], true
+}
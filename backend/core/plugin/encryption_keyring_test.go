@@ -0,0 +1,61 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptionKeyRing(t *testing.T) {
+	legacy, err := NewEncryptionKeyRing("", "legacy-secret", "")
+	assert.Nil(t, err)
+	legacyText, err := legacy.Encrypt("token")
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(legacyText, ":"))
+
+	v1, err := NewEncryptionKeyRing("v1", "secret-1", "legacy:legacy-secret")
+	assert.Nil(t, err)
+	v1Text, err := v1.Encrypt("token")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(v1Text, "v1:"))
+	assert.True(t, v1.IsCurrent(v1Text))
+	assert.Equal(t, []string{"legacy-secret"}, v1.PreviousSecrets())
+	assert.False(t, v1.IsCurrent(legacyText))
+	for _, text := range []string{legacyText, v1Text} {
+		decrypted, err := v1.Decrypt(text)
+		assert.Nil(t, err)
+		assert.Equal(t, "token", decrypted)
+	}
+
+	v2, err := NewEncryptionKeyRing("v2", "secret-2", "v1:secret-1")
+	assert.Nil(t, err)
+	decrypted, err := v2.Decrypt(v1Text)
+	assert.Nil(t, err)
+	assert.Equal(t, "token", decrypted)
+	assert.False(t, v2.IsCurrent(v1Text))
+	_, err = v2.Decrypt(legacyText)
+	assert.NotNil(t, err)
+
+	_, err = NewEncryptionKeyRing("v2", "secret-2", "v2:secret-1")
+	assert.NotNil(t, err)
+	_, err = NewEncryptionKeyRing("v 2", "secret-2", "")
+	assert.NotNil(t, err)
+}
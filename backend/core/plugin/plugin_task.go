@@ -19,6 +19,8 @@ package plugin
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	corecontext "github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/errors"
@@ -107,6 +109,58 @@ type SubTaskMeta struct {
 	DependencyTables []string
 	ProductTables    []string
 	ForceRunOnResume bool // Should a subtask be ran dispite it was finished before
+	// RetryPolicy lets the runner rerun the subtask in place when it fails with a transient error
+	RetryPolicy *SubTaskRetryPolicy
+}
+
+// DefaultTransientErrorTypes are the errors worth a retry when the RetryPolicy does not tell otherwise
+var DefaultTransientErrorTypes = []*errors.Type{
+	errors.Timeout,
+	errors.Unavailable,
+	errors.HttpStatus(http.StatusBadGateway),
+}
+
+// SubTaskRetryPolicy tells how many times and how long apart a failed subtask should be rerun
+type SubTaskRetryPolicy struct {
+	// MaxAttempts is the number of runs including the first one
+	MaxAttempts int
+	// Backoff is the pause before the second attempt, it doubles for every attempt after, up to MaxBackoff if set
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// TransientErrorTypes are the types of the errors worth a retry, DefaultTransientErrorTypes if empty
+	TransientErrorTypes []*errors.Type
+}
+
+// IsTransient tells whether the error, or any error it wraps, is of a transient type
+func (p *SubTaskRetryPolicy) IsTransient(err errors.Error) bool {
+	if err == nil {
+		return false
+	}
+	types := p.TransientErrorTypes
+	if len(types) == 0 {
+		types = DefaultTransientErrorTypes
+	}
+	for _, t := range types {
+		if err.As(t) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// BackoffOf returns the pause after the given failed attempt, attempts start at 1
+func (p *SubTaskRetryPolicy) BackoffOf(attempt int) time.Duration {
+	backoff := p.Backoff
+	for i := 1; i < attempt; i++ {
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
 }
 
 // PluginTask Implement this interface to let framework run tasks for you
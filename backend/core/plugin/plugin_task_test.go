@@ -0,0 +1,50 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubTaskRetryPolicyIsTransient(t *testing.T) {
+	policy := &SubTaskRetryPolicy{MaxAttempts: 3}
+	assert.False(t, policy.IsTransient(nil))
+	assert.True(t, policy.IsTransient(errors.Timeout.New("read timed out")))
+	assert.True(t, policy.IsTransient(errors.Default.Wrap(errors.HttpStatus(http.StatusBadGateway).New("bad gateway"), "collect issues")))
+	assert.False(t, policy.IsTransient(errors.BadInput.New("invalid jql")))
+
+	policy.TransientErrorTypes = []*errors.Type{errors.HttpStatus(http.StatusTooManyRequests)}
+	assert.True(t, policy.IsTransient(errors.HttpStatus(http.StatusTooManyRequests).New("slow down")))
+	assert.False(t, policy.IsTransient(errors.Timeout.New("read timed out")))
+}
+
+func TestSubTaskRetryPolicyBackoffOf(t *testing.T) {
+	policy := &SubTaskRetryPolicy{Backoff: time.Minute, MaxBackoff: 3 * time.Minute}
+	assert.Equal(t, time.Minute, policy.BackoffOf(1))
+	assert.Equal(t, 2*time.Minute, policy.BackoffOf(2))
+	assert.Equal(t, 3*time.Minute, policy.BackoffOf(3))
+	assert.Equal(t, 3*time.Minute, policy.BackoffOf(10))
+
+	policy.MaxBackoff = 0
+	assert.Equal(t, 8*time.Minute, policy.BackoffOf(4))
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/apache/incubator-devlake/core/config"
 	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/plugin"
 	contextimpl "github.com/apache/incubator-devlake/impls/context"
@@ -49,10 +50,24 @@ func CreateAppBasicRes() context.BasicRes {
 	if err != nil {
 		panic(err)
 	}
-	dalgorm.Init(cfg.GetString(plugin.EncodeKeyEnvStr))
+	keyRing, err := NewEncryptionKeyRing(cfg)
+	if err != nil {
+		panic(err)
+	}
+	dalgorm.InitWithKeyRing(keyRing)
 	return CreateBasicRes(cfg, logger, db)
 }
 
+// NewEncryptionKeyRing returns the key ring configured by `ENCRYPTION_SECRET`, `ENCRYPTION_KEY_ID` and
+// `ENCRYPTION_PREVIOUS_SECRETS`
+func NewEncryptionKeyRing(cfg config.ConfigReader) (*plugin.EncryptionKeyRing, errors.Error) {
+	return plugin.NewEncryptionKeyRing(
+		cfg.GetString(plugin.EncryptionKeyIdEnvStr),
+		cfg.GetString(plugin.EncodeKeyEnvStr),
+		cfg.GetString(plugin.EncryptionPreviousSecretsEnvStr),
+	)
+}
+
 // CreateBasicRes returns a BasicRes based on what was given
 func CreateBasicRes(cfg config.ConfigReader, logger log.Logger, db *gorm.DB) context.BasicRes {
 	return contextimpl.NewDefaultBasicRes(cfg, logger, dalgorm.NewDalgorm(db))
@@ -31,7 +31,9 @@ import (
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/models/migrationscripts"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/tracing"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // RunCmd FIXME ...
@@ -84,7 +86,14 @@ func DirectRun(cmd *cobra.Command, args []string, pluginTask plugin.PluginTask,
 	if err != nil {
 		panic(err)
 	}
-	ctx := createContext()
+	shutdownTracing, err := tracing.Init(basicRes.GetConfigReader())
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
+	ctx, span := tracing.Start(createContext(), "task "+cmd.Use, attribute.String("devlake.plugin", cmd.Use))
 	task := &models.Task{
 		Plugin:   cmd.Use,
 		Options:  options,
@@ -109,6 +118,7 @@ func DirectRun(cmd *cobra.Command, args []string, pluginTask plugin.PluginTask,
 		nil,
 		&syncPolicy,
 	)
+	tracing.End(span, err)
 	if err != nil {
 		panic(err)
 	}
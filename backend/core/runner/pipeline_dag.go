@@ -0,0 +1,194 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	gocontext "context"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// taskTables holds the tables read and written by the enabled subtasks of a task
+type taskTables struct {
+	// declared is false if any of the enabled subtasks didn't declare DependencyTables nor ProductTables,
+	// the task might touch any table in that case
+	declared     bool
+	dependencies map[string]bool
+	products     map[string]bool
+}
+
+func (t *taskTables) conflictsWith(other *taskTables) bool {
+	if !t.declared || !other.declared {
+		return true
+	}
+	return intersects(t.products, other.dependencies) ||
+		intersects(t.dependencies, other.products) ||
+		intersects(t.products, other.products)
+}
+
+func intersects(a, b map[string]bool) bool {
+	for table := range a {
+		if b[table] {
+			return true
+		}
+	}
+	return false
+}
+
+// getTaskTables collects the DependencyTables and ProductTables of the subtasks to be executed by the task
+func getTaskTables(task *models.Task, syncPolicy *models.SyncPolicy) *taskTables {
+	undeclared := &taskTables{}
+	pluginMeta, err := plugin.GetPlugin(task.Plugin)
+	if err != nil {
+		return undeclared
+	}
+	pluginTask, ok := pluginMeta.(plugin.PluginTask)
+	if !ok {
+		return undeclared
+	}
+	subtaskMetas := pluginTask.SubTaskMetas()
+	subtasksFlag, err := GetSubtasksFlag(subtaskMetas, task.Subtasks, syncPolicy)
+	if err != nil {
+		return undeclared
+	}
+	tables := &taskTables{
+		declared:     true,
+		dependencies: make(map[string]bool),
+		products:     make(map[string]bool),
+	}
+	for _, subtaskMeta := range subtaskMetas {
+		if !subtasksFlag[subtaskMeta.Name] {
+			continue
+		}
+		if len(subtaskMeta.DependencyTables) == 0 && len(subtaskMeta.ProductTables) == 0 {
+			return undeclared
+		}
+		for _, table := range subtaskMeta.DependencyTables {
+			tables.dependencies[table] = true
+		}
+		for _, table := range subtaskMeta.ProductTables {
+			tables.products[table] = true
+		}
+	}
+	return tables
+}
+
+// buildTaskDag returns the prerequisites of each task. A task depends on the tasks of the previous stages that
+// touch the same tables as it does, tasks of the same stage never depend on each other. The stages are preserved
+// for tasks with undeclared tables, so the execution order is exactly the same as the PipelinePlan if no
+// subtasks declared their tables.
+func buildTaskDag(tasks []models.Task, tables map[uint64]*taskTables) map[uint64][]uint64 {
+	prerequisites := make(map[uint64][]uint64, len(tasks))
+	for _, task := range tasks {
+		prerequisites[task.ID] = []uint64{}
+		for _, prev := range tasks {
+			if prev.PipelineRow >= task.PipelineRow {
+				continue
+			}
+			if tables[task.ID].conflictsWith(tables[prev.ID]) {
+				prerequisites[task.ID] = append(prerequisites[task.ID], prev.ID)
+			}
+		}
+	}
+	return prerequisites
+}
+
+type dagTaskResult struct {
+	taskId uint64
+	err    errors.Error
+}
+
+// runPipelineTasksAsDag starts each task as soon as all its prerequisites are finished instead of waiting for
+// the whole previous stage
+func runPipelineTasksAsDag(
+	basicRes context.BasicRes,
+	dbPipeline *models.Pipeline,
+	tasks []models.Task,
+	runTasks func([]uint64) errors.Error,
+) errors.Error {
+	db := basicRes.GetDal()
+	log := basicRes.GetLogger()
+
+	tables := make(map[uint64]*taskTables, len(tasks))
+	for i := range tasks {
+		tables[tasks[i].ID] = getTaskTables(&tasks[i], &dbPipeline.SyncPolicy)
+	}
+	prerequisites := buildTaskDag(tasks, tables)
+
+	started := make(map[uint64]bool, len(tasks))
+	finished := make(map[uint64]bool, len(tasks))
+	results := make(chan dagTaskResult, len(tasks))
+	running := 0
+	stage := 0
+	aborted := false
+	var err errors.Error
+	for {
+		for i := range tasks {
+			task := &tasks[i]
+			if aborted || started[task.ID] {
+				continue
+			}
+			ready := true
+			for _, prerequisite := range prerequisites[task.ID] {
+				if !finished[prerequisite] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			// stage represents the furthest stage reached by the pipeline
+			if task.PipelineRow > stage {
+				stage = task.PipelineRow
+				dbe := db.UpdateColumns(dbPipeline, []dal.DalSet{
+					{ColumnName: "status", Value: models.TASK_RUNNING},
+					{ColumnName: "stage", Value: stage},
+				})
+				if dbe != nil {
+					log.Error(dbe, "update pipeline state failed")
+				}
+			}
+			log.Info("task #%d is ready, prerequisites: %v", task.ID, prerequisites[task.ID])
+			started[task.ID] = true
+			running++
+			go func(taskId uint64) {
+				results <- dagTaskResult{taskId: taskId, err: runTasks([]uint64{taskId})}
+			}(task.ID)
+		}
+		if running == 0 {
+			break
+		}
+		result := <-results
+		running--
+		finished[result.taskId] = true
+		if result.err != nil {
+			err = result.err
+			log.Error(err, "run task #%d failed", result.taskId)
+			if errors.Is(err, gocontext.Canceled) || !dbPipeline.SkipOnFail {
+				// stop scheduling and wait for the running tasks
+				aborted = true
+			}
+		}
+	}
+	return err
+}
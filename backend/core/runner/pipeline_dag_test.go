@@ -0,0 +1,74 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDagTask(id uint64, row int) models.Task {
+	return models.Task{Model: common.Model{ID: id}, PipelineRow: row}
+}
+
+func newTaskTables(dependencies, products []string) *taskTables {
+	tables := &taskTables{
+		declared:     true,
+		dependencies: make(map[string]bool),
+		products:     make(map[string]bool),
+	}
+	for _, table := range dependencies {
+		tables.dependencies[table] = true
+	}
+	for _, table := range products {
+		tables.products[table] = true
+	}
+	return tables
+}
+
+func TestBuildTaskDag(t *testing.T) {
+	tasks := []models.Task{
+		newDagTask(1, 1), // github: produces pull_requests
+		newDagTask(2, 1), // jenkins: undeclared
+		newDagTask(3, 2), // gitextractor: produces commits
+		newDagTask(4, 3), // linker: pull_requests -> pull_request_issues
+		newDagTask(5, 3), // refdiff: undeclared
+		newDagTask(6, 4), // writes pull_request_issues
+	}
+	tables := map[uint64]*taskTables{
+		1: newTaskTables(nil, []string{"pull_requests"}),
+		2: {},
+		3: newTaskTables(nil, []string{"commits"}),
+		4: newTaskTables([]string{"pull_requests", "issues"}, []string{"pull_request_issues"}),
+		5: {},
+		6: newTaskTables(nil, []string{"pull_request_issues"}),
+	}
+	dag := buildTaskDag(tasks, tables)
+	assert.Equal(t, []uint64{}, dag[1])
+	assert.Equal(t, []uint64{}, dag[2])
+	// tasks with declared tables still wait for the undeclared ones of the previous stages
+	assert.Equal(t, []uint64{2}, dag[3])
+	assert.Equal(t, []uint64{1, 2}, dag[4])
+	// undeclared tasks wait for all tasks of the previous stages
+	assert.Equal(t, []uint64{1, 2, 3}, dag[5])
+	// writing the same table
+	assert.Equal(t, []uint64{2, 4, 5}, dag[6])
+}
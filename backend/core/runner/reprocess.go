@@ -0,0 +1,324 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	gocontext "context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/domaininfo"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var reprocessTableNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// reprocessSnapshot keeps the primary keys and the hashes of the rows of the domain tables derived from the raw
+// data of the plugins in a reprocess pipeline, so the rows inserted, updated or deleted could be counted afterward
+type reprocessSnapshot struct {
+	pipelineId uint64
+	rawTables  []string
+	tables     []*reprocessTable
+	// rawData holds the params of the raw data read by the subtasks of the pipeline by raw table
+	mu      sync.Mutex
+	rawData map[string]map[string]bool
+}
+
+type reprocessTable struct {
+	name        string
+	snapshot    string
+	primaryKeys []string
+	columns     []string
+}
+
+type pipelineIdCtxKey struct{}
+
+var reprocessSnapshots = make(map[uint64]*reprocessSnapshot)
+var reprocessSnapshotsMu sync.Mutex
+
+// withPipelineId tells the subtasks running with `ctx` which pipeline they belong to
+func withPipelineId(ctx gocontext.Context, pipelineId uint64) gocontext.Context {
+	return gocontext.WithValue(ctx, pipelineIdCtxKey{}, pipelineId)
+}
+
+// RecordReprocessedRawData records the raw data of `params` in `table` read by a subtask running with `ctx`, so the
+// reprocess report of its pipeline only counts the rows derived from the scopes actually reprocessed
+func RecordReprocessedRawData(ctx gocontext.Context, table string, params string) {
+	pipelineId, ok := ctx.Value(pipelineIdCtxKey{}).(uint64)
+	if !ok {
+		return
+	}
+	reprocessSnapshotsMu.Lock()
+	snapshot := reprocessSnapshots[pipelineId]
+	reprocessSnapshotsMu.Unlock()
+	if snapshot == nil {
+		return
+	}
+	snapshot.mu.Lock()
+	defer snapshot.mu.Unlock()
+	if snapshot.rawData[table] == nil {
+		snapshot.rawData[table] = make(map[string]bool)
+	}
+	snapshot.rawData[table][params] = true
+}
+
+func quoteColumn(db dal.Dal, column string) string {
+	if db.Dialect() == "postgres" {
+		return fmt.Sprintf(`"%s"`, column)
+	}
+	return fmt.Sprintf("`%s`", column)
+}
+
+// rowHash returns the sql expression hashing all columns but the timestamps of the row aliased as `alias`. Each
+// column is hashed on its own so values can not move between columns unnoticed, and NULL is told from any value
+// by hashing it as "n" and any value as "v" followed by the value.
+func (table *reprocessTable) rowHash(db dal.Dal, alias string) string {
+	textType := "CHAR"
+	if db.Dialect() == "postgres" {
+		textType = "TEXT"
+	}
+	columns := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		value := fmt.Sprintf("%s.%s", alias, quoteColumn(db, column))
+		columns[i] = fmt.Sprintf(
+			"MD5(CASE WHEN %s IS NULL THEN 'n' ELSE CONCAT('v', CAST(%s AS %s)) END)", value, value, textType,
+		)
+	}
+	return fmt.Sprintf("MD5(CONCAT(%s))", strings.Join(columns, ", "))
+}
+
+func (table *reprocessTable) joinOn(db dal.Dal) string {
+	conditions := make([]string, len(table.primaryKeys))
+	for i, pk := range table.primaryKeys {
+		conditions[i] = fmt.Sprintf("t.%s = s.%s", quoteColumn(db, pk), quoteColumn(db, pk))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// getPluginRawTables returns the raw tables of `plugins`. A raw table belongs to the plugin with the longest name
+// it is prefixed with, so the tables of `github_graphql` are never taken for the ones of `github`
+func getPluginRawTables(db dal.Dal, plugins []string) ([]string, errors.Error) {
+	allTables, err := db.AllTables()
+	if err != nil {
+		return nil, err
+	}
+	pluginNames := make(map[string]bool)
+	for name := range plugin.AllPlugins() {
+		pluginNames[name] = true
+	}
+	for _, name := range plugins {
+		pluginNames[name] = true
+	}
+	wanted := make(map[string]bool)
+	for _, name := range plugins {
+		wanted[name] = true
+	}
+	var rawTables []string
+	for _, table := range allTables {
+		owner := ""
+		for name := range pluginNames {
+			if strings.HasPrefix(table, "_raw_"+name+"_") && len(name) > len(owner) {
+				owner = name
+			}
+		}
+		// the raw tables are embedded in the sql directly since the parameters are not supported by `CREATE TABLE AS`
+		if wanted[owner] && reprocessTableNamePattern.MatchString(table) {
+			rawTables = append(rawTables, table)
+		}
+	}
+	sort.Strings(rawTables)
+	return rawTables, nil
+}
+
+// takeReprocessSnapshot snapshots the domain tables before the `tasks` of a reprocess pipeline run, snapshots
+// left by an interrupted run of the same pipeline are reused
+func takeReprocessSnapshot(basicRes context.BasicRes, pipelineId uint64, tasks []models.Task) (*reprocessSnapshot, errors.Error) {
+	db := basicRes.GetDal()
+	var plugins []string
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		if seen[task.Plugin] {
+			continue
+		}
+		seen[task.Plugin] = true
+		plugins = append(plugins, task.Plugin)
+	}
+	if len(plugins) == 0 {
+		return nil, nil
+	}
+	rawTables, err := getPluginRawTables(db, plugins)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawTables) == 0 {
+		return nil, nil
+	}
+	snapshot := &reprocessSnapshot{
+		pipelineId: pipelineId,
+		rawTables:  rawTables,
+		rawData:    make(map[string]map[string]bool),
+	}
+	for i, tabler := range domaininfo.GetDomainTablesInfo() {
+		if !db.HasTable(tabler) || !db.HasColumn(tabler, "_raw_data_table") {
+			continue
+		}
+		columnMetas, err := db.GetColumns(tabler, nil)
+		if err != nil {
+			snapshot.drop(basicRes)
+			return nil, err
+		}
+		table := &reprocessTable{
+			name:     tabler.TableName(),
+			snapshot: fmt.Sprintf("_devlake_reprocess_%d_%d", pipelineId, i),
+		}
+		for _, columnMeta := range columnMetas {
+			if isPrimaryKey, ok := columnMeta.PrimaryKey(); ok && isPrimaryKey {
+				table.primaryKeys = append(table.primaryKeys, columnMeta.Name())
+			}
+			switch columnMeta.Name() {
+			case "created_at", "updated_at":
+			default:
+				table.columns = append(table.columns, columnMeta.Name())
+			}
+		}
+		if len(table.primaryKeys) == 0 {
+			continue
+		}
+		// the table is dropped along with the others if the snapshot fails from now on
+		snapshot.tables = append(snapshot.tables, table)
+		if !db.HasTable(table.snapshot) {
+			columns := make([]string, len(table.primaryKeys))
+			for j, pk := range table.primaryKeys {
+				columns[j] = "t." + quoteColumn(db, pk)
+			}
+			err = db.Exec(fmt.Sprintf(
+				"CREATE TABLE %s AS SELECT %s, t._raw_data_table, t._raw_data_params, %s AS row_hash FROM %s t WHERE t._raw_data_table IN ('%s')",
+				table.snapshot,
+				strings.Join(columns, ", "),
+				table.rowHash(db, "t"),
+				table.name,
+				strings.Join(snapshot.rawTables, "', '"),
+			))
+			if err != nil {
+				snapshot.drop(basicRes)
+				return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to snapshot table %s", table.name))
+			}
+		}
+	}
+	reprocessSnapshotsMu.Lock()
+	reprocessSnapshots[pipelineId] = snapshot
+	reprocessSnapshotsMu.Unlock()
+	return snapshot, nil
+}
+
+// rawDataFilter returns the sql condition selecting the rows of the table aliased as `alias` derived from the raw
+// data read by the pipeline, empty if no raw data was read
+func (snapshot *reprocessSnapshot) rawDataFilter(alias string) (string, []interface{}) {
+	snapshot.mu.Lock()
+	defer snapshot.mu.Unlock()
+	var conditions []string
+	var params []interface{}
+	for _, rawTable := range snapshot.rawTables {
+		rawParams := make([]string, 0, len(snapshot.rawData[rawTable]))
+		for p := range snapshot.rawData[rawTable] {
+			rawParams = append(rawParams, p)
+		}
+		if len(rawParams) == 0 {
+			continue
+		}
+		sort.Strings(rawParams)
+		conditions = append(conditions, fmt.Sprintf("(%s._raw_data_table = ? AND %s._raw_data_params IN ?)", alias, alias))
+		params = append(params, rawTable, rawParams)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", params
+}
+
+// report compares the domain tables with the snapshots, saves the ReprocessReports of the tables changed and
+// drops the snapshots
+func (snapshot *reprocessSnapshot) report(basicRes context.BasicRes) errors.Error {
+	defer snapshot.drop(basicRes)
+	db := basicRes.GetDal()
+	tFilter, tParams := snapshot.rawDataFilter("t")
+	sFilter, sParams := snapshot.rawDataFilter("s")
+	if tFilter == "" {
+		return nil
+	}
+	var errs []error
+	for _, table := range snapshot.tables {
+		firstPk := quoteColumn(db, table.primaryKeys[0])
+		report := &models.ReprocessReport{
+			PipelineId:  snapshot.pipelineId,
+			DomainTable: table.name,
+		}
+		var err errors.Error
+		report.Inserted, err = db.Count(
+			dal.From(table.name+" t"),
+			dal.Join(fmt.Sprintf("LEFT JOIN %s s ON %s", table.snapshot, table.joinOn(db))),
+			dal.Where(fmt.Sprintf("%s AND s.%s IS NULL", tFilter, firstPk), tParams...),
+		)
+		if err == nil {
+			report.Deleted, err = db.Count(
+				dal.From(table.snapshot+" s"),
+				dal.Join(fmt.Sprintf("LEFT JOIN %s t ON %s", table.name, table.joinOn(db))),
+				dal.Where(fmt.Sprintf("%s AND t.%s IS NULL", sFilter, firstPk), sParams...),
+			)
+		}
+		if err == nil {
+			report.Updated, err = db.Count(
+				dal.From(table.name+" t"),
+				dal.Join(fmt.Sprintf("JOIN %s s ON %s", table.snapshot, table.joinOn(db))),
+				dal.Where(fmt.Sprintf("%s AND %s != s.row_hash", sFilter, table.rowHash(db, "t")), sParams...),
+			)
+		}
+		if err != nil {
+			errs = append(errs, errors.Default.Wrap(err, fmt.Sprintf("failed to compare table %s with its snapshot", table.name)))
+			continue
+		}
+		if report.Inserted+report.Updated+report.Deleted > 0 {
+			if err = db.CreateOrUpdate(report); err != nil {
+				errs = append(errs, errors.Default.Wrap(err, "failed to save the reprocess report"))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Default.Combine(errs)
+	}
+	return nil
+}
+
+// drop drops the snapshot tables and stops recording the raw data read by the pipeline
+func (snapshot *reprocessSnapshot) drop(basicRes context.BasicRes) {
+	reprocessSnapshotsMu.Lock()
+	delete(reprocessSnapshots, snapshot.pipelineId)
+	reprocessSnapshotsMu.Unlock()
+	for _, table := range snapshot.tables {
+		if err := basicRes.GetDal().DropTables(table.snapshot); err != nil {
+			basicRes.GetLogger().Error(err, "failed to drop the snapshot of table %s", table.name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	gocontext "context"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/plugin"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReprocessableSubtasks(t *testing.T) {
+	metas := []plugin.SubTaskMeta{
+		{Name: "collectIssues", ProductTables: []string{"jira_api_issues"}},
+		{Name: "extractIssues", DependencyTables: []string{"jira_api_issues"}, ProductTables: []string{"_tool_issues"}},
+		{Name: "collectComments", DependencyTables: []string{"_tool_issues"}, ProductTables: []string{"_raw_comments", "_tool_comment_cursors"}},
+		{Name: "extractComments", DependencyTables: []string{"_raw_comments"}, ProductTables: []string{"_tool_comments"}},
+		{Name: "convertCursors", DependencyTables: []string{"_tool_comment_cursors"}, ProductTables: []string{"issue_comments"}},
+		{Name: "enrichCursors", DependencyTables: []string{"issue_comments"}, ProductTables: []string{"issue_changelogs"}},
+		{Name: "convertIssues", DependencyTables: []string{"_tool_issues"}, ProductTables: []string{"issues"}},
+	}
+	assert.Equal(t, map[string]bool{
+		"extractIssues":   true,
+		"extractComments": true,
+		"convertIssues":   true,
+	}, GetReprocessableSubtasks(metas))
+}
+
+func TestIsCollectorSubtask(t *testing.T) {
+	// raw tables declared with or without the prefix
+	assert.True(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "Fetch Jobs", DependencyTables: []string{"_tool_github_runs"}, ProductTables: []string{"github_api_jobs"}}))
+	assert.True(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "fetchIssues", ProductTables: []string{"_raw_jira_api_issues"}}))
+	// calculators producing tables out of the domain layer
+	assert.False(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "collectDailyWip", DependencyTables: []string{"issues"}, ProductTables: []string{"flow_daily_wip"}}))
+	assert.False(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "Collect Stats", DependencyTables: []string{"flow_daily_wip"}, ProductTables: []string{"flow_weekly_throughput"}}))
+	assert.False(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "collectPrIssues", DependencyTables: []string{"_tool_github_pull_requests"}, ProductTables: []string{"pull_request_issues"}}))
+	// subtasks declaring no tables are told by their names
+	assert.True(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "collectIssues"}))
+	assert.True(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "Clone Git Repo"}))
+	assert.False(t, isCollectorSubtask(plugin.SubTaskMeta{Name: "extractIssues"}))
+}
+
+func TestGetPluginRawTables(t *testing.T) {
+	for _, name := range []string{"github", "github_graphql", "git", "gitlab", "gitextractor"} {
+		assert.Nil(t, plugin.RegisterPlugin(name, nil))
+	}
+	mockDal := new(mockdal.Dal)
+	mockDal.On("AllTables").Return([]string{
+		"_raw_github_api_issues",
+		"_raw_github_graphql_issues",
+		"_raw_gitlab_api_merge_requests",
+		"_raw_gitextractor_commits",
+		"_raw_git_commits",
+		"_raw_git_commits'); DROP TABLE issues; --",
+		"_tool_github_issues",
+		"issues",
+	}, nil)
+	rawTables, err := getPluginRawTables(mockDal, []string{"github", "git"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_raw_git_commits", "_raw_github_api_issues"}, rawTables)
+}
+
+func TestRecordReprocessedRawData(t *testing.T) {
+	snapshot := &reprocessSnapshot{
+		pipelineId: 1,
+		rawTables:  []string{"_raw_github_api_issues", "_raw_github_api_pulls"},
+		rawData:    make(map[string]map[string]bool),
+	}
+	reprocessSnapshots[snapshot.pipelineId] = snapshot
+	defer delete(reprocessSnapshots, snapshot.pipelineId)
+
+	filter, params := snapshot.rawDataFilter("t")
+	assert.Empty(t, filter)
+	assert.Empty(t, params)
+
+	ctx := withPipelineId(gocontext.Background(), 1)
+	RecordReprocessedRawData(ctx, "_raw_github_api_issues", `{"ConnectionId":1,"Name":"b"}`)
+	RecordReprocessedRawData(ctx, "_raw_github_api_issues", `{"ConnectionId":1,"Name":"a"}`)
+	RecordReprocessedRawData(withPipelineId(gocontext.Background(), 2), "_raw_github_api_pulls", `{"ConnectionId":1,"Name":"a"}`)
+	RecordReprocessedRawData(gocontext.Background(), "_raw_github_api_pulls", `{"ConnectionId":1,"Name":"a"}`)
+
+	filter, params = snapshot.rawDataFilter("t")
+	assert.Equal(t, "((t._raw_data_table = ? AND t._raw_data_params IN ?))", filter)
+	assert.Equal(t, []interface{}{
+		"_raw_github_api_issues",
+		[]string{`{"ConnectionId":1,"Name":"a"}`, `{"ConnectionId":1,"Name":"b"}`},
+	}, params)
+}
+
+func TestRowHash(t *testing.T) {
+	table := &reprocessTable{name: "issues", columns: []string{"id", "title"}}
+	mysql := new(mockdal.Dal)
+	mysql.On("Dialect").Return("mysql")
+	assert.Equal(t,
+		"MD5(CONCAT(MD5(CASE WHEN s.`id` IS NULL THEN 'n' ELSE CONCAT('v', CAST(s.`id` AS CHAR)) END), MD5(CASE WHEN s.`title` IS NULL THEN 'n' ELSE CONCAT('v', CAST(s.`title` AS CHAR)) END)))",
+		table.rowHash(mysql, "s"),
+	)
+	postgres := new(mockdal.Dal)
+	postgres.On("Dialect").Return("postgres")
+	assert.Equal(t,
+		`MD5(CONCAT(MD5(CASE WHEN s."id" IS NULL THEN 'n' ELSE CONCAT('v', CAST(s."id" AS TEXT)) END), MD5(CASE WHEN s."title" IS NULL THEN 'n' ELSE CONCAT('v', CAST(s."title" AS TEXT)) END)))`,
+		table.rowHash(postgres, "s"),
+	)
+}
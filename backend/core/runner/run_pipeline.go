@@ -44,20 +44,13 @@ func RunPipeline(
 	if err != nil {
 		return err
 	}
-	taskIds := make([][]uint64, 0)
-	for _, task := range tasks {
-		for len(taskIds) < task.PipelineRow {
-			taskIds = append(taskIds, make([]uint64, 0))
-		}
-		taskIds[task.PipelineRow-1] = append(taskIds[task.PipelineRow-1], task.ID)
-	}
-	return runPipelineTasks(basicRes, pipelineId, taskIds, runTasks)
+	return runPipelineTasks(basicRes, pipelineId, tasks, runTasks)
 }
 
 func runPipelineTasks(
 	basicRes context.BasicRes,
 	pipelineId uint64,
-	taskIds [][]uint64,
+	tasks []models.Task,
 	runTasks func([]uint64) errors.Error,
 ) errors.Error {
 	db := basicRes.GetDal()
@@ -74,6 +67,49 @@ func runPipelineTasks(
 		return nil
 	}
 
+	// snapshot the domain tables to report the rows changed by reprocessing
+	var snapshot *reprocessSnapshot
+	if dbPipeline.Reprocess {
+		snapshot, err = takeReprocessSnapshot(basicRes, dbPipeline.ID, tasks)
+		if err != nil {
+			log.Error(err, "failed to snapshot the domain tables, no reprocess report would be generated")
+		}
+	}
+
+	if basicRes.GetConfigReader().GetBool("PIPELINE_DAG_SCHEDULING") {
+		err = runPipelineTasksAsDag(basicRes, dbPipeline, tasks, runTasks)
+	} else {
+		err = runPipelineStages(basicRes, dbPipeline, tasks, runTasks)
+	}
+	if snapshot != nil {
+		if reportErr := snapshot.report(basicRes); reportErr != nil {
+			log.Error(reportErr, "failed to generate the reprocess report")
+		}
+	}
+	if dbPipeline.BeganAt != nil {
+		log.Info("pipeline finished in %d ms: %v", time.Now().UnixMilli()-dbPipeline.BeganAt.UnixMilli(), err)
+	} else {
+		log.Info("pipeline finished at %d ms: %v", time.Now().UnixMilli(), err)
+	}
+	return err
+}
+
+// runPipelineStages executes each stage of tasks sequentially while executing the tasks of a stage concurrently
+func runPipelineStages(
+	basicRes context.BasicRes,
+	dbPipeline *models.Pipeline,
+	tasks []models.Task,
+	runTasks func([]uint64) errors.Error,
+) (err errors.Error) {
+	db := basicRes.GetDal()
+	log := basicRes.GetLogger()
+	taskIds := make([][]uint64, 0)
+	for _, task := range tasks {
+		for len(taskIds) < task.PipelineRow {
+			taskIds = append(taskIds, make([]uint64, 0))
+		}
+		taskIds[task.PipelineRow-1] = append(taskIds[task.PipelineRow-1], task.ID)
+	}
 	// This double for loop executes each set of tasks sequentially while
 	// executing the set of tasks concurrently.
 	for i, row := range taskIds {
@@ -96,10 +132,5 @@ func runPipelineTasks(
 			}
 		}
 	}
-	if dbPipeline.BeganAt != nil {
-		log.Info("pipeline finished in %d ms: %v", time.Now().UnixMilli()-dbPipeline.BeganAt.UnixMilli(), err)
-	} else {
-		log.Info("pipeline finished at %d ms: %v", time.Now().UnixMilli(), err)
-	}
 	return err
 }
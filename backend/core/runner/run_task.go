@@ -27,12 +27,16 @@ import (
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/metrics"
 	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/domaininfo"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/tracing"
 	"github.com/apache/incubator-devlake/core/utils"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	contextimpl "github.com/apache/incubator-devlake/impls/context"
 	"github.com/apache/incubator-devlake/impls/logruslog"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // RunTask FIXME ...
@@ -52,14 +56,22 @@ func RunTask(
 		return err
 	}
 
+	ctx, span := tracing.Start(ctx, "task "+task.Plugin,
+		attribute.Int64("devlake.pipeline.id", int64(task.PipelineId)),
+		attribute.Int64("devlake.task.id", int64(task.ID)),
+		attribute.String("devlake.plugin", task.Plugin),
+	)
 	logger, err := getTaskLogger(basicRes.GetLogger(), task)
 	if err != nil {
+		tracing.End(span, err)
 		return err
 	}
+	logger = logruslog.WithTraceContext(logger, ctx)
 	beganAt := time.Now()
 	if task.BeganAt != nil {
 		beganAt = *task.BeganAt
 	}
+	alreadyCompleted := task.Status == models.TASK_COMPLETED
 	// make sure task status always correct even if it panicked
 	defer func() {
 		if r := recover(); r != nil {
@@ -107,6 +119,17 @@ func RunTask(
 				logger.Error(dbe, "failed to finalize task status into db (task succeeded)")
 			}
 		}
+		if !alreadyCompleted {
+			metrics.ObserveTask(task.Plugin, finishedAt.Sub(beganAt), err)
+		}
+		tracing.End(span, err)
+		if taskStatusListener != nil && !alreadyCompleted {
+			status := models.TASK_COMPLETED
+			if err != nil {
+				status = models.TASK_FAILED
+			}
+			taskStatusListener(task, status, err)
+		}
 		// update finishedTasks
 		errors.Must(db.UpdateColumn(
 			&models.Pipeline{},
@@ -133,6 +156,9 @@ func RunTask(
 	if dbe != nil {
 		return dbe
 	}
+	if taskStatusListener != nil {
+		taskStatusListener(task, models.TASK_RUNNING, nil)
+	}
 
 	err = RunPluginTask(
 		ctx,
@@ -183,51 +209,9 @@ func RunPluginSubTasks(
 	logger.Info("start plugin")
 	// find out all possible subtasks this plugin can offer
 	subtaskMetas := pluginTask.SubTaskMetas()
-	subtasksFlag := make(map[string]bool)
-	for _, subtaskMeta := range subtaskMetas {
-		subtasksFlag[subtaskMeta.Name] = subtaskMeta.EnabledByDefault
-	}
-	/* subtasksFlag example
-	subtasksFlag := map[string]bool{
-		"collectProject": true,
-		"convertCommits": true,
-		...
-	}
-	*/
-
-	// user specifies what subtasks to run
-	if len(task.Subtasks) != 0 {
-		// decode user specified subtasks
-		var specifiedTasks []string
-		err := api.Decode(task.Subtasks, &specifiedTasks, nil)
-		if err != nil {
-			return errors.Default.Wrap(err, "subtasks could not be decoded")
-		}
-		if len(specifiedTasks) > 0 {
-			// first, disable all subtasks
-			for task := range subtasksFlag {
-				subtasksFlag[task] = false
-			}
-			// second, check specified subtasks is valid and enable them if so
-			for _, task := range specifiedTasks {
-				if _, ok := subtasksFlag[task]; ok {
-					subtasksFlag[task] = true
-				} else {
-					return errors.Default.New(fmt.Sprintf("subtask %s does not exist", task))
-				}
-			}
-		}
-	}
-
-	// 1. make sure `Collect` subtasks skip if `SkipCollectors` is true
-	// 2. make sure `Required` subtasks are always enabled
-	for _, subtaskMeta := range subtaskMetas {
-		if syncPolicy != nil && syncPolicy.SkipCollectors && strings.Contains(strings.ToLower(subtaskMeta.Name), "collect") {
-			subtasksFlag[subtaskMeta.Name] = false
-		}
-		if subtaskMeta.Required {
-			subtasksFlag[subtaskMeta.Name] = true
-		}
+	subtasksFlag, err := GetSubtasksFlag(subtaskMetas, task.Subtasks, syncPolicy)
+	if err != nil {
+		return err
 	}
 
 	// calculate total step(number of task to run)
@@ -238,6 +222,13 @@ func RunPluginSubTasks(
 		}
 	}
 
+	// the resources bound to the task context, i.e. the rate limit budgets of the api clients, are released
+	// once the task is over
+	ctx, cancel := gocontext.WithCancel(ctx)
+	defer cancel()
+	// the requests sent by the api clients of the task are traced as children of the running subtask
+	ctx = tracing.WithActiveSpan(ctx)
+	ctx = withPipelineId(ctx, task.PipelineId)
 	taskCtx := contextimpl.NewDefaultTaskContext(ctx, basicRes, task.Plugin, subtasksFlag, progress)
 	if closeablePlugin, ok := pluginTask.(plugin.CloseablePluginTask); ok {
 		defer closeablePlugin.Close(taskCtx)
@@ -265,7 +256,7 @@ func RunPluginSubTasks(
 			// subtask was disabled
 			continue
 		}
-		if strings.Contains(strings.ToLower(subtaskMeta.Name), "collect") || strings.Contains(strings.ToLower(subtaskMeta.Name), "clone git repo") {
+		if isCollectorSubtask(subtaskMeta) {
 			collectSubtaskNumber++
 			isCollector = true
 		} else {
@@ -324,7 +315,7 @@ func RunPluginSubTasks(
 			logger.Info("subtask %s already finished previously", subtaskMeta.Name)
 		} else {
 			logger.Info("executing subtask %s", subtaskMeta.Name)
-			err = runSubtask(basicRes, subtaskCtx, task.ID, subtaskNumber, subtaskMeta.EntryPoint)
+			err = runSubtaskWithRetry(ctx, basicRes, subtaskCtx, task, subtaskNumber, &subtaskMeta)
 			if err != nil {
 				err = errors.SubtaskErr.Wrap(err, fmt.Sprintf("subtask %s ended unexpectedly", subtaskMeta.Name), errors.WithData(&subtaskMeta))
 				logger.Error(err, "")
@@ -344,6 +335,135 @@ func RunPluginSubTasks(
 	return nil
 }
 
+// GetSubtasksFlag determines which subtasks should be executed based on the user specified `subtasks` and `syncPolicy`
+func GetSubtasksFlag(
+	subtaskMetas []plugin.SubTaskMeta,
+	subtasks []string,
+	syncPolicy *models.SyncPolicy,
+) (map[string]bool, errors.Error) {
+	subtasksFlag := make(map[string]bool)
+	for _, subtaskMeta := range subtaskMetas {
+		subtasksFlag[subtaskMeta.Name] = subtaskMeta.EnabledByDefault
+	}
+	/* subtasksFlag example
+	subtasksFlag := map[string]bool{
+		"collectProject": true,
+		"convertCommits": true,
+		...
+	}
+	*/
+
+	// user specifies what subtasks to run
+	if len(subtasks) != 0 {
+		// decode user specified subtasks
+		var specifiedTasks []string
+		err := api.Decode(subtasks, &specifiedTasks, nil)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "subtasks could not be decoded")
+		}
+		if len(specifiedTasks) > 0 {
+			// first, disable all subtasks
+			for task := range subtasksFlag {
+				subtasksFlag[task] = false
+			}
+			// second, check specified subtasks is valid and enable them if so
+			for _, task := range specifiedTasks {
+				if _, ok := subtasksFlag[task]; ok {
+					subtasksFlag[task] = true
+				} else {
+					return nil, errors.Default.New(fmt.Sprintf("subtask %s does not exist", task))
+				}
+			}
+		}
+	}
+
+	// 1. make sure `Collect` subtasks skip if `SkipCollectors` is true
+	// 2. make sure `Required` subtasks are always enabled
+	// 3. make sure only the subtasks depending on the raw data run if `Reprocess` is true
+	var reprocessable map[string]bool
+	if syncPolicy != nil && syncPolicy.Reprocess {
+		reprocessable = GetReprocessableSubtasks(subtaskMetas)
+	}
+	for _, subtaskMeta := range subtaskMetas {
+		if syncPolicy != nil && syncPolicy.SkipCollectors && isCollectorSubtask(subtaskMeta) {
+			subtasksFlag[subtaskMeta.Name] = false
+		}
+		if subtaskMeta.Required {
+			subtasksFlag[subtaskMeta.Name] = true
+		}
+		if reprocessable != nil && !reprocessable[subtaskMeta.Name] {
+			subtasksFlag[subtaskMeta.Name] = false
+		}
+	}
+	return subtasksFlag, nil
+}
+
+// isCollectorSubtask tells whether the subtask fetches data from the data source. Subtasks declaring their tables
+// are collectors if they produce raw tables out of nothing but the tool layer, the others are told by their names
+func isCollectorSubtask(subtaskMeta plugin.SubTaskMeta) bool {
+	if len(subtaskMeta.ProductTables) == 0 {
+		name := strings.ToLower(subtaskMeta.Name)
+		return strings.Contains(name, "collect") || strings.Contains(name, "clone git repo")
+	}
+	for _, table := range subtaskMeta.DependencyTables {
+		if !strings.HasPrefix(table, "_tool_") {
+			return false
+		}
+	}
+	for _, table := range subtaskMeta.ProductTables {
+		if isRawTable(table) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRawTable tells whether the table declared by a subtask is a raw table, which is declared either with the `_raw_`
+// prefix or by the name given to the RawDataSubTaskArgs
+func isRawTable(table string) bool {
+	if strings.HasPrefix(table, "_raw_") {
+		return true
+	}
+	if strings.HasPrefix(table, "_tool_") || strings.HasPrefix(table, "_devlake_") {
+		return false
+	}
+	for _, tabler := range domaininfo.GetDomainTablesInfo() {
+		if tabler.TableName() == table {
+			return false
+		}
+	}
+	return true
+}
+
+// GetReprocessableSubtasks returns the subtasks which could be rerun from the raw data already collected, that is
+// the extractors, convertors and enrichers whose declared `DependencyTables` are either raw tables or produced
+// by other reprocessable subtasks. Tables produced by collectors other than the raw ones would not be refreshed
+// in reprocess mode, so the subtasks depending on them are excluded as well.
+func GetReprocessableSubtasks(subtaskMetas []plugin.SubTaskMeta) map[string]bool {
+	reprocessable := make(map[string]bool)
+	stale := make(map[string]bool)
+	for _, subtaskMeta := range subtaskMetas {
+		isCollector := isCollectorSubtask(subtaskMeta)
+		ok := !isCollector
+		for _, table := range subtaskMeta.DependencyTables {
+			if stale[table] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			reprocessable[subtaskMeta.Name] = true
+			continue
+		}
+		for _, table := range subtaskMeta.ProductTables {
+			if !isCollector || !isRawTable(table) {
+				stale[table] = true
+			}
+		}
+	}
+	return reprocessable
+}
+
 // UpdateProgressDetail FIXME ...
 func UpdateProgressDetail(basicRes context.BasicRes, taskId uint64, progressDetail *models.TaskProgressDetail, p *plugin.RunningProgress) {
 	task := &models.Task{}
@@ -382,29 +502,142 @@ func UpdateProgressDetail(basicRes context.BasicRes, taskId uint64, progressDeta
 func runSubtask(
 	basicRes context.BasicRes,
 	ctx plugin.SubTaskContext,
-	parentID uint64,
+	task *models.Task,
 	subtaskNumber int,
 	entryPoint plugin.SubTaskEntryPoint,
-) errors.Error {
+) (err errors.Error) {
 	beginAt := time.Now()
 	subtask := &models.Subtask{
 		Name:    ctx.GetName(),
-		TaskID:  parentID,
+		TaskID:  task.ID,
 		Number:  subtaskNumber,
 		BeganAt: &beginAt,
 	}
 	recordSubtask(basicRes, subtask)
+	_, span := tracing.Start(ctx.GetContext(), "subtask "+subtask.Name,
+		attribute.Int64("devlake.task.id", int64(task.ID)),
+		attribute.String("devlake.plugin", task.Plugin),
+		attribute.String("devlake.subtask", subtask.Name),
+	)
+	tracing.Activate(ctx.GetContext(), span)
+	if subtaskStatusListener != nil {
+		subtaskStatusListener(task, subtask.Name, models.TASK_RUNNING, nil)
+	}
 	// defer to record subtask status
 	defer func() {
+		tracing.Activate(ctx.GetContext(), nil)
+		tracing.End(span, err)
+		if subtaskStatusListener != nil {
+			status := models.TASK_COMPLETED
+			if err != nil {
+				status = models.TASK_FAILED
+			}
+			subtaskStatusListener(task, subtask.Name, status, err)
+		}
 		finishedAt := time.Now()
 		subtask.FinishedAt = &finishedAt
 		subtask.SpentSeconds = finishedAt.Unix() - beginAt.Unix()
 
 		recordSubtask(basicRes, subtask)
+		metrics.ObserveSubtask(task.Plugin, subtask.Name, finishedAt.Sub(beginAt), err)
 	}()
 	return entryPoint(ctx)
 }
 
+// TaskStatusListener is notified when a task starts running and when it completes or fails
+type TaskStatusListener func(task *models.Task, status string, err errors.Error)
+
+var taskStatusListener TaskStatusListener
+
+// SetTaskStatusListener sets the listener notified of the status transitions of tasks
+func SetTaskStatusListener(listener TaskStatusListener) {
+	taskStatusListener = listener
+}
+
+// SubtaskStatusListener is notified when an attempt of a subtask starts running and when it completes or fails
+type SubtaskStatusListener func(task *models.Task, subtask string, status string, err errors.Error)
+
+var subtaskStatusListener SubtaskStatusListener
+
+// SetSubtaskStatusListener sets the listener notified of the status transitions of subtasks
+func SetSubtaskStatusListener(listener SubtaskStatusListener) {
+	subtaskStatusListener = listener
+}
+
+// SubtaskRetryListener is notified when a subtask failed with a transient error and is about to be retried
+type SubtaskRetryListener func(ctx gocontext.Context, task *models.Task, subtask string, attempt int, err errors.Error, backoff time.Duration)
+
+var subtaskRetryListener SubtaskRetryListener
+
+// SetSubtaskRetryListener sets the listener notified when subtasks are retried
+func SetSubtaskRetryListener(listener SubtaskRetryListener) {
+	subtaskRetryListener = listener
+}
+
+// runSubtaskWithRetry runs the subtask again while it fails with a transient error according to its retry policy,
+// and records every attempt
+func runSubtaskWithRetry(
+	ctx gocontext.Context,
+	basicRes context.BasicRes,
+	subtaskCtx plugin.SubTaskContext,
+	task *models.Task,
+	subtaskNumber int,
+	subtaskMeta *plugin.SubTaskMeta,
+) errors.Error {
+	logger := basicRes.GetLogger()
+	policy := subtaskMeta.RetryPolicy
+	for attempt := 1; ; attempt++ {
+		beganAt := time.Now()
+		err := runSubtask(basicRes, subtaskCtx, task, subtaskNumber, subtaskMeta.EntryPoint)
+		finishedAt := time.Now()
+		transient := policy != nil && policy.IsTransient(err)
+		recordSubtaskAttempt(basicRes, &models.SubtaskAttempt{
+			TaskID:     task.ID,
+			Name:       subtaskMeta.Name,
+			Attempt:    attempt,
+			BeganAt:    &beganAt,
+			FinishedAt: &finishedAt,
+			IsFailed:   err != nil,
+			Transient:  transient,
+			Message:    errorMessage(err),
+		})
+		if err == nil || !transient || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		backoff := policy.BackoffOf(attempt)
+		logger.Warn(err, "subtask %s failed with a transient error, attempt %d of %d would start in %s", subtaskMeta.Name, attempt+1, policy.MaxAttempts, backoff)
+		if subtaskRetryListener != nil {
+			subtaskRetryListener(ctx, task, subtaskMeta.Name, attempt, err, backoff)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Convert(ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func errorMessage(err errors.Error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// recordSubtaskAttempt keeps the attempt history and the number of attempts of the subtask
+func recordSubtaskAttempt(basicRes context.BasicRes, attempt *models.SubtaskAttempt) {
+	db := basicRes.GetDal()
+	if err := db.Create(attempt); err != nil {
+		basicRes.GetLogger().Error(err, "error writing attempt %d of subtask %s to DB", attempt.Attempt, attempt.Name)
+	}
+	where := dal.Where("task_id = ? and name = ?", attempt.TaskID, attempt.Name)
+	if err := db.UpdateColumns(&models.Subtask{}, []dal.DalSet{
+		{ColumnName: "attempts", Value: attempt.Attempt},
+	}, where); err != nil {
+		basicRes.GetLogger().Error(err, "error writing attempts of subtask %s to DB", attempt.Name)
+	}
+}
+
 func recordSubtask(basicRes context.BasicRes, subtask *models.Subtask) {
 	where := dal.Where("task_id = ? and name = ?", subtask.TaskID, subtask.Name)
 	if err := basicRes.GetDal().UpdateColumns(subtask, []dal.DalSet{
@@ -429,5 +662,9 @@ func getTaskLogger(parentLogger log.Logger, task *models.Task) (log.Logger, erro
 		Path:   loggingPath,
 		Writer: stream,
 	})
-	return logger, nil
+	return logruslog.WithFields(logger, map[string]interface{}{
+		logruslog.FieldPipelineId: task.PipelineId,
+		logruslog.FieldTaskId:     task.ID,
+		logruslog.FieldPlugin:     task.Plugin,
+	}), nil
 }
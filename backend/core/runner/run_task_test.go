@@ -0,0 +1,75 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	gocontext "context"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunSubtaskWithRetryAfterAsyncTransientError(t *testing.T) {
+	var attempts []*models.SubtaskAttempt
+	basicRes := unithelper.DummyBasicRes(func(mockDal *mockdal.Dal) {
+		mockDal.On("UpdateColumns", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockDal.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			attempts = append(attempts, args.Get(0).(*models.SubtaskAttempt))
+		}).Return(nil)
+	})
+	subtaskCtx := unithelper.DummySubTaskContext(basicRes.GetDal())
+	subtaskCtx.On("GetContext").Return(gocontext.Background())
+
+	// the scheduler outlives the attempts the same way the one of an ApiAsyncClient does
+	scheduler, err := api.NewWorkerScheduler(gocontext.Background(), 1, time.Millisecond, unithelper.DummyLogger())
+	assert.Nil(t, err)
+	defer scheduler.Release()
+	calls := 0
+	subtaskMeta := &plugin.SubTaskMeta{
+		Name: "collectIssues",
+		EntryPoint: func(plugin.SubTaskContext) errors.Error {
+			calls++
+			failed := calls == 1
+			scheduler.SubmitBlocking(func() errors.Error {
+				if failed {
+					return errors.HttpStatus(502).New("bad gateway")
+				}
+				return nil
+			})
+			return scheduler.WaitAsync()
+		},
+		RetryPolicy: &plugin.SubTaskRetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+	}
+
+	err = runSubtaskWithRetry(gocontext.Background(), basicRes, subtaskCtx, &models.Task{Plugin: "jira"}, 1, subtaskMeta)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	if assert.Len(t, attempts, 2) {
+		assert.True(t, attempts[0].IsFailed)
+		assert.True(t, attempts[0].Transient)
+		assert.False(t, attempts[1].IsFailed)
+	}
+}
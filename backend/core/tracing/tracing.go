@@ -0,0 +1,142 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/apache/incubator-devlake"
+	serviceName         = "devlake"
+)
+
+// Init exports the spans to the OTLP/HTTP collector at OTEL_EXPORTER_OTLP_ENDPOINT when ENABLE_TRACING is set, the
+// spans are dropped otherwise. The returned function flushes the pending spans.
+func Init(cfg config.ConfigReader) (func(ctx context.Context) error, errors.Error) {
+	if !cfg.GetBool("ENABLE_TRACING") {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+	// the other `OTEL_EXPORTER_OTLP_*` variables are read by the exporter from the environment
+	var opts []otlptracehttp.Option
+	if endpoint := cfg.GetString("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		endpointUrl, err := url.Parse(endpoint)
+		if err != nil || endpointUrl.Host == "" {
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid OTEL_EXPORTER_OTLP_ENDPOINT %s", endpoint))
+		}
+		opts = append(opts,
+			otlptracehttp.WithEndpoint(endpointUrl.Host),
+			otlptracehttp.WithURLPath(strings.TrimSuffix(endpointUrl.Path, "/")+"/v1/traces"),
+		)
+		if endpointUrl.Scheme == "http" {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to create the OTLP trace exporter")
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default service name
+	res, err := resource.Merge(
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)),
+		resource.Default(),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to describe the trace resource")
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in ctx, or of the span activated by Activate
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ActiveContext(ctx), name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span and marks it as failed if err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// activeSpanKey carries the activeSpan of a context
+type activeSpanKey struct{}
+
+// activeSpan holds the span of the step being run by a long-lived context, i.e. the task context is shared by all
+// its subtasks and the api clients created out of it, their requests should be traced as children of the
+// subtask running at the time
+type activeSpan struct {
+	mu   sync.RWMutex
+	span trace.Span
+}
+
+// WithActiveSpan returns a context in which the span passed to Activate becomes the parent of the spans started
+// by Start, the span of ctx stays the parent until then
+func WithActiveSpan(ctx context.Context) context.Context {
+	return context.WithValue(ctx, activeSpanKey{}, &activeSpan{})
+}
+
+// Activate activates the span in the context created by WithActiveSpan, nil falls back to the span of the context
+func Activate(ctx context.Context, span trace.Span) {
+	if active, ok := ctx.Value(activeSpanKey{}).(*activeSpan); ok {
+		active.mu.Lock()
+		defer active.mu.Unlock()
+		active.span = span
+	}
+}
+
+// ActiveContext returns ctx with its active span as the current span, or ctx itself if it has none
+func ActiveContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	active, ok := ctx.Value(activeSpanKey{}).(*activeSpan)
+	if !ok {
+		return ctx
+	}
+	active.mu.RLock()
+	defer active.mu.RUnlock()
+	if active.span != nil {
+		return trace.ContextWithSpan(ctx, active.span)
+	}
+	return ctx
+}
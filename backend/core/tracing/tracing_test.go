@@ -0,0 +1,88 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInit(t *testing.T) {
+	var received int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			atomic.AddInt32(&received, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	cfg := viper.New()
+	shutdown, err := Init(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, shutdown(context.Background()))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&received))
+
+	cfg.Set("ENABLE_TRACING", true)
+	cfg.Set("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL)
+	shutdown, err = Init(cfg)
+	assert.Nil(t, err)
+	_, span := Start(context.Background(), "pipeline")
+	End(span, nil)
+	assert.Nil(t, shutdown(context.Background()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&received))
+
+	cfg.Set("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost")
+	_, err = Init(cfg)
+	assert.NotNil(t, err)
+}
+
+func TestActivate(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	taskCtx, taskSpan := Start(context.Background(), "task")
+	taskCtx = WithActiveSpan(taskCtx)
+	_, subtaskSpan := Start(taskCtx, "subtask")
+	Activate(taskCtx, subtaskSpan)
+	_, requestSpan := Start(taskCtx, "GET")
+	End(requestSpan, nil)
+	Activate(taskCtx, nil)
+	End(subtaskSpan, nil)
+	_, afterSpan := Start(taskCtx, "GET")
+	End(afterSpan, nil)
+	End(taskSpan, nil)
+
+	parents := map[string]trace.SpanID{}
+	for _, s := range exporter.GetSpans() {
+		parents[s.Name+"@"+s.SpanContext.SpanID().String()] = s.Parent.SpanID()
+	}
+	assert.Equal(t, subtaskSpan.SpanContext().SpanID(), parents["GET@"+requestSpan.SpanContext().SpanID().String()])
+	assert.Equal(t, taskSpan.SpanContext().SpanID(), parents["subtask@"+subtaskSpan.SpanContext().SpanID().String()])
+	assert.Equal(t, taskSpan.SpanContext().SpanID(), parents["GET@"+afterSpan.SpanContext().SpanID().String()])
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/models"
 	common "github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/core/utils"
 	"github.com/spf13/viper"
 	"regexp"
@@ -45,6 +46,9 @@ type ApiKeyHelper struct {
 	cfg              *viper.Viper
 	logger           log.Logger
 	encryptionSecret string
+	// previousSecrets are the secrets in ENCRYPTION_PREVIOUS_SECRETS, the api keys hashed with them before the
+	// rotation are still accepted and re-hashed with the current secret
+	previousSecrets []string
 }
 
 func NewApiKeyHelper(basicRes context.BasicRes, logger log.Logger) *ApiKeyHelper {
@@ -53,11 +57,20 @@ func NewApiKeyHelper(basicRes context.BasicRes, logger log.Logger) *ApiKeyHelper
 	if encryptionSecret == "" {
 		panic("ENCRYPTION_SECRET must be set in environment variable or .env file")
 	}
+	keyRing, err := plugin.NewEncryptionKeyRing(
+		strings.TrimSpace(cfg.GetString(plugin.EncryptionKeyIdEnvStr)),
+		encryptionSecret,
+		cfg.GetString(plugin.EncryptionPreviousSecretsEnvStr),
+	)
+	if err != nil {
+		panic(err)
+	}
 	return &ApiKeyHelper{
 		basicRes:         basicRes,
 		cfg:              cfg,
 		logger:           logger,
 		encryptionSecret: encryptionSecret,
+		previousSecrets:  keyRing.PreviousSecrets(),
 	}
 }
 
@@ -220,7 +233,11 @@ func (c *ApiKeyHelper) generateApiKey() (apiKey string, hashedApiKey string, err
 }
 
 func (c *ApiKeyHelper) DigestToken(token string) (string, errors.Error) {
-	h := hmac.New(sha256.New, []byte(c.encryptionSecret))
+	return c.digestToken(c.encryptionSecret, token)
+}
+
+func (c *ApiKeyHelper) digestToken(secret string, token string) (string, errors.Error) {
+	h := hmac.New(sha256.New, []byte(secret))
 	if _, err := h.Write([]byte(token)); err != nil {
 		c.logger.Error(err, "hmac write api key")
 		return "", errors.Default.Wrap(err, "hmac write token")
@@ -228,3 +245,38 @@ func (c *ApiKeyHelper) DigestToken(token string) (string, errors.Error) {
 	hashedApiKey := fmt.Sprintf("%x", h.Sum(nil))
 	return hashedApiKey, nil
 }
+
+// FindApiKeyByToken returns the api key of the `token`. The api keys hashed with a previous ENCRYPTION_SECRET are
+// re-hashed with the current one once they are used, so they keep working after the secret was rotated as long
+// as the previous secret is in ENCRYPTION_PREVIOUS_SECRETS.
+func (c *ApiKeyHelper) FindApiKeyByToken(token string) (*models.ApiKey, errors.Error) {
+	db := c.basicRes.GetDal()
+	hashedApiKey, err := c.DigestToken(token)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := c.GetApiKey(db, dal.Where("api_key = ?", hashedApiKey))
+	if err == nil || !db.IsErrorNotFound(err) {
+		return apiKey, err
+	}
+	for _, secret := range c.previousSecrets {
+		previousHashedApiKey, digestErr := c.digestToken(secret, token)
+		if digestErr != nil {
+			return nil, digestErr
+		}
+		apiKey, previousErr := c.GetApiKey(db, dal.Where("api_key = ?", previousHashedApiKey))
+		if previousErr != nil {
+			if db.IsErrorNotFound(previousErr) {
+				continue
+			}
+			return nil, previousErr
+		}
+		apiKey.ApiKey = hashedApiKey
+		if updateErr := db.UpdateColumn(&models.ApiKey{}, "api_key", hashedApiKey, dal.Where("id = ?", apiKey.ID)); updateErr != nil {
+			// the key is still valid, it would be re-hashed the next time
+			c.logger.Error(updateErr, "failed to re-hash api key %d with the current secret", apiKey.ID)
+		}
+		return apiKey, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,68 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeyhelper
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFindApiKeyByTokenHashedWithPreviousSecret(t *testing.T) {
+	helper := &ApiKeyHelper{logger: unithelper.DummyLogger(), encryptionSecret: "secret-2", previousSecrets: []string{"secret-1"}}
+	currentHash, err := helper.DigestToken("token")
+	assert.Nil(t, err)
+	previousHash, err := helper.digestToken("secret-1", "token")
+	assert.Nil(t, err)
+
+	notFound := errors.NotFound.New("record not found")
+	helper.basicRes = unithelper.DummyBasicRes(func(mockDal *mockdal.Dal) {
+		mockDal.On("IsErrorNotFound", notFound).Return(true)
+		mockDal.On("First", mock.Anything, []dal.Clause{dal.Where("api_key = ?", currentHash)}).Return(notFound).Once()
+		mockDal.On("First", mock.Anything, []dal.Clause{dal.Where("api_key = ?", previousHash)}).Run(func(args mock.Arguments) {
+			apiKey := args.Get(0).(*models.ApiKey)
+			apiKey.ID = 1
+			apiKey.ApiKey = previousHash
+		}).Return(nil).Once()
+		mockDal.On("UpdateColumn", &models.ApiKey{}, "api_key", currentHash, []dal.Clause{dal.Where("id = ?", uint64(1))}).Return(nil).Once()
+	})
+
+	apiKey, err := helper.FindApiKeyByToken("token")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), apiKey.ID)
+	assert.Equal(t, currentHash, apiKey.ApiKey)
+	helper.basicRes.GetDal().(*mockdal.Dal).AssertNumberOfCalls(t, "UpdateColumn", 1)
+}
+
+func TestFindApiKeyByTokenNotFound(t *testing.T) {
+	helper := &ApiKeyHelper{logger: unithelper.DummyLogger(), encryptionSecret: "secret-2", previousSecrets: []string{"secret-1"}}
+	notFound := errors.NotFound.New("record not found")
+	helper.basicRes = unithelper.DummyBasicRes(func(mockDal *mockdal.Dal) {
+		mockDal.On("IsErrorNotFound", notFound).Return(true)
+		mockDal.On("First", mock.Anything, mock.Anything).Return(notFound).Twice()
+	})
+
+	_, err := helper.FindApiKeyByToken("token")
+	assert.Equal(t, notFound, err)
+}
@@ -24,6 +24,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/apache/incubator-devlake/core/errors"
@@ -44,6 +45,11 @@ type ApiAsyncClient struct {
 	maxRetry     int
 	numOfWorkers int
 	logger       log.Logger
+	// budget is the quota shared with the other clients of the same connection, the tick interval is adjusted
+	// by it after every response but never gets shorter than baseTickInterval calculated at creation
+	budget           *RateLimitBudget
+	baseTickInterval time.Duration
+	releaseBudget    sync.Once
 }
 
 const defaultTimeout = 120 * time.Second
@@ -122,14 +128,53 @@ func CreateAsyncApiClient(
 		return nil, errors.Default.Wrap(err, "failed to create scheduler")
 	}
 
+	credentials := rateLimiter.Credentials
+	if credentials == 0 {
+		credentials = apiClient.GetCredentialsCount()
+	}
+
 	// finally, wrap around api client with async sematic
-	return &ApiAsyncClient{
-		apiClient,
-		scheduler,
-		retry,
-		numOfWorkers,
-		logger,
-	}, nil
+	asyncClient := &ApiAsyncClient{
+		ApiClient:        apiClient,
+		WorkerScheduler:  scheduler,
+		maxRetry:         retry,
+		numOfWorkers:     numOfWorkers,
+		logger:           logger,
+		budget:           acquireRateLimitBudget(apiClient.GetQuotaKey(), credentials),
+		baseTickInterval: tickInterval,
+	}
+	// most plugins never release their clients, the budget is detached once the task is over
+	go func() {
+		<-taskCtx.GetContext().Done()
+		asyncClient.detachBudget()
+	}()
+	return asyncClient, nil
+}
+
+// adjustRateLimit updates the shared budget with the quota reported by the response, then slows down or speeds
+// up the scheduler to spread the remaining quota evenly until it resets
+func (apiClient *ApiAsyncClient) adjustRateLimit(res *http.Response) {
+	if apiClient.budget == nil || res == nil {
+		return
+	}
+	now := time.Now()
+	pause := apiClient.budget.update(ParseRateLimitQuota(res, now), now)
+	if pause > 0 {
+		apiClient.logger.Warn(nil, "rate limit of %s reached, pausing requests for %s", apiClient.GetEndpoint(), pause.String())
+		if rateLimitListener != nil {
+			rateLimitListener(apiClient.WorkerScheduler.ctx, apiClient.GetEndpoint(), pause)
+		}
+	}
+	interval := apiClient.budget.interval(now)
+	if interval < apiClient.baseTickInterval {
+		interval = apiClient.baseTickInterval
+	}
+	// avoid resetting the ticker for tiny changes
+	current := apiClient.GetTickInterval()
+	if interval > current*11/10 || interval < current*9/10 {
+		apiClient.logger.Debug("adjusting tick interval of %s from %s to %s", apiClient.GetEndpoint(), current.String(), interval.String())
+		apiClient.Reset(interval)
+	}
 }
 
 // GetMaxRetry returns the maximum retry attempts for a request
@@ -160,8 +205,15 @@ func (apiClient *ApiAsyncClient) DoAsync(
 		var res *http.Response
 		var respBody []byte
 
+		// wait for the pause asked by the server, i.e. Retry-After, before sending the request
+		if apiClient.budget != nil {
+			if err := apiClient.budget.wait(apiClient.WorkerScheduler.ctx); err != nil {
+				return err
+			}
+		}
 		apiClient.logger.Debug("endpoint: %s  method: %s  header: %s  body: %s query: %s", path, method, header, body, query)
 		res, err = apiClient.Do(method, path, query, body, header)
+		apiClient.adjustRateLimit(res)
 		if err == ErrIgnoreAndContinue {
 			// make sure defer func got be executed
 			err = nil //nolint
@@ -239,6 +291,22 @@ func (apiClient *ApiAsyncClient) DoPostAsync(
 	apiClient.DoAsync(http.MethodPost, path, query, body, header, handler, 0)
 }
 
+// Release the scheduler and detach from the shared rate limit budget
+func (apiClient *ApiAsyncClient) Release() {
+	apiClient.WorkerScheduler.Release()
+	apiClient.detachBudget()
+}
+
+// detachBudget stops the client from being counted by the shared rate limit budget, it is safe to be called
+// multiple times
+func (apiClient *ApiAsyncClient) detachBudget() {
+	apiClient.releaseBudget.Do(func() {
+		if apiClient.budget != nil {
+			apiClient.budget.release()
+		}
+	})
+}
+
 // GetNumOfWorkers to return the Workers count if scheduler.
 func (apiClient *ApiAsyncClient) GetNumOfWorkers() int {
 	return apiClient.numOfWorkers
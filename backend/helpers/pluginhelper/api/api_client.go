@@ -40,7 +40,12 @@ import (
 	"github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/metrics"
+	"github.com/apache/incubator-devlake/core/tracing"
 	"github.com/apache/incubator-devlake/core/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 )
 
 // ErrIgnoreAndContinue is a error which should be ignored
@@ -66,6 +71,13 @@ type ApiClient struct {
 	afterResponse plugin.ApiClientAfterResponse
 	ctx           gocontext.Context
 	logger        log.Logger
+	// quotaKey identifies the rate limit quota the requests consume, the endpoint would be used if empty
+	quotaKey string
+	// credentials rotates the credentials of the connection if it holds more than one
+	credentials *CredentialPool
+	// plugin and connectionId label the metrics of the requests
+	pluginName   string
+	connectionId string
 }
 
 // NewApiClientFromConnection creates ApiClient based on given connection.
@@ -81,6 +93,11 @@ func NewApiClientFromConnection(
 	if err != nil {
 		return nil, err
 	}
+	// all requests of the same connection consume the same quota
+	if identifiable, ok := connection.(interface{ ConnectionId() uint64 }); ok {
+		apiClient.SetQuotaKey(fmt.Sprintf("%T#%d", connection, identifiable.ConnectionId()))
+		apiClient.SetMetricsLabels(pluginNameOf(connection), fmt.Sprintf("%d", identifiable.ConnectionId()))
+	}
 
 	// if connection needs to prepare the ApiClient, i.e. fetch token for future requests
 	if prepareApiClient, ok := connection.(plugin.PrepareApiClient); ok {
@@ -96,6 +113,10 @@ func NewApiClientFromConnection(
 			return authenticator.SetupAuthentication(req)
 		})
 	}
+	// rotate the credentials only if the connection authenticates the requests with the same scheme
+	if pool := NewCredentialPoolFromConnection(connection); pool != nil && pool.matchScheme(apiClient.probeAuthorization()) {
+		apiClient.SetCredentialPool(pool)
+	}
 
 	return apiClient, nil
 }
@@ -197,6 +218,56 @@ func (apiClient *ApiClient) Setup(
 	apiClient.data = map[string]interface{}{}
 }
 
+// SetQuotaKey sets the key of the rate limit quota consumed by the requests, clients with the same key share
+// the same RateLimitBudget
+func (apiClient *ApiClient) SetQuotaKey(quotaKey string) {
+	apiClient.quotaKey = quotaKey
+}
+
+// SetMetricsLabels sets the plugin and the connection the metrics of the requests are labelled with
+func (apiClient *ApiClient) SetMetricsLabels(pluginName, connectionId string) {
+	apiClient.pluginName = pluginName
+	apiClient.connectionId = connectionId
+}
+
+// pluginNameOf returns the name of the plugin the connection is defined in, i.e. `github` for
+// `github.com/apache/incubator-devlake/plugins/github/models`
+func pluginNameOf(connection interface{}) string {
+	t := reflect.TypeOf(connection)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	parts := strings.Split(t.PkgPath(), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "plugins" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// GetQuotaKey returns the key of the rate limit quota consumed by the requests
+func (apiClient *ApiClient) GetQuotaKey() string {
+	if apiClient.quotaKey == "" {
+		return apiClient.endpoint
+	}
+	return apiClient.quotaKey
+}
+
+// SetCredentialPool sets the credentials to rotate among, the Authorization header set by the connection would
+// be replaced by the credential picked for each request
+func (apiClient *ApiClient) SetCredentialPool(credentials *CredentialPool) {
+	apiClient.credentials = credentials
+}
+
+// GetCredentialsCount returns the number of credentials the requests rotate among
+func (apiClient *ApiClient) GetCredentialsCount() int {
+	if apiClient.credentials == nil {
+		return 1
+	}
+	return apiClient.credentials.Size()
+}
+
 // SetEndpoint FIXME ...
 func (apiClient *ApiClient) SetEndpoint(endpoint string) {
 	apiClient.endpoint = endpoint
@@ -354,7 +425,25 @@ func (apiClient *ApiClient) Do(
 		}
 	}
 	apiClient.logDebug("[api-client] %v %v", method, *uri)
-	res, err = errors.Convert01(apiClient.client.Do(req))
+	sentAt := time.Now()
+	_, span := tracing.Start(apiClient.ctx, method+" "+urlTemplateOf(path),
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLFull(req.URL.String()),
+		attribute.String("url.template", urlTemplateOf(path)),
+		attribute.String("devlake.plugin", apiClient.pluginName),
+		attribute.String("devlake.connection.id", apiClient.connectionId),
+	)
+	res, err = apiClient.doWithCredentials(req)
+	statusCode := 0
+	if res != nil {
+		statusCode = res.StatusCode
+		span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+		if statusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, res.Status)
+		}
+	}
+	tracing.End(span, err)
+	metrics.ObserveApiRequest(apiClient.pluginName, apiClient.connectionId, method, statusCode, time.Since(sentAt))
 	if err != nil {
 		apiClient.logError(err, "[api-client] failed to request %s with error", req.URL.String())
 		return nil, err
@@ -375,6 +464,79 @@ func (apiClient *ApiClient) Do(
 	return res, nil
 }
 
+// urlIdSegmentRegex matches the path segments which are ids rather than part of the route, i.e. numbers,
+// commit shas and uuids
+var urlIdSegmentRegex = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{7,40}|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+
+// urlTemplateOf replaces the ids in the path with `{id}` so the requests to the same route share the same span name
+func urlTemplateOf(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		// the number after `api` is the version, i.e. `rest/api/2/search`
+		if i > 0 && segments[i-1] == "api" {
+			continue
+		}
+		if urlIdSegmentRegex.MatchString(segment) && strings.ContainsAny(segment, "0123456789") {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// probeAuthorization returns the Authorization header the requests would be sent with
+func (apiClient *ApiClient) probeAuthorization() string {
+	req, err := http.NewRequest(http.MethodGet, apiClient.endpoint, nil)
+	if err != nil {
+		return ""
+	}
+	for name, value := range apiClient.headers {
+		req.Header.Set(name, value)
+	}
+	if apiClient.beforeRequest != nil && apiClient.beforeRequest(req) != nil {
+		return ""
+	}
+	return req.Header.Get("Authorization")
+}
+
+// doWithCredentials sends the request with the credential of the pool that has the most remaining quota, the
+// credentials rejected by the server or out of quota are parked and the request is retried with the next available one
+func (apiClient *ApiClient) doWithCredentials(req *http.Request) (*http.Response, errors.Error) {
+	pool := apiClient.credentials
+	if pool == nil {
+		return errors.Convert01(apiClient.client.Do(req))
+	}
+	tried := make(map[*pooledCredential]bool)
+	credential := pool.pick(tried, true)
+	for {
+		req.Header.Set("Authorization", credential.authorization)
+		res, err := errors.Convert01(apiClient.client.Do(req))
+		if err != nil {
+			return nil, err
+		}
+		if !pool.update(credential, res) {
+			return res, nil
+		}
+		tried[credential] = true
+		next := pool.pick(tried, false)
+		if next == nil {
+			return res, nil
+		}
+		apiClient.logDebug("[api-client] credential rejected with status %d, retrying %s with another one", res.StatusCode, req.URL.String())
+		res.Body.Close()
+		req = req.Clone(req.Context())
+		if req.GetBody != nil {
+			req.Body, err = errors.Convert01(req.GetBody())
+			if err != nil {
+				return nil, err
+			}
+		}
+		credential = next
+	}
+}
+
 // Get FIXME ...
 func (apiClient *ApiClient) Get(
 	path string,
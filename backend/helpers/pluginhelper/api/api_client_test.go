@@ -95,3 +95,16 @@ func TestApiClientBlackList(t *testing.T) {
 		})
 	}
 }
+
+func TestUrlTemplateOf(t *testing.T) {
+	for path, template := range map[string]string{
+		"rest/api/2/search":                                  "rest/api/2/search",
+		"rest/agile/1.0/board/8/issue":                       "rest/agile/1.0/board/{id}/issue",
+		"repos/apache/incubator-devlake/pulls/123/commits":   "repos/apache/incubator-devlake/pulls/{id}/commits",
+		"repos/apache/devlake/commits/4fd0cb1a/status?x=1":   "repos/apache/devlake/commits/{id}/status",
+		"projects/acc3e1b2-5b1c-4a3e-9a2b-0c1d2e3f4a5b/bugs": "projects/{id}/bugs",
+		"/users/deadbeef":                                    "/users/deadbeef",
+	} {
+		assert.Equal(t, template, urlTemplateOf(path), path)
+	}
+}
@@ -172,6 +172,10 @@ func (collector *ApiCollector) Execute() errors.Error {
 			return errors.Default.Wrap(err, "error deleting data from collector")
 		}
 	}
+	err = collector.recordCollection(db, isIncremental)
+	if err != nil {
+		return err
+	}
 
 	// if MinTickInterval was specified
 	if collector.args.MinTickInterval != nil {
@@ -463,11 +467,9 @@ func (collector *ApiCollector) fetchAsync(reqData *RequestData, handler func(int
 		urlString := res.Request.URL.String()
 		rows := make([]*RawData, count)
 		for i, msg := range items {
-			rows[i] = &RawData{
-				Params: collector.params,
-				Data:   msg,
-				Url:    urlString,
-				Input:  reqData.InputJSON,
+			rows[i], err = collector.newRawData(msg, urlString, reqData.InputJSON)
+			if err != nil {
+				return errors.Default.Wrap(err, fmt.Sprintf("error compressing raw rows of %s", collector.table))
 			}
 		}
 		err = db.Create(rows, dal.From(collector.table))
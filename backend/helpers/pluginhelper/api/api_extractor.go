@@ -106,8 +106,7 @@ func (extractor *ApiExtractor) Execute() errors.Error {
 			return errors.Convert(ctx.Err())
 		default:
 		}
-		row := &RawData{}
-		err = db.Fetch(cursor, row)
+		row, err := fetchRawData(db, cursor)
 		if err != nil {
 			return errors.Default.Wrap(err, "error fetching row")
 		}
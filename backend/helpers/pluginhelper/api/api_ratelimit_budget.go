@@ -0,0 +1,198 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	gocontext "context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// RateLimitQuota is the rate limit status reported by the response headers
+type RateLimitQuota struct {
+	// Remaining is the number of requests left in the current window, -1 if unknown
+	Remaining int
+	// ResetAt is when the current window resets, zero if unknown
+	ResetAt time.Time
+	// RetryAfter is how long the server asked us to wait before the next request
+	RetryAfter time.Duration
+}
+
+// ParseRateLimitQuota parses the rate limit headers returned by GitHub and Jira (X-RateLimit-*), GitLab and the
+// IETF draft (RateLimit-*) and the standard Retry-After header. Reset values may be epoch seconds, seconds to
+// wait or timestamps
+func ParseRateLimitQuota(res *http.Response, now time.Time) *RateLimitQuota {
+	quota := &RateLimitQuota{Remaining: -1}
+	if res == nil {
+		return quota
+	}
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if remaining, err := strconv.Atoi(strings.TrimSpace(res.Header.Get(prefix + "Remaining"))); err == nil {
+			quota.Remaining = remaining
+			quota.ResetAt = parseRateLimitTime(res.Header.Get(prefix+"Reset"), now)
+			break
+		}
+	}
+	if retryAfter := parseRateLimitTime(res.Header.Get("Retry-After"), now); retryAfter.After(now) {
+		quota.RetryAfter = retryAfter.Sub(now)
+	}
+	return quota
+}
+
+func parseRateLimitTime(value string, now time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		// epoch seconds are never that small, it must be the seconds to wait
+		if seconds < 1e9 {
+			return now.Add(time.Duration(seconds * float64(time.Second)))
+		}
+		return time.Unix(int64(seconds), 0)
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04Z"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// RateLimitListener is notified when the requests of a task are paused by the rate limit of the data source
+type RateLimitListener func(ctx gocontext.Context, endpoint string, pause time.Duration)
+
+var rateLimitListener RateLimitListener
+
+// SetRateLimitListener sets the listener notified when requests are paused by the rate limit
+func SetRateLimitListener(listener RateLimitListener) {
+	rateLimitListener = listener
+}
+
+// RateLimitBudget is the quota of a connection shared by all its ApiAsyncClients within the process, so the tasks
+// running concurrently split the remaining requests instead of each assuming it owns the whole quota
+type RateLimitBudget struct {
+	key         string
+	mu          sync.Mutex
+	clients     int
+	credentials int
+	remaining   int
+	resetAt     time.Time
+	pausedUntil time.Time
+}
+
+var rateLimitBudgets = make(map[string]*RateLimitBudget)
+var rateLimitBudgetsMu sync.Mutex
+
+// acquireRateLimitBudget returns the budget of `key` and attaches a client to it, the requests rotate among
+// `credentials` credentials each with its own quota
+func acquireRateLimitBudget(key string, credentials int) *RateLimitBudget {
+	rateLimitBudgetsMu.Lock()
+	defer rateLimitBudgetsMu.Unlock()
+	budget, ok := rateLimitBudgets[key]
+	if !ok {
+		budget = &RateLimitBudget{key: key, remaining: -1}
+		rateLimitBudgets[key] = budget
+	}
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+	budget.clients++
+	if credentials < 1 {
+		credentials = 1
+	}
+	budget.credentials = credentials
+	return budget
+}
+
+// release detaches a client from the budget, the budget is dropped once no client is attached
+func (b *RateLimitBudget) release() {
+	rateLimitBudgetsMu.Lock()
+	defer rateLimitBudgetsMu.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clients--
+	if b.clients <= 0 && rateLimitBudgets[b.key] == b {
+		delete(rateLimitBudgets, b.key)
+	}
+}
+
+// update records the quota reported by a response, and returns how long all clients would be paused if the
+// response started a pause
+func (b *RateLimitBudget) update(quota *RateLimitQuota, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if quota.Remaining >= 0 {
+		b.remaining = quota.Remaining * b.credentials
+		b.resetAt = quota.ResetAt
+	}
+	var until time.Time
+	if quota.RetryAfter > 0 {
+		until = now.Add(quota.RetryAfter)
+	} else if quota.Remaining == 0 && b.credentials == 1 && quota.ResetAt.After(now) {
+		until = quota.ResetAt
+	}
+	if until.After(b.pausedUntil) {
+		alreadyPaused := b.pausedUntil.After(now)
+		b.pausedUntil = until
+		if !alreadyPaused {
+			return until.Sub(now)
+		}
+	}
+	return 0
+}
+
+// interval returns the tick interval for every client to share the remaining quota evenly until it resets,
+// 0 if the quota is unknown
+func (b *RateLimitBudget) interval(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining < 0 || !b.resetAt.After(now) {
+		return 0
+	}
+	window := b.resetAt.Sub(now)
+	if b.remaining == 0 {
+		return window
+	}
+	return window * time.Duration(b.clients) / time.Duration(b.remaining)
+}
+
+// wait blocks until the pause of the budget is over
+func (b *RateLimitBudget) wait(ctx gocontext.Context) errors.Error {
+	b.mu.Lock()
+	pause := time.Until(b.pausedUntil)
+	b.mu.Unlock()
+	if pause <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return errors.Convert(ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
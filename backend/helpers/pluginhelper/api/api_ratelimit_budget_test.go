@@ -0,0 +1,118 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimitQuota(t *testing.T) {
+	now := time.Date(2024, 10, 19, 8, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		headers  map[string]string
+		expected *RateLimitQuota
+	}{
+		{
+			name:     "no headers",
+			headers:  map[string]string{},
+			expected: &RateLimitQuota{Remaining: -1},
+		},
+		{
+			name:     "github epoch reset",
+			headers:  map[string]string{"X-RateLimit-Remaining": "4999", "X-RateLimit-Reset": "1729326600"},
+			expected: &RateLimitQuota{Remaining: 4999, ResetAt: time.Unix(1729326600, 0)},
+		},
+		{
+			name:     "gitlab",
+			headers:  map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "1729326060"},
+			expected: &RateLimitQuota{Remaining: 0, ResetAt: time.Unix(1729326060, 0)},
+		},
+		{
+			name:     "ietf draft seconds to reset",
+			headers:  map[string]string{"RateLimit-Remaining": "10", "RateLimit-Reset": "30"},
+			expected: &RateLimitQuota{Remaining: 10, ResetAt: now.Add(30 * time.Second)},
+		},
+		{
+			name:     "jira timestamp reset and retry after",
+			headers:  map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "2024-10-19T08:05Z", "Retry-After": "60"},
+			expected: &RateLimitQuota{Remaining: 0, ResetAt: now.Add(5 * time.Minute), RetryAfter: time.Minute},
+		},
+		{
+			name:     "retry after http date",
+			headers:  map[string]string{"Retry-After": "Sat, 19 Oct 2024 08:02:00 GMT"},
+			expected: &RateLimitQuota{Remaining: -1, RetryAfter: 2 * time.Minute},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			for k, v := range tc.headers {
+				res.Header.Set(k, v)
+			}
+			quota := ParseRateLimitQuota(res, now)
+			assert.Equal(t, tc.expected.Remaining, quota.Remaining)
+			assert.True(t, tc.expected.ResetAt.Equal(quota.ResetAt), "expected %v, got %v", tc.expected.ResetAt, quota.ResetAt)
+			assert.Equal(t, tc.expected.RetryAfter, quota.RetryAfter)
+		})
+	}
+}
+
+func TestRateLimitBudget(t *testing.T) {
+	now := time.Now()
+	budget := acquireRateLimitBudget("test#1", 1)
+	assert.Same(t, budget, acquireRateLimitBudget("test#1", 1))
+	assert.Equal(t, time.Duration(0), budget.interval(now))
+
+	// two clients share 100 requests in 100 seconds
+	assert.Equal(t, time.Duration(0), budget.update(&RateLimitQuota{Remaining: 100, ResetAt: now.Add(100 * time.Second)}, now))
+	assert.Equal(t, 2*time.Second, budget.interval(now))
+
+	// exhausted quota pauses all clients until reset, only the first response reports the pause
+	assert.Equal(t, 100*time.Second, budget.update(&RateLimitQuota{Remaining: 0, ResetAt: now.Add(100 * time.Second)}, now))
+	assert.Equal(t, time.Duration(0), budget.update(&RateLimitQuota{Remaining: 0, ResetAt: now.Add(100 * time.Second)}, now))
+
+	budget.release()
+	budget.release()
+	assert.NotSame(t, budget, acquireRateLimitBudget("test#1", 1))
+
+	// quota of a single token among many does not pause the others
+	multi := acquireRateLimitBudget("test#2", 3)
+	assert.Equal(t, time.Duration(0), multi.update(&RateLimitQuota{Remaining: 0, ResetAt: now.Add(time.Minute)}, now))
+	assert.Equal(t, 10*time.Second, multi.update(&RateLimitQuota{Remaining: -1, RetryAfter: 10 * time.Second}, now))
+}
+
+func TestApiAsyncClientDetachBudget(t *testing.T) {
+	budget := acquireRateLimitBudget(t.Name(), 1)
+	acquireRateLimitBudget(t.Name(), 1)
+	first := &ApiAsyncClient{budget: budget}
+	second := &ApiAsyncClient{budget: budget}
+
+	first.detachBudget()
+	first.detachBudget()
+	assert.Equal(t, 1, budget.clients)
+	assert.Same(t, budget, rateLimitBudgets[t.Name()])
+
+	second.detachBudget()
+	assert.Equal(t, 0, budget.clients)
+	assert.NotContains(t, rateLimitBudgets, t.Name())
+}
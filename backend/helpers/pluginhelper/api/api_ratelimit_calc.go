@@ -31,6 +31,10 @@ type ApiRateLimitCalculator struct {
 	Method                 string
 	ApiPath                string
 	DynamicRateLimit       func(res *http.Response) (int, time.Duration, errors.Error)
+	// Credentials is the number of credentials the requests rotate among, each of them has its own quota. The
+	// remaining quota reported by the response headers is multiplied by it, default to the size of the credential
+	// pool of the ApiClient
+	Credentials int
 }
 
 // Calculate FIXME ...
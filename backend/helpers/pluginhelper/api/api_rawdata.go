@@ -18,23 +18,77 @@ limitations under the License.
 package api
 
 import (
+	"bytes"
+	"compress/gzip"
+	gocontext "context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"time"
 
+	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
 	plugin "github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
 )
 
+// RawDataCompressionEnvStr is the config enabling the gzip compression of the `data` column of the raw tables
+const RawDataCompressionEnvStr = "RAW_DATA_COMPRESSION"
+
 // RawData is raw data structure in DB storage
 type RawData struct {
-	ID        uint64 `gorm:"primaryKey"`
-	Params    string `gorm:"type:varchar(255);index"`
-	Data      []byte
-	Url       string
-	Input     json.RawMessage `gorm:"type:json"`
-	CreatedAt time.Time
+	ID     uint64 `gorm:"primaryKey"`
+	Params string `gorm:"type:varchar(255);index"`
+	Data   []byte
+	Url    string
+	Input  json.RawMessage `gorm:"type:json"`
+	// CollectedAt is the start time of the collector run that fetched the row, rows sharing the same `Params` and
+	// `CollectedAt` form a collection. It is NULL for the rows collected before it was introduced
+	CollectedAt *time.Time
+	CreatedAt   time.Time
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// IsRawDataCompressed returns true if the data was compressed by CompressRawData
+func IsRawDataCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+// CompressRawData compresses the data with gzip, API responses never start with the gzip magic number so the
+// compressed data could be told from the uncompressed one
+func CompressRawData(data []byte) ([]byte, errors.Error) {
+	if len(data) == 0 || IsRawDataCompressed(data) {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, errors.Convert(err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Convert(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecompressRawData decompresses the data compressed by CompressRawData, the uncompressed data is returned as is
+func DecompressRawData(data []byte) ([]byte, errors.Error) {
+	if !IsRawDataCompressed(data) {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to decompress raw data")
+	}
+	defer reader.Close()
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to decompress raw data")
+	}
+	return decompressed, nil
 }
 
 type TaskOptions interface {
@@ -58,9 +112,21 @@ type RawDataSubTaskArgs struct {
 
 // RawDataSubTask is Common features for raw data sub-tasks
 type RawDataSubTask struct {
-	args   *RawDataSubTaskArgs
-	table  string
-	params string
+	args        *RawDataSubTaskArgs
+	table       string
+	params      string
+	compress    bool
+	collectedAt time.Time
+}
+
+// RawDataListener is notified of the raw data of `params` in `table` handled by a subtask running with `ctx`
+type RawDataListener func(ctx gocontext.Context, table string, params string)
+
+var rawDataListener RawDataListener
+
+// SetRawDataListener sets the listener notified of the raw data handled by the subtasks
+func SetRawDataListener(listener RawDataListener) {
+	rawDataListener = listener
 }
 
 // NewRawDataSubTask constructor for RawDataSubTask
@@ -83,10 +149,20 @@ func NewRawDataSubTask(args RawDataSubTaskArgs) (*RawDataSubTask, errors.Error)
 	} else {
 		paramsString = plugin.MarshalScopeParams(params)
 	}
+	compress, err := utils.StrToBoolOr(args.Ctx.GetConfig(RawDataCompressionEnvStr), false)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, fmt.Sprintf("invalid %s", RawDataCompressionEnvStr))
+	}
+	table := fmt.Sprintf("_raw_%s", args.Table)
+	if rawDataListener != nil {
+		rawDataListener(args.Ctx.GetContext(), table, paramsString)
+	}
 	return &RawDataSubTask{
-		args:   &args,
-		table:  fmt.Sprintf("_raw_%s", args.Table),
-		params: paramsString,
+		args:        &args,
+		table:       table,
+		params:      paramsString,
+		compress:    compress,
+		collectedAt: time.Now(),
 	}, nil
 }
 
@@ -99,3 +175,60 @@ func (r *RawDataSubTask) GetTable() string {
 func (r *RawDataSubTask) GetParams() string {
 	return r.params
 }
+
+// newRawData creates a row of the raw table for the current collection, the data would be compressed
+// if `RAW_DATA_COMPRESSION` is enabled
+func (r *RawDataSubTask) newRawData(data []byte, url string, input json.RawMessage) (*RawData, errors.Error) {
+	if r.compress {
+		var err errors.Error
+		data, err = CompressRawData(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &RawData{
+		Params:      r.params,
+		Data:        data,
+		Url:         url,
+		Input:       input,
+		CollectedAt: &r.collectedAt,
+	}, nil
+}
+
+// recordCollection records the current collection for the raw data retention, a full collection supersedes the
+// collections before it so their records are dropped
+func (r *RawDataSubTask) recordCollection(db dal.Dal, incremental bool) errors.Error {
+	if !incremental {
+		err := db.Delete(
+			&models.RawDataCollection{},
+			dal.Where("raw_table = ? AND params = ? AND collected_at < ?", r.table, r.params, r.collectedAt),
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "error deleting the superseded raw data collections")
+		}
+	}
+	err := db.CreateOrUpdate(&models.RawDataCollection{
+		RawTable:    r.table,
+		Params:      r.params,
+		CollectedAt: r.collectedAt,
+		Incremental: incremental,
+	})
+	if err != nil {
+		return errors.Default.Wrap(err, "error recording the raw data collection")
+	}
+	return nil
+}
+
+// fetchRawData fetches the next row of the raw table with the data decompressed
+func fetchRawData(db dal.Dal, cursor dal.Rows) (*RawData, errors.Error) {
+	row := &RawData{}
+	err := db.Fetch(cursor, row)
+	if err != nil {
+		return nil, err
+	}
+	row.Data, err = DecompressRawData(row.Data)
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
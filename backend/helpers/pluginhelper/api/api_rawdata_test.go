@@ -0,0 +1,50 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressRawData(t *testing.T) {
+	data := []byte(`{"id":1,"title":"compress me"}`)
+	compressed, err := CompressRawData(data)
+	assert.Nil(t, err)
+	assert.True(t, IsRawDataCompressed(compressed))
+	assert.NotEqual(t, data, compressed)
+
+	// compressing twice is a no-op
+	again, err := CompressRawData(compressed)
+	assert.Nil(t, err)
+	assert.Equal(t, compressed, again)
+
+	decompressed, err := DecompressRawData(compressed)
+	assert.Nil(t, err)
+	assert.Equal(t, data, decompressed)
+
+	// uncompressed data collected before enabling the compression is returned as is
+	decompressed, err = DecompressRawData(data)
+	assert.Nil(t, err)
+	assert.Equal(t, data, decompressed)
+
+	empty, err := CompressRawData(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, empty)
+}
@@ -0,0 +1,219 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersRule is a line of a CODEOWNERS file assigning owners to the files matching Pattern
+type CodeOwnersRule struct {
+	LineNo   int
+	Section  string
+	Optional bool
+	Pattern  string
+	Owners   []string
+}
+
+var codeOwnersSectionRegex = regexp.MustCompile(`^(\^)?\[([^\]]+)\](?:\[\d+\])?(.*)$`)
+
+// ParseCodeOwners parses the CODEOWNERS formats of GitHub, GitLab and Bitbucket. On top of the common
+// "pattern owner..." rules, it supports the sections of GitLab with their default owners, and the groups
+// ("@@@group member...") of Bitbucket which are expanded wherever they are referenced as "@@group".
+func ParseCodeOwners(content string) []*CodeOwnersRule {
+	var rules []*CodeOwnersRule
+	groups := make(map[string][]string)
+	section, optional := "", false
+	var defaultOwners []string
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if match := codeOwnersSectionRegex.FindStringSubmatch(line); match != nil {
+			section = strings.TrimSpace(match[2])
+			optional = match[1] != ""
+			defaultOwners = expandCodeOwners(splitCodeOwnersLine(match[3]), groups)
+			continue
+		}
+		tokens := splitCodeOwnersLine(line)
+		if len(tokens) == 0 {
+			continue
+		}
+		if strings.HasPrefix(tokens[0], "@@@") {
+			groups[tokens[0][3:]] = expandCodeOwners(tokens[1:], groups)
+			continue
+		}
+		// settings and merge checks of Bitbucket
+		if strings.HasPrefix(tokens[0], "CODEOWNERS.") || strings.HasPrefix(tokens[0], "Check(") {
+			continue
+		}
+		owners := expandCodeOwners(tokens[1:], groups)
+		if len(owners) == 0 {
+			owners = defaultOwners
+		}
+		rules = append(rules, &CodeOwnersRule{
+			LineNo:   i + 1,
+			Section:  section,
+			Optional: optional,
+			Pattern:  tokens[0],
+			Owners:   owners,
+		})
+	}
+	return rules
+}
+
+// splitCodeOwnersLine splits the line on whitespaces and drops the comment, escaped whitespaces and "#" are kept
+// in the tokens, other escape sequences are left for the pattern
+func splitCodeOwnersLine(line string) []string {
+	var tokens []string
+	var token strings.Builder
+	flush := func() {
+		if token.Len() > 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			if line[i] != ' ' && line[i] != '\t' && line[i] != '#' {
+				token.WriteByte(c)
+			}
+			token.WriteByte(line[i])
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		case c == '#' && token.Len() == 0:
+			return tokens
+		default:
+			token.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func expandCodeOwners(owners []string, groups map[string][]string) []string {
+	expanded := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		if strings.HasPrefix(owner, "@@") && !strings.HasPrefix(owner, "@@@") {
+			if members, ok := groups[owner[2:]]; ok {
+				expanded = append(expanded, members...)
+				continue
+			}
+		}
+		expanded = append(expanded, owner)
+	}
+	return expanded
+}
+
+// CodeOwners tells the owners of the files of a repo
+type CodeOwners struct {
+	rules    []*CodeOwnersRule
+	patterns []*regexp.Regexp
+}
+
+// NewCodeOwners compiles the rules, which must be in the order of the CODEOWNERS file
+func NewCodeOwners(rules []*CodeOwnersRule) *CodeOwners {
+	patterns := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		patterns[i] = codeOwnersPatternRegex(rule.Pattern)
+	}
+	return &CodeOwners{rules: rules, patterns: patterns}
+}
+
+// Rules returns the last rule matching the file, in each section for GitLab. The optional sections are skipped
+// unless includeOptional is true.
+func (c *CodeOwners) Rules(filePath string, includeOptional bool) []*CodeOwnersRule {
+	filePath = strings.TrimPrefix(filePath, "/")
+	var rules []*CodeOwnersRule
+	matchedSections := make(map[string]bool)
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		rule := c.rules[i]
+		section := strings.ToLower(rule.Section)
+		if matchedSections[section] || (rule.Optional && !includeOptional) || !c.patterns[i].MatchString(filePath) {
+			continue
+		}
+		matchedSections[section] = true
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Owners returns the owners of the rules matching the file, see Rules
+func (c *CodeOwners) Owners(filePath string, includeOptional bool) []string {
+	var owners []string
+	seen := make(map[string]bool)
+	for _, rule := range c.Rules(filePath, includeOptional) {
+		for _, owner := range rule.Owners {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// codeOwnersPatternRegex converts a pattern to a regex following the gitignore rules: a pattern is relative to the
+// root when it has a slash other than a trailing one and matches at any depth otherwise, and a pattern matching a
+// directory matches everything under it. As documented by GitHub, a pattern ending with "/*" only matches the files
+// right in the directory.
+func codeOwnersPatternRegex(pattern string) *regexp.Regexp {
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if strings.Contains(pattern, "/") {
+		anchored = true
+	}
+	var regex strings.Builder
+	if anchored {
+		regex.WriteString("^")
+	} else {
+		regex.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			regex.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			regex.WriteString(".*")
+			i++
+		case c == '*':
+			regex.WriteString("[^/]*")
+		case c == '?':
+			regex.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			regex.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			regex.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	switch {
+	case directory:
+		regex.WriteString("/.*$")
+	case strings.HasSuffix(pattern, "/*"):
+		regex.WriteString("$")
+	default:
+		regex.WriteString("(?:/.*)?$")
+	}
+	return regexp.MustCompile(regex.String())
+}
@@ -0,0 +1,107 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	rules := ParseCodeOwners(`# global owners
+*       @global-owner  # comment
+/docs/  docs@example.com @org/docs-team
+my\ dir/ @alice
+\#notes  @bob
+
+[Frontend] @frontend-lead
+/web/
+/web/vendor/ @carol
+^[Security][2] @sec
+*.pem
+
+@@@Backend @dave @erin
+CODEOWNERS.toplevel.assignment_routing random 1
+/api/ @@Backend @frank
+`)
+	assert.Len(t, rules, 8)
+	assert.Equal(t, &CodeOwnersRule{LineNo: 2, Pattern: "*", Owners: []string{"@global-owner"}}, rules[0])
+	assert.Equal(t, []string{"docs@example.com", "@org/docs-team"}, rules[1].Owners)
+	assert.Equal(t, "my dir/", rules[2].Pattern)
+	assert.Equal(t, "#notes", rules[3].Pattern)
+	assert.Equal(t, &CodeOwnersRule{LineNo: 8, Section: "Frontend", Pattern: "/web/", Owners: []string{"@frontend-lead"}}, rules[4])
+	assert.Equal(t, []string{"@carol"}, rules[5].Owners)
+	assert.Equal(t, &CodeOwnersRule{LineNo: 11, Section: "Security", Optional: true, Pattern: "*.pem", Owners: []string{"@sec"}}, rules[6])
+	assert.Equal(t, []string{"@dave", "@erin", "@frank"}, rules[7].Owners)
+}
+
+func TestCodeOwnersPatternRegex(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*", "a/b/c.go", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "web/app.jsx", false},
+		{"/docs/", "docs/a/b.md", true},
+		{"/docs/", "web/docs/a.md", false},
+		{"apps/", "web/apps/a.go", true},
+		{"apps/", "apps", false},
+		{"docs/*", "docs/a.md", true},
+		{"docs/*", "docs/a/b.md", false},
+		{"/build/logs", "build/logs/1.log", true},
+		{"build/logs", "src/build/logs/1.log", false},
+		{"**/logs", "a/b/logs/1.log", true},
+		{"**/logs", "logs/1.log", true},
+		{"docs/**/*.md", "docs/a/b/c.md", true},
+		{"README.md", "sub/README.md", true},
+		{"README?md", "README.md", true},
+		{"a+b", "a+b", true},
+		{"a+b", "aab", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, codeOwnersPatternRegex(c.pattern).MatchString(c.path), "%s %s", c.pattern, c.path)
+	}
+}
+
+func TestCodeOwnersOwners(t *testing.T) {
+	codeOwners := NewCodeOwners(ParseCodeOwners(`
+*            @global
+/api/        @api
+/api/docs/
+[Frontend]
+/web/        @web
+^[Optional]
+*.css        @designer
+`))
+	assert.Equal(t, []string{"@api"}, codeOwners.Owners("api/user.go", false))
+	// the last matching rule has no owner
+	assert.Empty(t, codeOwners.Owners("/api/docs/index.md", false))
+	// one owner from each section
+	assert.Equal(t, []string{"@web", "@global"}, codeOwners.Owners("web/app.js", false))
+	assert.Equal(t, []string{"@designer", "@web", "@global"}, codeOwners.Owners("web/app.css", true))
+
+	var patterns []string
+	for _, rule := range codeOwners.Rules("web/app.js", false) {
+		patterns = append(patterns, rule.Pattern)
+	}
+	assert.Equal(t, []string{"/web/", "*"}, patterns)
+	assert.Len(t, codeOwners.Rules("/api/docs/index.md", false), 1)
+}
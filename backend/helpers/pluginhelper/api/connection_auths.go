@@ -97,6 +97,11 @@ type MultiAuth struct {
 	apiAuthenticator plugin.ApiAuthenticator
 }
 
+// GetAuthMethod returns the authentication method selected by the connection
+func (ma *MultiAuth) GetAuthMethod() string {
+	return ma.AuthMethod
+}
+
 func (ma *MultiAuth) GetApiAuthenticator(connection plugin.ApiConnection) (plugin.ApiAuthenticator, errors.Error) {
 	// cache the ApiAuthenticator for performance
 	if ma.apiAuthenticator != nil {
@@ -36,12 +36,11 @@ import (
 
 // ConnectionApiHelper is used to write the CURD of connection
 type ConnectionApiHelper struct {
-	encryptionSecret string
-	log              log.Logger
-	db               dal.Dal
-	validator        *validator.Validate
-	bpManager        *services.BlueprintManager
-	pluginName       string
+	log        log.Logger
+	db         dal.Dal
+	validator  *validator.Validate
+	bpManager  *services.BlueprintManager
+	pluginName string
 }
 
 // NewConnectionHelper creates a ConnectionHelper for connection management
@@ -54,12 +53,11 @@ func NewConnectionHelper(
 		vld = validator.New()
 	}
 	return &ConnectionApiHelper{
-		encryptionSecret: basicRes.GetConfig(plugin.EncodeKeyEnvStr),
-		log:              basicRes.GetLogger(),
-		db:               basicRes.GetDal(),
-		validator:        vld,
-		bpManager:        services.NewBlueprintManager(basicRes.GetDal()),
-		pluginName:       pluginName,
+		log:        basicRes.GetLogger(),
+		db:         basicRes.GetDal(),
+		validator:  vld,
+		bpManager:  services.NewBlueprintManager(basicRes.GetDal()),
+		pluginName: pluginName,
 	}
 }
 
@@ -0,0 +1,193 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// CredentialParkDuration is how long a credential rejected by the server (401) or out of quota (403) is left out of
+// the rotation when the response doesn't tell when its quota resets
+const CredentialParkDuration = 10 * time.Minute
+
+// pooledCredential is one of the credentials held by a connection
+type pooledCredential struct {
+	// authorization is the value of the Authorization header
+	authorization string
+	// remaining is the quota left in the current window, -1 if unknown
+	remaining   int
+	resetAt     time.Time
+	parkedUntil time.Time
+}
+
+// CredentialPool holds the credentials of a connection, and picks the one with the most remaining quota for
+// each request. A credential rejected by the server is parked instead of failing the request
+type CredentialPool struct {
+	mu          sync.Mutex
+	credentials []*pooledCredential
+	now         func() time.Time
+}
+
+// NewCredentialPool creates a pool with the given Authorization header values
+func NewCredentialPool(authorizations []string) *CredentialPool {
+	pool := &CredentialPool{now: time.Now}
+	for _, authorization := range authorizations {
+		pool.credentials = append(pool.credentials, &pooledCredential{authorization: authorization, remaining: -1})
+	}
+	return pool
+}
+
+// NewCredentialPoolFromConnection returns the pool of the credentials held by the connection, nil if it holds
+// less than 2 of them. Only AccessToken and BasicAuth are pooled: the tokens are separated by comma, and so are
+// the usernames and the passwords which must pair up
+func NewCredentialPoolFromConnection(connection plugin.ApiConnection) *CredentialPool {
+	authMethod := ""
+	if multiAuth, ok := connection.(plugin.MultiAuthenticator); ok {
+		authMethod = multiAuth.GetAuthMethod()
+	}
+	var authorizations []string
+	if authMethod == "" || authMethod == plugin.AUTH_METHOD_TOKEN {
+		if authenticator, ok := connection.(plugin.AccessTokenAuthenticator); ok {
+			if accessToken, ok := authenticator.GetAccessTokenAuthenticator().(*AccessToken); ok {
+				for _, token := range splitCredentials(accessToken.Token) {
+					authorizations = append(authorizations, fmt.Sprintf("Bearer %v", token))
+				}
+			}
+		}
+	}
+	if len(authorizations) == 0 && (authMethod == "" || authMethod == plugin.AUTH_METHOD_BASIC) {
+		if authenticator, ok := connection.(plugin.BasicAuthenticator); ok {
+			if basicAuth, ok := authenticator.GetBasicAuthenticator().(*BasicAuth); ok {
+				usernames := splitCredentials(basicAuth.Username)
+				passwords := splitCredentials(basicAuth.Password)
+				if len(usernames) == len(passwords) {
+					for i := range usernames {
+						ba := &BasicAuth{Username: usernames[i], Password: passwords[i]}
+						authorizations = append(authorizations, fmt.Sprintf("Basic %v", ba.GetEncodedToken()))
+					}
+				}
+			}
+		}
+	}
+	if len(authorizations) < 2 {
+		return nil
+	}
+	return NewCredentialPool(authorizations)
+}
+
+func splitCredentials(value string) []string {
+	var credentials []string
+	for _, credential := range strings.Split(value, ",") {
+		if credential = strings.TrimSpace(credential); credential != "" {
+			credentials = append(credentials, credential)
+		}
+	}
+	return credentials
+}
+
+// Size returns the number of credentials in the pool
+func (p *CredentialPool) Size() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.credentials)
+}
+
+// matchScheme checks if the credentials share the authentication scheme of the given Authorization header
+func (p *CredentialPool) matchScheme(authorization string) bool {
+	scheme, _, _ := strings.Cut(authorization, " ")
+	for _, credential := range p.credentials {
+		if s, _, _ := strings.Cut(credential.authorization, " "); s != scheme {
+			return false
+		}
+	}
+	return scheme != ""
+}
+
+// pick returns the available credential with the most remaining quota, credentials never used or whose window
+// has reset come first. When all of them are parked, the one released the soonest is returned if `allowParked`
+// is set. Credentials listed in `excluded` are skipped, nil is returned if none is left
+func (p *CredentialPool) pick(excluded map[*pooledCredential]bool, allowParked bool) *pooledCredential {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var best, parked *pooledCredential
+	bestRemaining := -1
+	for _, credential := range p.credentials {
+		if excluded[credential] {
+			continue
+		}
+		if credential.parkedUntil.After(now) {
+			if parked == nil || credential.parkedUntil.Before(parked.parkedUntil) {
+				parked = credential
+			}
+			continue
+		}
+		remaining := credential.remaining
+		if remaining < 0 || (!credential.resetAt.IsZero() && !credential.resetAt.After(now)) {
+			remaining = math.MaxInt
+		}
+		if best == nil || remaining > bestRemaining {
+			best, bestRemaining = credential, remaining
+		}
+	}
+	if best == nil && allowParked {
+		best = parked
+	}
+	if best != nil && best.remaining > 0 {
+		// reserve the quota so concurrent requests spread over the credentials
+		best.remaining--
+	}
+	return best
+}
+
+// update records the quota reported by the response, and parks the credential if it was rejected or ran out of
+// quota. It returns true if the request should be retried with another credential. A 403 not caused by the rate
+// limit means the credential is not allowed to access the resource, which the other credentials are unlikely to be
+// either, so the response is returned as is
+func (p *CredentialPool) update(credential *pooledCredential, res *http.Response) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	quota := ParseRateLimitQuota(res, now)
+	if quota.Remaining >= 0 {
+		credential.remaining = quota.Remaining
+		credential.resetAt = quota.ResetAt
+	}
+	rateLimited := res.StatusCode == http.StatusForbidden && (quota.RetryAfter > 0 || quota.Remaining == 0)
+	if res.StatusCode != http.StatusUnauthorized && !rateLimited {
+		credential.parkedUntil = time.Time{}
+		return false
+	}
+	switch {
+	case quota.RetryAfter > 0:
+		credential.parkedUntil = now.Add(quota.RetryAfter)
+	case quota.Remaining == 0 && quota.ResetAt.After(now):
+		credential.parkedUntil = quota.ResetAt
+	default:
+		credential.parkedUntil = now.Add(CredentialParkDuration)
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPooledConn struct {
+	RestConnection `mapstructure:",squash"`
+	MultiAuth      `mapstructure:",squash"`
+	BasicAuth      `mapstructure:",squash"`
+	AccessToken    `mapstructure:",squash"`
+}
+
+func TestNewCredentialPoolFromConnection(t *testing.T) {
+	testCases := []struct {
+		name     string
+		conn     *testPooledConn
+		expected []string
+	}{
+		{
+			name: "single token",
+			conn: &testPooledConn{MultiAuth: MultiAuth{AuthMethod: "AccessToken"}, AccessToken: AccessToken{Token: "t1"}},
+		},
+		{
+			name:     "tokens",
+			conn:     &testPooledConn{MultiAuth: MultiAuth{AuthMethod: "AccessToken"}, AccessToken: AccessToken{Token: "t1, t2,"}},
+			expected: []string{"Bearer t1", "Bearer t2"},
+		},
+		{
+			name: "tokens of unselected method",
+			conn: &testPooledConn{MultiAuth: MultiAuth{AuthMethod: "BasicAuth"}, AccessToken: AccessToken{Token: "t1,t2"}},
+		},
+		{
+			name: "basic auth",
+			conn: &testPooledConn{
+				MultiAuth: MultiAuth{AuthMethod: "BasicAuth"},
+				BasicAuth: BasicAuth{Username: "u1,u2", Password: "p1,p2"},
+			},
+			expected: []string{"Basic dTE6cDE=", "Basic dTI6cDI="},
+		},
+		{
+			name: "basic auth mismatched",
+			conn: &testPooledConn{
+				MultiAuth: MultiAuth{AuthMethod: "BasicAuth"},
+				BasicAuth: BasicAuth{Username: "u1,u2", Password: "p1"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := NewCredentialPoolFromConnection(tc.conn)
+			var authorizations []string
+			if pool != nil {
+				for _, credential := range pool.credentials {
+					authorizations = append(authorizations, credential.authorization)
+				}
+			}
+			assert.Equal(t, tc.expected, authorizations)
+		})
+	}
+}
+
+func TestCredentialPoolPick(t *testing.T) {
+	now := time.Date(2024, 10, 19, 8, 0, 0, 0, time.UTC)
+	pool := NewCredentialPool([]string{"Bearer t1", "Bearer t2", "Bearer t3"})
+	pool.now = func() time.Time { return now }
+	t1, t2, t3 := pool.credentials[0], pool.credentials[1], pool.credentials[2]
+	t1.remaining, t1.resetAt = 100, now.Add(time.Hour)
+	t2.remaining, t2.resetAt = 300, now.Add(time.Hour)
+	t3.remaining, t3.resetAt = 200, now.Add(time.Hour)
+
+	assert.Equal(t, t2, pool.pick(nil, true))
+	assert.Equal(t, 299, t2.remaining)
+	assert.Equal(t, t3, pool.pick(map[*pooledCredential]bool{t2: true}, true))
+
+	// the window of t1 has reset
+	t1.resetAt = now.Add(-time.Second)
+	assert.Equal(t, t1, pool.pick(nil, true))
+
+	// rejected credentials are parked until the quota resets
+	res := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+	res.Header.Set("X-RateLimit-Remaining", "0")
+	res.Header.Set("X-RateLimit-Reset", "1729325400")
+	assert.True(t, pool.update(t1, res))
+	assert.Equal(t, time.Unix(1729325400, 0), t1.parkedUntil)
+	assert.True(t, pool.update(t2, &http.Response{StatusCode: http.StatusUnauthorized}))
+	assert.Equal(t, now.Add(CredentialParkDuration), t2.parkedUntil)
+	assert.Equal(t, t3, pool.pick(nil, true))
+
+	// all parked
+	assert.True(t, pool.update(t3, &http.Response{StatusCode: http.StatusUnauthorized}))
+	assert.Nil(t, pool.pick(nil, false))
+	assert.Equal(t, t1, pool.pick(nil, true))
+
+	// a successful response releases the credential
+	assert.False(t, pool.update(t2, &http.Response{StatusCode: http.StatusOK}))
+	assert.Equal(t, t2, pool.pick(nil, false))
+
+	// a 403 not caused by the rate limit is returned as is
+	res = &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+	res.Header.Set("X-RateLimit-Remaining", "42")
+	assert.False(t, pool.update(t2, res))
+	assert.True(t, t2.parkedUntil.IsZero())
+	assert.False(t, pool.update(t2, &http.Response{StatusCode: http.StatusForbidden}))
+	res = &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+	res.Header.Set("Retry-After", "60")
+	assert.True(t, pool.update(t2, res))
+	assert.Equal(t, now.Add(time.Minute), t2.parkedUntil)
+}
+
+func TestApiClientRetriesWithAnotherCredential(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "Bearer revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	apiClient := &ApiClient{}
+	apiClient.Setup(server.URL, nil, 0)
+	apiClient.SetCredentialPool(NewCredentialPool([]string{"Bearer revoked", "Bearer valid"}))
+	res, err := apiClient.Post("/", nil, map[string]string{"foo": "bar"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{"Bearer revoked", "Bearer valid"}, authorizations)
+
+	// the revoked credential is parked
+	_, err = apiClient.Get("/", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer valid", authorizations[2])
+}
+
+func TestApiClientReturnsPlainForbidden(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	apiClient := &ApiClient{}
+	apiClient.Setup(server.URL, nil, 0)
+	apiClient.SetCredentialPool(NewCredentialPool([]string{"Bearer t1", "Bearer t2"}))
+	res, err := apiClient.Get("/", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Len(t, authorizations, 1)
+
+	// no credential is parked
+	_, _ = apiClient.Get("/", nil, nil)
+	_, _ = apiClient.Get("/", nil, nil)
+	assert.Len(t, authorizations, 3)
+	assert.ElementsMatch(t, []string{"Bearer t1", "Bearer t2"}, authorizations[1:])
+}
@@ -23,11 +23,14 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/tracing"
 	"github.com/apache/incubator-devlake/core/utils"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/merico-dev/graphql"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // GraphqlAsyncClient send graphql one by one
@@ -164,7 +167,12 @@ func (apiClient *GraphqlAsyncClient) Query(q interface{}, variables map[string]i
 			return nil, nil
 		default:
 			var dataErrors []graphql.DataError
+			_, span := tracing.Start(apiClient.ctx, "graphql "+graphqlOperationOf(q),
+				attribute.String("graphql.operation.name", graphqlOperationOf(q)),
+			)
 			dataErrors, err := apiClient.client.Query(apiClient.ctx, q, variables)
+			span.SetAttributes(attribute.Int("graphql.data_errors", len(dataErrors)))
+			tracing.End(span, err)
 			if err == context.Canceled {
 				return nil, err
 			}
@@ -189,6 +197,18 @@ func (apiClient *GraphqlAsyncClient) Query(q interface{}, variables map[string]i
 	return nil, errors.Default.Wrap(err, fmt.Sprintf("got error when querying GraphQL (from the %dth retry)", retryTime))
 }
 
+// graphqlOperationOf returns the name of the query struct, which identifies the query as a url template would
+func graphqlOperationOf(q interface{}) string {
+	t := reflect.TypeOf(q)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Name() == "" {
+		return "query"
+	}
+	return t.Name()
+}
+
 // NextTick to return the NextTick of scheduler
 func (apiClient *GraphqlAsyncClient) NextTick(task func() errors.Error, taskErrorChecker func(err error)) {
 	// to make sure task will be enqueued
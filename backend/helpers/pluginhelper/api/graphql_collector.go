@@ -153,6 +153,10 @@ func (collector *GraphqlCollector) Execute() errors.Error {
 			return errors.Default.Wrap(err, "error deleting data from collector")
 		}
 	}
+	err = collector.recordCollection(db, isIncremental)
+	if err != nil {
+		return err
+	}
 
 	collector.args.Ctx.SetProgress(0, -1)
 	if collector.args.Input != nil {
@@ -319,8 +323,7 @@ func (collector *GraphqlCollector) ExtractExistRawData(divider *BatchSaveDivider
 		}
 		// get the type of query and variables. For each iteration, the query should be a different object
 		query, variables, _ := collector.args.BuildQuery(nil)
-		row := &RawData{}
-		err = db.Fetch(cursor, row)
+		row, err := fetchRawData(db, cursor)
 		if err != nil {
 			return errors.Default.Wrap(err, "error fetching row")
 		}
@@ -406,11 +409,10 @@ func (collector *GraphqlCollector) fetchAsync(divider *BatchSaveDivider, reqData
 		collector.checkError(errors.Default.Wrap(err, `variables in graphql query can not marshal to json`))
 		return
 	}
-	row := &RawData{
-		Params: collector.params,
-		Data:   paramsBytes,
-		Url:    queryStr,
-		Input:  variablesJson,
+	row, err := collector.newRawData(paramsBytes, queryStr, variablesJson)
+	if err != nil {
+		collector.checkError(errors.Default.Wrap(err, `compress row in graphql collector failed`))
+		return
 	}
 	err = db.Create(row, dal.From(collector.table))
 	if err != nil {
@@ -114,8 +114,8 @@ func NewSubtaskStateManager(args *SubtaskCommonArgs) (stateManager *SubtaskState
 	if stateManager.since == nil {
 		stateManager.since = state.TimeAfter
 	}
-	// if fullsync is set or no previous success start time, we are in the full sync mode
-	if syncPolicy.FullSync || state.PrevStartedAt == nil {
+	// if fullsync or reprocess is set or no previous success start time, we are in the full sync mode
+	if syncPolicy.FullSync || syncPolicy.Reprocess || state.PrevStartedAt == nil {
 		return
 	}
 	// if timeAfter is not set or NOT before the previous vaule, we are in the incremental mode
@@ -0,0 +1,156 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+)
+
+// TeamResolver tells which team an account or a user belonged to at a given time, according to the
+// team_memberships, so that work can be attributed to the team of its author at the time it was done
+type TeamResolver struct {
+	accountUsers map[string]string
+	emailUsers   map[string]string
+	memberships  map[string][]crossdomain.TeamMembership
+	parents      map[string]string
+}
+
+// NewTeamResolver loads the user accounts, the emails of users and accounts, team memberships and team hierarchy
+// into memory
+func NewTeamResolver(db dal.Dal) (*TeamResolver, errors.Error) {
+	var userAccounts []crossdomain.UserAccount
+	err := db.All(&userAccounts)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading user_accounts")
+	}
+	var users []crossdomain.User
+	err = db.All(&users, dal.Select("id, email"), dal.Where("email != ''"))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading users")
+	}
+	var accounts []crossdomain.Account
+	err = db.All(&accounts, dal.Select("id, email"), dal.Where("email != ''"))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading accounts")
+	}
+	var memberships []crossdomain.TeamMembership
+	err = db.All(&memberships)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading team_memberships")
+	}
+	var teams []crossdomain.Team
+	err = db.All(&teams, dal.Select("id, parent_id"))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading teams")
+	}
+	return newTeamResolver(userAccounts, users, accounts, memberships, teams), nil
+}
+
+func newTeamResolver(
+	userAccounts []crossdomain.UserAccount,
+	users []crossdomain.User,
+	accounts []crossdomain.Account,
+	memberships []crossdomain.TeamMembership,
+	teams []crossdomain.Team,
+) *TeamResolver {
+	resolver := &TeamResolver{
+		accountUsers: make(map[string]string, len(userAccounts)),
+		emailUsers:   make(map[string]string, len(users)),
+		memberships:  make(map[string][]crossdomain.TeamMembership),
+		parents:      make(map[string]string, len(teams)),
+	}
+	for _, userAccount := range userAccounts {
+		resolver.accountUsers[userAccount.AccountId] = userAccount.UserId
+	}
+	// the email of a user wins over the ones of the accounts of other users
+	for _, account := range accounts {
+		if userId, ok := resolver.accountUsers[account.Id]; ok {
+			resolver.emailUsers[strings.ToLower(account.Email)] = userId
+		}
+	}
+	for _, user := range users {
+		resolver.emailUsers[strings.ToLower(user.Email)] = user.Id
+	}
+	for _, membership := range memberships {
+		resolver.memberships[membership.UserId] = append(resolver.memberships[membership.UserId], membership)
+	}
+	// the latest started membership comes first, the one with the smallest team id among equals
+	for _, userMemberships := range resolver.memberships {
+		sort.Slice(userMemberships, func(i, j int) bool {
+			a, b := userMemberships[i], userMemberships[j]
+			if (a.StartDate == nil) != (b.StartDate == nil) {
+				return b.StartDate == nil
+			}
+			if a.StartDate != nil && !a.StartDate.Equal(*b.StartDate) {
+				return a.StartDate.After(*b.StartDate)
+			}
+			return a.TeamId < b.TeamId
+		})
+	}
+	for _, team := range teams {
+		resolver.parents[team.Id] = team.ParentId
+	}
+	return resolver
+}
+
+// UserTeamAt returns the team the user belonged to at the given time, the one joined last when the user was
+// in several teams, or an empty string
+func (r *TeamResolver) UserTeamAt(userId string, at time.Time) string {
+	for _, membership := range r.memberships[userId] {
+		if membership.IsEffectiveAt(at) {
+			return membership.TeamId
+		}
+	}
+	return ""
+}
+
+// AccountTeamAt returns the team the user of the account belonged to at the given time, or an empty string
+func (r *TeamResolver) AccountTeamAt(accountId string, at time.Time) string {
+	userId, ok := r.accountUsers[accountId]
+	if !ok {
+		return ""
+	}
+	return r.UserTeamAt(userId, at)
+}
+
+// EmailTeamAt returns the team the user with the email, or with an account having the email, belonged to at the
+// given time, or an empty string. Commits only know the email of their author.
+func (r *TeamResolver) EmailTeamAt(email string, at time.Time) string {
+	userId, ok := r.emailUsers[strings.ToLower(email)]
+	if !ok {
+		return ""
+	}
+	return r.UserTeamAt(userId, at)
+}
+
+// TeamAncestors returns the parent team, grand parent team and so on of the team
+func (r *TeamResolver) TeamAncestors(teamId string) []string {
+	var ancestors []string
+	visited := map[string]bool{teamId: true}
+	for parent := r.parents[teamId]; parent != "" && !visited[parent]; parent = r.parents[parent] {
+		visited[parent] = true
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
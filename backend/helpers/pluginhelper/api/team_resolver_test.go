@@ -0,0 +1,73 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamResolver(t *testing.T) {
+	date := func(value string) *time.Time {
+		d, err := time.Parse("2006-01-02", value)
+		assert.Nil(t, err)
+		return &d
+	}
+	team := func(id, parentId string) crossdomain.Team {
+		return crossdomain.Team{DomainEntity: domainlayer.DomainEntity{Id: id}, ParentId: parentId}
+	}
+	resolver := newTeamResolver(
+		[]crossdomain.UserAccount{{UserId: "u1", AccountId: "github:1"}, {UserId: "u2", AccountId: "gitlab:2"}},
+		[]crossdomain.User{{DomainEntity: domainlayer.DomainEntity{Id: "u1"}, Email: "alice@example.com"}},
+		[]crossdomain.Account{
+			{DomainEntity: domainlayer.DomainEntity{Id: "github:1"}, Email: "alice@users.example.com"},
+			{DomainEntity: domainlayer.DomainEntity{Id: "gitlab:2"}, Email: "Alice@example.com"},
+			{DomainEntity: domainlayer.DomainEntity{Id: "gitlab:3"}, Email: "nobody@example.com"},
+		},
+		[]crossdomain.TeamMembership{
+			{TeamId: "platform", UserId: "u1", EndDate: date("2024-06-01")},
+			{TeamId: "payments", UserId: "u1", StartDate: date("2024-06-01")},
+			// an overlapping membership, joined later
+			{TeamId: "guild", UserId: "u1", StartDate: date("2024-09-01"), EndDate: date("2024-10-01")},
+		},
+		[]crossdomain.Team{team("payments", "product"), team("product", "company"), team("company", ""), team("loop", "loop")},
+	)
+
+	assert.Equal(t, "platform", resolver.AccountTeamAt("github:1", *date("2024-01-15")))
+	assert.Equal(t, "payments", resolver.AccountTeamAt("github:1", *date("2024-06-01")))
+	assert.Equal(t, "guild", resolver.UserTeamAt("u1", *date("2024-09-15")))
+	assert.Equal(t, "payments", resolver.UserTeamAt("u1", *date("2024-10-01")))
+	assert.Equal(t, "", resolver.AccountTeamAt("github:2", *date("2024-10-01")))
+	assert.Equal(t, "", resolver.UserTeamAt("u2", *date("2024-10-01")))
+	assert.Equal(t, "payments", resolver.EmailTeamAt("alice@example.com", *date("2024-10-01")))
+	assert.Equal(t, "payments", resolver.EmailTeamAt("alice@users.example.com", *date("2024-10-01")))
+	assert.Equal(t, "", resolver.EmailTeamAt("nobody@example.com", *date("2024-10-01")))
+
+	assert.Equal(t, []string{"product", "company"}, resolver.TeamAncestors("payments"))
+	assert.Nil(t, resolver.TeamAncestors("loop"))
+
+	a := crossdomain.TeamMembership{StartDate: date("2024-01-01"), EndDate: date("2024-06-01")}
+	assert.True(t, a.Overlaps(crossdomain.TeamMembership{StartDate: date("2024-05-01")}))
+	assert.False(t, a.Overlaps(crossdomain.TeamMembership{StartDate: date("2024-06-01")}))
+	assert.False(t, a.Overlaps(crossdomain.TeamMembership{EndDate: date("2024-01-01")}))
+	assert.True(t, a.Overlaps(crossdomain.TeamMembership{}))
+}
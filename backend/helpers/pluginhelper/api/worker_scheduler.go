@@ -146,6 +146,8 @@ func (s *WorkerScheduler) checkError(err interface{}) {
 
 // HasError return if any error occurred
 func (s *WorkerScheduler) HasError() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return len(s.workerErrors) > 0
 }
 
@@ -161,28 +163,41 @@ func (s *WorkerScheduler) NextTick(task func() errors.Error) {
 	}()
 }
 
-// Wait blocks current go-routine until all workers returned
+// WaitAsync blocks current go-routine until all workers returned, the errors gathered so far are cleared so the
+// scheduler can be reused, i.e. when the subtask is retried
 func (s *WorkerScheduler) WaitAsync() errors.Error {
 	s.waitGroup.Wait()
-	if len(s.workerErrors) > 0 {
-		for _, err := range s.workerErrors {
-			if errors.Is(err, context.Canceled) {
-				return errors.Default.Wrap(err, "task canceled")
-			}
+	s.mu.Lock()
+	workerErrors := s.workerErrors
+	s.workerErrors = nil
+	s.mu.Unlock()
+	if len(workerErrors) == 0 {
+		return nil
+	}
+	for _, err := range workerErrors {
+		if errors.Is(err, context.Canceled) {
+			return errors.Default.Wrap(err, "task canceled")
 		}
-		return errors.Default.Combine(s.workerErrors)
 	}
-	return nil
+	if len(workerErrors) == 1 {
+		return errors.Default.WrapRaw(workerErrors[0])
+	}
+	// keep the first error as the cause so its type, i.e. the http status of a failed request, is not lost
+	return errors.Default.Wrap(workerErrors[0], errors.Default.Combine(workerErrors[1:]).Error())
 }
 
 // Reset stops a WorkScheduler and resets its period to the specified duration.
 func (s *WorkerScheduler) Reset(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.tickInterval = interval
 	s.ticker.Reset(interval)
 }
 
 // GetTickInterval returns current tick interval of the WorkScheduler
 func (s *WorkerScheduler) GetTickInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.tickInterval
 }
 
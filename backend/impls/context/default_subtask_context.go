@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/metrics"
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
 )
@@ -44,6 +45,9 @@ func (c *DefaultSubTaskContext) SetProgress(current int, total int) {
 // IncProgress FIXME ...
 func (c *DefaultSubTaskContext) IncProgress(quantity int) {
 	c.defaultExecContext.IncProgress(plugin.SubTaskIncProgress, quantity)
+	if c.taskCtx != nil {
+		metrics.AddSubtaskRecords(c.taskCtx.GetName(), c.name, quantity)
+	}
 	if c.LastProgressTime.IsZero() || c.LastProgressTime.Add(3*time.Second).Before(time.Now()) || c.current%1000 == 0 {
 		c.LastProgressTime = time.Now()
 		c.BasicRes.GetLogger().Info("finished records: %d", c.current)
@@ -26,6 +26,7 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/impls/logruslog"
 )
 
 // DefaultTaskContext is TaskContext default implementation
@@ -64,8 +65,12 @@ func (c *DefaultTaskContext) SubTaskContext(subtask string) (plugin.SubTaskConte
 			// now, create a subtask context if it didn't exist
 			c.defaultExecContext.mu.Lock()
 			if c.subtaskCtxs[subtask] == nil {
+				execCtx := c.defaultExecContext.fork(subtask)
+				execCtx.BasicRes = execCtx.BasicRes.ReplaceLogger(logruslog.WithFields(execCtx.GetLogger(), map[string]interface{}{
+					logruslog.FieldSubtask: subtask,
+				}))
 				c.subtaskCtxs[subtask] = &DefaultSubTaskContext{
-					c.defaultExecContext.fork(subtask),
+					execCtx,
 					c,
 					time.Time{},
 				}
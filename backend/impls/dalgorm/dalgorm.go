@@ -288,6 +288,12 @@ func (d *Dalgorm) UpdateColumn(entityOrTable interface{}, columnName string, val
 
 // UpdateColumns allows you to update multiple columns of mulitple records
 func (d *Dalgorm) UpdateColumns(entityOrTable interface{}, set []dal.DalSet, clauses ...dal.Clause) errors.Error {
+	_, err := d.UpdateColumnsAffected(entityOrTable, set, clauses...)
+	return err
+}
+
+// UpdateColumnsAffected updates multiple columns of multiple records and returns the number of records updated
+func (d *Dalgorm) UpdateColumnsAffected(entityOrTable interface{}, set []dal.DalSet, clauses ...dal.Clause) (int64, errors.Error) {
 	d.unwrapDynamic(&entityOrTable, &clauses)
 	updatesSet := make(map[string]interface{})
 
@@ -299,7 +305,8 @@ func (d *Dalgorm) UpdateColumns(entityOrTable interface{}, set []dal.DalSet, cla
 	}
 
 	clauses = append(clauses, dal.From(entityOrTable))
-	return d.convertGormError(buildTx(d.db, clauses).Updates(updatesSet).Error)
+	result := buildTx(d.db, clauses).Updates(updatesSet)
+	return result.RowsAffected, d.convertGormError(result.Error)
 }
 
 // UpdateAllColumn updated all Columns of entity
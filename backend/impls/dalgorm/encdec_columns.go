@@ -0,0 +1,59 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dalgorm
+
+import (
+	"sync"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"gorm.io/gorm/schema"
+)
+
+// EncDecTable describes the columns of a table encrypted by the encdec serializer
+type EncDecTable struct {
+	Name        string
+	PrimaryKeys []string
+	Columns     []string
+}
+
+// GetEncDecTable returns the `serializer:encdec` columns of the `tabler`, nil if there is none
+func GetEncDecTable(tabler dal.Tabler) (*EncDecTable, errors.Error) {
+	var model interface{} = tabler
+	if dynamic, ok := tabler.(models.DynamicTabler); ok {
+		model = dynamic.New().Unwrap()
+	}
+	s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to parse the schema of "+tabler.TableName())
+	}
+	table := &EncDecTable{
+		Name:        tabler.TableName(),
+		PrimaryKeys: s.PrimaryFieldDBNames,
+	}
+	for _, field := range s.Fields {
+		if field.DBName != "" && field.TagSettings["SERIALIZER"] == "encdec" {
+			table.Columns = append(table.Columns, field.DBName)
+		}
+	}
+	if len(table.Columns) == 0 || len(table.PrimaryKeys) == 0 {
+		return nil, nil
+	}
+	return table, nil
+}
@@ -0,0 +1,64 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dalgorm
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type EncDecTestAuth struct {
+	Token string `gorm:"serializer:encdec"`
+}
+
+type encDecTestConnection struct {
+	common.Model
+	Name string
+	EncDecTestAuth
+	Secret string `gorm:"column:app_secret;serializer:encdec"`
+}
+
+func (encDecTestConnection) TableName() string {
+	return "_tool_test_connections"
+}
+
+type encDecTestPlain struct {
+	common.Model
+	Name string
+}
+
+func (encDecTestPlain) TableName() string {
+	return "_tool_test_plains"
+}
+
+func TestGetEncDecTable(t *testing.T) {
+	Init("secret")
+	table, err := GetEncDecTable(&encDecTestConnection{})
+	assert.Nil(t, err)
+	assert.Equal(t, &EncDecTable{
+		Name:        "_tool_test_connections",
+		PrimaryKeys: []string{"id"},
+		Columns:     []string{"token", "app_secret"},
+	}, table)
+
+	table, err = GetEncDecTable(&encDecTestPlain{})
+	assert.Nil(t, err)
+	assert.Nil(t, table)
+}
@@ -34,7 +34,7 @@ var _ schema.SerializerInterface = (*EncDecSerializer)(nil)
 // EncDecSerializer is responsible for field encryption/decryption in Application Level
 // Ref: https://gorm.io/docs/serializer.html
 type EncDecSerializer struct {
-	encryptionSecret string
+	keyRing *plugin.EncryptionKeyRing
 }
 
 // Scan implements serializer interface
@@ -52,7 +52,7 @@ func (es *EncDecSerializer) Scan(ctx context.Context, field *schema.Field, dst r
 			return fmt.Errorf("failed to decrypt value: %#v", dbValue)
 		}
 
-		decrypted, err := plugin.Decrypt(es.encryptionSecret, base64str)
+		decrypted, err := es.keyRing.Decrypt(base64str)
 		if err != nil {
 			return err
 		}
@@ -91,10 +91,20 @@ func (es *EncDecSerializer) Value(ctx context.Context, field *schema.Field, dst
 		}
 		target = string(b)
 	}
-	return plugin.Encrypt(es.encryptionSecret, target)
+	return es.keyRing.Encrypt(target)
 }
 
-// Init the encdec serializer
+// Init the encdec serializer with a key ring only containing the `encryptionSecret`
 func Init(encryptionSecret string) {
-	schema.RegisterSerializer("encdec", &EncDecSerializer{encryptionSecret: encryptionSecret})
+	keyRing, err := plugin.NewEncryptionKeyRing("", encryptionSecret, "")
+	if err != nil {
+		panic(err)
+	}
+	InitWithKeyRing(keyRing)
+}
+
+// InitWithKeyRing the encdec serializer, values are encrypted by the current key of the `keyRing` and
+// decrypted by the key they were tagged with
+func InitWithKeyRing(keyRing *plugin.EncryptionKeyRing) {
+	schema.RegisterSerializer("encdec", &EncDecSerializer{keyRing: keyRing})
 }
@@ -50,7 +50,8 @@ func init() {
 
 	var formatter logrus.Formatter
 
-	format := os.Getenv("LOGGING_FORMAT")
+	// json writes every entry as a JSON object, with the pipeline, task, subtask and plugin fields of the loggers
+	format := strings.ToLower(cfg.GetString("LOGGING_FORMAT"))
 
 	switch format {
 	case "json":
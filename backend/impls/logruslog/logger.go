@@ -18,6 +18,7 @@ limitations under the License.
 package logruslog
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -25,13 +26,24 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var alreadyInBracketsRegex = regexp.MustCompile(`\[.*?]+`)
 
+// the fields identifying what the entries of the pipeline, task and subtask loggers are about
+const (
+	FieldPipelineId = "pipeline_id"
+	FieldTaskId     = "task_id"
+	FieldPlugin     = "plugin"
+	FieldSubtask    = "subtask"
+)
+
 type DefaultLogger struct {
 	log    *logrus.Logger
 	config *log.LoggerConfig
+	// fields are attached to every entry, i.e. the trace context
+	fields logrus.Fields
 }
 
 func NewDefaultLogger(logger *logrus.Logger) (log.Logger, errors.Error) {
@@ -55,7 +67,11 @@ func (l *DefaultLogger) Log(level log.LogLevel, format string, a ...interface{})
 		if l.config.Prefix != "" {
 			msg = fmt.Sprintf("%s %s", l.config.Prefix, msg)
 		}
-		l.log.Log(logrus.Level(level), msg)
+		if len(l.fields) > 0 {
+			l.log.WithFields(l.fields).Log(logrus.Level(level), msg)
+		} else {
+			l.log.Log(logrus.Level(level), msg)
+		}
 	}
 }
 
@@ -119,6 +135,7 @@ func (l *DefaultLogger) getLogger(prefix string) (log.Logger, errors.Error) {
 			Path:   l.config.Path,
 			Prefix: prefix,
 		},
+		fields: l.fields,
 	}
 	return newLogger, nil
 }
@@ -132,6 +149,40 @@ func (l *DefaultLogger) createPrefix(newPrefix string) string {
 	return fmt.Sprintf("%s [%s]", l.config.Prefix, newPrefix)
 }
 
+// WithFields returns a logger attaching the fields to every entry on top of the ones of the logger, they are
+// inherited by the nested loggers. Loggers other than DefaultLogger are returned as is.
+func WithFields(logger log.Logger, fields map[string]interface{}) log.Logger {
+	defaultLogger, ok := logger.(*DefaultLogger)
+	if !ok || len(fields) == 0 {
+		return logger
+	}
+	merged := make(logrus.Fields, len(defaultLogger.fields)+len(fields))
+	for k, v := range defaultLogger.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &DefaultLogger{
+		log:    defaultLogger.log,
+		config: defaultLogger.config,
+		fields: merged,
+	}
+}
+
+// WithTraceContext returns a logger attaching the trace and span ids of the span in ctx to every entry, the logger
+// itself is returned if ctx is not traced
+func WithTraceContext(logger log.Logger, ctx context.Context) log.Logger {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return logger
+	}
+	return WithFields(logger, map[string]interface{}{
+		"trace_id": spanCtx.TraceID().String(),
+		"span_id":  spanCtx.SpanID().String(),
+	})
+}
+
 func formatMessage(err error, msg string, args ...interface{}) string {
 	msg = fmt.Sprintf(msg, args...)
 	if err == nil {
@@ -86,6 +86,7 @@ func (p Azuredevops) GetTablesInfo() []dal.Tabler {
 		&models.AzuredevopsConnection{},
 		&models.AzuredevopsPrCommit{},
 		&models.AzuredevopsPrLabel{},
+		&models.AzuredevopsPrReviewer{},
 		&models.AzuredevopsProject{},
 		&models.AzuredevopsPullRequest{},
 		&models.AzuredevopsRepo{},
@@ -55,6 +55,13 @@ type AzuredevopsApiPullRequest struct {
 		Name   string `json:"name"`
 		Active bool   `json:"active"`
 	} `json:"labels"`
+	Reviewers []struct {
+		Id          string `json:"id"`
+		DisplayName string `json:"displayName"`
+		UniqueName  string `json:"uniqueName"`
+		Vote        int    `json:"vote"`
+		IsRequired  bool   `json:"isRequired"`
+	} `json:"reviewers"`
 	Repository struct {
 		Id      string `json:"id"`
 		Name    string `json:"name"`
@@ -40,6 +40,7 @@ var ExtractApiPullRequestsMeta = plugin.SubTaskMeta{
 	ProductTables: []string{
 		models.AzuredevopsPullRequest{}.TableName(),
 		models.AzuredevopsPrLabel{}.TableName(),
+		models.AzuredevopsPrReviewer{}.TableName(),
 	},
 }
 
@@ -69,6 +70,17 @@ func ExtractApiPullRequests(taskCtx plugin.SubTaskContext) errors.Error {
 					LabelName:     label.Name,
 				})
 			}
+			for _, reviewer := range rawL.Reviewers {
+				results = append(results, &models.AzuredevopsPrReviewer{
+					ConnectionId:  data.Options.ConnectionId,
+					PullRequestId: adoApiPr.AzuredevopsId,
+					ReviewerId:    reviewer.Id,
+					DisplayName:   reviewer.DisplayName,
+					UniqueName:    reviewer.UniqueName,
+					Vote:          reviewer.Vote,
+					IsRequired:    reviewer.IsRequired,
+				})
+			}
 
 			results = append(results, adoApiPr)
 
@@ -0,0 +1,136 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/azuredevops_go/models"
+)
+
+func init() {
+	RegisterSubtaskMeta(&ConvertPrReviewersMeta)
+}
+
+var ConvertPrReviewersMeta = plugin.SubTaskMeta{
+	Name:             "convertPrReviewers",
+	EntryPoint:       ConvertPrReviewers,
+	EnabledByDefault: true,
+	Description:      "Convert tool layer table azuredevops_go_pull_request_reviewers into domain layer tables pull_request_reviewers and pull_request_comments",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE_REVIEW},
+	DependencyTables: []string{
+		models.AzuredevopsPrReviewer{}.TableName(),
+		models.AzuredevopsPullRequest{}.TableName(),
+	},
+	ProductTables: []string{
+		code.PullRequestReviewer{}.TableName(),
+		code.PullRequestComment{}.TableName(),
+	},
+}
+
+type azuredevopsPrReviewerVote struct {
+	models.AzuredevopsPrReviewer
+	ClosedDate *time.Time
+}
+
+// ConvertPrReviewers turns the votes of reviewers into reviews so approvals count like the ones of the
+// other plugins. Azure DevOps does not tell when a vote was cast, reviews are dated by the closing of the
+// pull request and only the votes of closed pull requests are converted.
+func ConvertPrReviewers(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	rawDataSubTaskArgs, data := CreateRawDataSubTaskArgs(taskCtx, RawPullRequestTable)
+	repoId := data.Options.RepositoryId
+	clauses := []dal.Clause{
+		dal.Select("_tool_azuredevops_go_pull_request_reviewers.*, _tool_azuredevops_go_pull_requests.closed_date"),
+		dal.From(&models.AzuredevopsPrReviewer{}),
+		dal.Join(`left join _tool_azuredevops_go_pull_requests on
+			_tool_azuredevops_go_pull_requests.azuredevops_id = _tool_azuredevops_go_pull_request_reviewers.pull_request_id
+			and _tool_azuredevops_go_pull_requests.connection_id = _tool_azuredevops_go_pull_request_reviewers.connection_id`),
+		dal.Where(`_tool_azuredevops_go_pull_requests.repository_id = ?
+			and _tool_azuredevops_go_pull_requests.connection_id = ?`,
+			repoId, data.Options.ConnectionId),
+		dal.Orderby("pull_request_id ASC"),
+	}
+
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	prIdGen := didgen.NewDomainIdGenerator(&models.AzuredevopsPullRequest{})
+	reviewIdGen := didgen.NewDomainIdGenerator(&models.AzuredevopsPrReviewer{})
+	accountIdGen := didgen.NewDomainIdGenerator(&models.AzuredevopsUser{})
+
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		RawDataSubTaskArgs: *rawDataSubTaskArgs,
+		InputRowType:       reflect.TypeOf(azuredevopsPrReviewerVote{}),
+		Input:              cursor,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			prReviewer := inputRow.(*azuredevopsPrReviewerVote)
+			pullRequestId := prIdGen.Generate(data.Options.ConnectionId, prReviewer.PullRequestId)
+			reviewerId := accountIdGen.Generate(data.Options.ConnectionId, prReviewer.ReviewerId)
+			results := []interface{}{
+				&code.PullRequestReviewer{
+					PullRequestId: pullRequestId,
+					ReviewerId:    reviewerId,
+					Name:          prReviewer.DisplayName,
+					UserName:      prReviewer.UniqueName,
+				},
+			}
+			status := getReviewStatus(prReviewer.Vote)
+			if status != "" && prReviewer.ClosedDate != nil {
+				results = append(results, &code.PullRequestComment{
+					DomainEntity: domainlayer.DomainEntity{
+						Id: reviewIdGen.Generate(data.Options.ConnectionId, prReviewer.PullRequestId, prReviewer.ReviewerId),
+					},
+					PullRequestId: pullRequestId,
+					AccountId:     reviewerId,
+					CreatedDate:   *prReviewer.ClosedDate,
+					Type:          code.REVIEW,
+					Status:        status,
+				})
+			}
+			return results, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
+
+// getReviewStatus maps the vote of a reviewer to the review states used by the other plugins
+func getReviewStatus(vote int) string {
+	switch {
+	case vote > 0:
+		return "APPROVED"
+	case vote < 0:
+		return "CHANGES_REQUESTED"
+	}
+	return ""
+}
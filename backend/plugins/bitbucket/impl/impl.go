@@ -80,6 +80,7 @@ func (p Bitbucket) GetTablesInfo() []dal.Tabler {
 		&models.BitbucketDeployment{},
 		&models.BitbucketPipelineStep{},
 		&models.BitbucketPrCommit{},
+		&models.BitbucketPrParticipant{},
 		&models.BitbucketScopeConfig{},
 	}
 }
@@ -126,6 +127,7 @@ func (p Bitbucket) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.ConvertAccountsMeta,
 		tasks.ConvertPullRequestsMeta,
 		tasks.ConvertPrCommentsMeta,
+		tasks.ConvertPrParticipantsMeta,
 		tasks.ConvertPrCommitsMeta,
 		tasks.ConvertCommitsMeta,
 		tasks.ConvertIssuesMeta,
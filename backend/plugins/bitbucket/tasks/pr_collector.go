@@ -52,6 +52,7 @@ func CollectApiPullRequests(taskCtx plugin.SubTaskContext) errors.Error {
 				`values.merge_commit.hash,values.merge_commit.date,values.links.html,values.author,values.created_on,values.updated_on,`+
 				`values.destination.branch.name,values.destination.commit.hash,values.destination.repository.full_name,`+
 				`values.source.branch.name,values.source.commit.hash,values.source.repository.full_name,`+
+				`values.participants.user,values.participants.role,values.participants.approved,values.participants.state,values.participants.participated_on,`+
 				`page,pagelen,size`,
 			collectorWithState),
 		GetTotalPages:  GetTotalPagesFromResponse,
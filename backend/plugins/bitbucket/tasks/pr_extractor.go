@@ -78,7 +78,13 @@ type BitbucketApiPullRequest struct {
 		Repo *models.BitbucketApiRepo `json:"repository"`
 	} `json:"source"`
 	//Reviewers    []BitbucketAccountResponse `json:"reviewers"`
-	//Participants []BitbucketAccountResponse `json:"participants"`
+	Participants []struct {
+		User           *BitbucketAccountResponse `json:"user"`
+		Role           string                    `json:"role"`
+		Approved       bool                      `json:"approved"`
+		State          string                    `json:"state"`
+		ParticipatedOn *common.Iso8601Time       `json:"participated_on"`
+	} `json:"participants"`
 }
 
 func ExtractApiPullRequests(taskCtx plugin.SubTaskContext) errors.Error {
@@ -115,6 +121,26 @@ func ExtractApiPullRequests(taskCtx plugin.SubTaskContext) errors.Error {
 				bitbucketPr.MergeCommitSha = rawL.MergeCommit.Hash
 				bitbucketPr.MergedAt = rawL.MergeCommit.Date.ToNullableTime()
 			}
+			for _, participant := range rawL.Participants {
+				if participant.User == nil {
+					continue
+				}
+				bitbucketUser, err := convertAccount(participant.User, data.Options.ConnectionId)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, bitbucketUser, &models.BitbucketPrParticipant{
+					ConnectionId:   data.Options.ConnectionId,
+					RepoId:         data.Options.FullName,
+					PullRequestId:  rawL.BitbucketId,
+					AccountId:      bitbucketUser.AccountId,
+					DisplayName:    bitbucketUser.DisplayName,
+					Role:           participant.Role,
+					Approved:       participant.Approved,
+					State:          participant.State,
+					ParticipatedOn: participant.ParticipatedOn.ToNullableTime(),
+				})
+			}
 			results = append(results, bitbucketPr)
 
 			return results, nil
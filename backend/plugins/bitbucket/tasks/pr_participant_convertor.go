@@ -0,0 +1,109 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	plugin "github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/bitbucket/models"
+)
+
+var ConvertPrParticipantsMeta = plugin.SubTaskMeta{
+	Name:             "Convert PR Participants",
+	EntryPoint:       ConvertPullRequestParticipants,
+	EnabledByDefault: true,
+	Description:      "Convert the reviewers and the reviews of participants of Bitbucket pull requests",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE_REVIEW},
+}
+
+// ConvertPullRequestParticipants turns the approvals and change requests of participants into reviews so
+// they count like the ones of the other plugins
+func ConvertPullRequestParticipants(taskCtx plugin.SubTaskContext) errors.Error {
+	rawDataSubTaskArgs, data := CreateRawDataSubTaskArgs(taskCtx, RAW_PULL_REQUEST_TABLE)
+	db := taskCtx.GetDal()
+
+	cursor, err := db.Cursor(
+		dal.From(&models.BitbucketPrParticipant{}),
+		dal.Where("connection_id = ? AND repo_id = ?", data.Options.ConnectionId, data.Options.FullName),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	reviewIdGen := didgen.NewDomainIdGenerator(&models.BitbucketPrParticipant{})
+	prIdGen := didgen.NewDomainIdGenerator(&models.BitbucketPullRequest{})
+	accountIdGen := didgen.NewDomainIdGenerator(&models.BitbucketAccount{})
+
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		InputRowType:       reflect.TypeOf(models.BitbucketPrParticipant{}),
+		Input:              cursor,
+		RawDataSubTaskArgs: *rawDataSubTaskArgs,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			participant := inputRow.(*models.BitbucketPrParticipant)
+			pullRequestId := prIdGen.Generate(participant.ConnectionId, participant.RepoId, participant.PullRequestId)
+			accountId := accountIdGen.Generate(participant.ConnectionId, participant.AccountId)
+			var results []interface{}
+			if participant.Role == "REVIEWER" {
+				results = append(results, &code.PullRequestReviewer{
+					PullRequestId: pullRequestId,
+					ReviewerId:    accountId,
+					Name:          participant.DisplayName,
+				})
+			}
+			status := getParticipantReviewStatus(participant)
+			if status != "" && participant.ParticipatedOn != nil {
+				results = append(results, &code.PullRequestComment{
+					DomainEntity: domainlayer.DomainEntity{
+						Id: reviewIdGen.Generate(participant.ConnectionId, participant.RepoId, participant.PullRequestId, participant.AccountId),
+					},
+					PullRequestId: pullRequestId,
+					AccountId:     accountId,
+					CreatedDate:   *participant.ParticipatedOn,
+					Type:          code.REVIEW,
+					Status:        status,
+				})
+			}
+			return results, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
+
+// getParticipantReviewStatus maps the state of a participant to the review states used by the other plugins
+func getParticipantReviewStatus(participant *models.BitbucketPrParticipant) string {
+	if participant.State != "" {
+		return strings.ToUpper(participant.State)
+	}
+	if participant.Approved {
+		return "APPROVED"
+	}
+	return ""
+}
@@ -33,6 +33,7 @@ type BitbucketServerPrComment struct {
 	CreatedAt     time.Time
 	UpdatedAt     *time.Time
 	Body          string
+	Status        string `gorm:"type:varchar(100)"` // APPROVED for approvals, empty for comments
 	common.NoPKModel
 }
 
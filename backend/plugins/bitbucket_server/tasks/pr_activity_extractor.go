@@ -89,7 +89,10 @@ func ExtractApiPullRequestActivities(taskCtx plugin.SubTaskContext) errors.Error
 				return nil, err
 			}
 
-			if prActivity.Action == "COMMENTED" && prActivity.Comment != nil {
+			// approvals are kept as comments without a body so they reach pull_request_comments like reviews
+			// of the other plugins do
+			isComment := prActivity.Action == "COMMENTED" && prActivity.Comment != nil
+			if isComment || prActivity.Action == "APPROVED" {
 				toolprComment, err := convertPullRequestComment(prActivity)
 				if err != nil {
 					return nil, err
@@ -146,10 +149,16 @@ func ExtractApiPullRequestActivities(taskCtx plugin.SubTaskContext) errors.Error
 func convertPullRequestComment(prActivity *ApiPrActivityResponse) (*models.BitbucketServerPrComment, errors.Error) {
 	bitbucketPrComment := &models.BitbucketServerPrComment{
 		BitbucketId: prActivity.BitbucketId,
-		AuthorName:  prActivity.User.DisplayName,
 		CreatedAt:   time.UnixMilli(prActivity.CreatedOn),
-		Body:        prActivity.Comment.Text,
 	}
+	if prActivity.User != nil {
+		bitbucketPrComment.AuthorName = prActivity.User.DisplayName
+	}
+	if prActivity.Comment == nil {
+		bitbucketPrComment.Status = prActivity.Action
+		return bitbucketPrComment, nil
+	}
+	bitbucketPrComment.Body = prActivity.Comment.Text
 	if prActivity.Comment.UpdatedAt != nil {
 		updatedAt := time.UnixMilli(*prActivity.Comment.UpdatedAt)
 		bitbucketPrComment.UpdatedAt = &updatedAt
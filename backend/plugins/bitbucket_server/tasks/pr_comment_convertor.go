@@ -70,8 +70,12 @@ func ConvertPullRequestComments(taskCtx plugin.SubTaskContext) errors.Error {
 				CreatedDate:   prComment.CreatedAt,
 				Body:          prComment.Body,
 				Type:          "", // TODO
+				Status:        prComment.Status,
 				CommitSha:     "",
 			}
+			if prComment.Status != "" {
+				domainPrComment.Type = "REVIEW"
+			}
 			return []interface{}{
 				domainPrComment,
 			}, nil
@@ -27,6 +27,7 @@ import (
 	"github.com/apache/incubator-devlake/core/models/common"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/apache/incubator-devlake/plugins/customize/models"
 	"github.com/tidwall/gjson"
 )
@@ -94,6 +95,10 @@ func extractCustomizedFields(ctx context.Context, d dal.Dal, table, rawTable, ra
 		}
 		switch blob := row["data"].(type) {
 		case []byte:
+			blob, err = api.DecompressRawData(blob)
+			if err != nil {
+				return err
+			}
 			for field, path := range extractor {
 				result := gjson.GetBytes(blob, path)
 				fillInUpdates(result, field, updates)
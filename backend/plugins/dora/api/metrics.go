@@ -0,0 +1,266 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/dora/tasks"
+)
+
+type DoraMetricsOutput struct {
+	ProjectName string                           `json:"projectName"`
+	Environment string                           `json:"environment"`
+	PeriodType  string                           `json:"periodType"`
+	DoraReport  string                           `json:"doraReport"`
+	From        time.Time                        `json:"from"`
+	To          time.Time                        `json:"to"`
+	Summary     DoraMetricsSummary               `json:"summary"`
+	Periods     []*crossdomain.ProjectDoraMetric `json:"periods"`
+}
+
+// DoraMetricsSummary is the four key metrics over the whole time range along with their benchmark levels. The
+// lead time and time to restore are the medians over all the changes and incidents in the range, like the dashboards
+type DoraMetricsSummary struct {
+	DeploymentCount              int      `json:"deploymentCount"`
+	MedianDeploymentDaysPerWeek  *float64 `json:"medianDeploymentDaysPerWeek"`
+	MedianDeploymentDaysPerMonth *float64 `json:"medianDeploymentDaysPerMonth"`
+	DeploymentFrequencyLevel     string   `json:"deploymentFrequencyLevel"`
+	MedianLeadTimeMinutes        *int64   `json:"medianLeadTimeMinutes"`
+	LeadTimeLevel                string   `json:"leadTimeLevel"`
+	ChangeFailureRate            *float64 `json:"changeFailureRate"`
+	ChangeFailureRateLevel       string   `json:"changeFailureRateLevel"`
+	MedianTimeToRestoreMinutes   *int64   `json:"medianTimeToRestoreMinutes"`
+	TimeToRestoreLevel           string   `json:"timeToRestoreLevel"`
+}
+
+// GetProjectMetrics returns the DORA metrics of a project
+// @Summary get the DORA metrics of a project
+// @Description get the weekly or monthly rollups of deployment frequency, lead time for changes, change failure rate
+// @Description and time to restore service, along with the benchmark levels over the time range
+// @Tags plugins/dora
+// @Param projectName path string true "project name"
+// @Param environment query string false "deployment environment, PRODUCTION by default"
+// @Param periodType query string false "WEEK or MONTH, MONTH by default"
+// @Param doraReport query string false "2021 or 2023, 2023 by default"
+// @Param from query string false "start date, i.e. 2024-01-01, six months before the end date by default"
+// @Param to query string false "end date, i.e. 2024-06-30, today by default"
+// @Success 200  {object} DoraMetricsOutput
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router /plugins/dora/projects/{projectName}/metrics [GET]
+func GetProjectMetrics(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	db := basicRes.GetDal()
+	projectName := input.Params["projectName"]
+	count, err := db.Count(dal.From(&models.Project{}), dal.Where("name = ?", projectName))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error checking project")
+	}
+	if count == 0 {
+		return nil, errors.NotFound.New("could not find project " + projectName)
+	}
+
+	output := &DoraMetricsOutput{
+		ProjectName: projectName,
+		Environment: input.Query.Get("environment"),
+		PeriodType:  strings.ToUpper(input.Query.Get("periodType")),
+	}
+	if output.Environment == "" {
+		output.Environment = devops.PRODUCTION
+	}
+	switch output.PeriodType {
+	case "":
+		output.PeriodType = crossdomain.DORA_PERIOD_MONTH
+	case crossdomain.DORA_PERIOD_WEEK, crossdomain.DORA_PERIOD_MONTH:
+	default:
+		return nil, errors.BadInput.New("periodType must be either WEEK or MONTH")
+	}
+	benchmark, err := tasks.NewDoraBenchmark(input.Query.Get("doraReport"))
+	if err != nil {
+		return nil, err
+	}
+	output.DoraReport = benchmark.Report()
+	output.To, err = parseDate(input.Query.Get("to"), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	output.From, err = parseDate(input.Query.Get("from"), output.To.AddDate(0, -6, 0))
+	if err != nil {
+		return nil, err
+	}
+	if output.From.After(output.To) {
+		return nil, errors.BadInput.New("from must be before to")
+	}
+
+	// load both period types, the deployment frequency depends on the weekly and the monthly deployment days
+	var metrics []*crossdomain.ProjectDoraMetric
+	err = db.All(
+		&metrics,
+		dal.Where(
+			"project_name = ? AND environment = ? AND period_start >= ? AND period_start <= ?",
+			projectName, output.Environment,
+			tasks.GetDoraPeriodStart(crossdomain.DORA_PERIOD_MONTH, output.From), output.To,
+		),
+		dal.Orderby("period_start"),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading project_dora_metrics")
+	}
+	weeks := fillDoraPeriods(metrics, projectName, output.Environment, crossdomain.DORA_PERIOD_WEEK, output.From, output.To)
+	months := fillDoraPeriods(metrics, projectName, output.Environment, crossdomain.DORA_PERIOD_MONTH, output.From, output.To)
+	output.Periods = months
+	if output.PeriodType == crossdomain.DORA_PERIOD_WEEK {
+		output.Periods = weeks
+	}
+	leadTimes, timesToRestore, err := tasks.GetDoraRawValues(db, projectName, output.Environment, output.PeriodType, output.From, output.To)
+	if err != nil {
+		return nil, err
+	}
+	output.Summary = summarizeDoraMetrics(benchmark, output.Periods, weeks, months, leadTimes, timesToRestore)
+	return &plugin.ApiResourceOutput{Body: output, Status: http.StatusOK}, nil
+}
+
+func parseDate(value string, defaultValue time.Time) (time.Time, errors.Error) {
+	if value == "" {
+		return tasks.GetDoraPeriodStart("", defaultValue), nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return date, errors.BadInput.Wrap(err, "invalid date, expected format: 2006-01-02")
+	}
+	return date, nil
+}
+
+// fillDoraPeriods returns the metrics of all the periods in the time range, periods without any activity are
+// filled with empty metrics
+func fillDoraPeriods(
+	metrics []*crossdomain.ProjectDoraMetric,
+	projectName, environment, periodType string,
+	from, to time.Time,
+) []*crossdomain.ProjectDoraMetric {
+	metricsByStart := make(map[time.Time]*crossdomain.ProjectDoraMetric)
+	for _, metric := range metrics {
+		if metric.PeriodType == periodType {
+			metricsByStart[metric.PeriodStart.UTC()] = metric
+		}
+	}
+	var periods []*crossdomain.ProjectDoraMetric
+	for start := tasks.GetDoraPeriodStart(periodType, from); !start.After(to); {
+		metric, ok := metricsByStart[start]
+		if !ok {
+			metric = &crossdomain.ProjectDoraMetric{
+				ProjectName: projectName,
+				Environment: environment,
+				PeriodType:  periodType,
+				PeriodStart: start,
+			}
+		}
+		periods = append(periods, metric)
+		if periodType == crossdomain.DORA_PERIOD_WEEK {
+			start = start.AddDate(0, 0, 7)
+		} else {
+			start = start.AddDate(0, 1, 0)
+		}
+	}
+	return periods
+}
+
+func summarizeDoraMetrics(
+	benchmark *tasks.DoraBenchmark,
+	periods, weeks, months []*crossdomain.ProjectDoraMetric,
+	leadTimes, timesToRestore []int64,
+) DoraMetricsSummary {
+	summary := DoraMetricsSummary{}
+	failedDeploymentCount := 0
+	for _, period := range periods {
+		summary.DeploymentCount += period.DeploymentCount
+		failedDeploymentCount += period.FailedDeploymentCount
+	}
+	if summary.DeploymentCount > 0 {
+		rate := float64(failedDeploymentCount) / float64(summary.DeploymentCount)
+		summary.ChangeFailureRate = &rate
+	}
+	summary.MedianLeadTimeMinutes = toMinutes(median(toFloats(leadTimes)))
+	summary.MedianTimeToRestoreMinutes = toMinutes(median(toFloats(timesToRestore)))
+
+	var daysPerWeek, daysPerMonth, daysPerSixMonths []float64
+	for _, week := range weeks {
+		daysPerWeek = append(daysPerWeek, float64(week.DeploymentDays))
+	}
+	// six months are counted backward from the latest month
+	sixMonths := 0.0
+	for i := range months {
+		month := months[len(months)-1-i]
+		daysPerMonth = append(daysPerMonth, float64(month.DeploymentDays))
+		sixMonths += float64(month.DeploymentDays)
+		if (i+1)%6 == 0 {
+			daysPerSixMonths = append(daysPerSixMonths, sixMonths)
+			sixMonths = 0
+		}
+	}
+	if summary.DeploymentCount > 0 {
+		summary.MedianDeploymentDaysPerWeek = median(daysPerWeek)
+		summary.MedianDeploymentDaysPerMonth = median(daysPerMonth)
+	}
+
+	summary.DeploymentFrequencyLevel = benchmark.DeploymentFrequency(
+		summary.MedianDeploymentDaysPerWeek,
+		summary.MedianDeploymentDaysPerMonth,
+		median(daysPerSixMonths),
+	)
+	summary.LeadTimeLevel = benchmark.LeadTime(summary.MedianLeadTimeMinutes)
+	summary.ChangeFailureRateLevel = benchmark.ChangeFailureRate(summary.ChangeFailureRate)
+	summary.TimeToRestoreLevel = benchmark.TimeToRestore(summary.MedianTimeToRestoreMinutes)
+	return summary
+}
+
+// median returns the largest value whose percent rank is no more than 0.5 like the dashboards, nil for empty values
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return &sorted[(len(sorted)-1)/2]
+}
+
+func toFloats(values []int64) []float64 {
+	floats := make([]float64, len(values))
+	for i, value := range values {
+		floats[i] = float64(value)
+	}
+	return floats
+}
+
+func toMinutes(value *float64) *int64 {
+	if value == nil {
+		return nil
+	}
+	minutes := int64(*value)
+	return &minutes
+}
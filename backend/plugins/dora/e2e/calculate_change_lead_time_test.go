@@ -51,6 +51,11 @@ func TestCalculateCLTimeDataFlow(t *testing.T) {
 	dataflowTester.ImportCsvIntoTabler("./change_lead_time/pull_request_comments.csv", &code.PullRequestComment{})
 	dataflowTester.ImportCsvIntoTabler("./change_lead_time/pull_request_commits.csv", &code.PullRequestCommit{})
 
+	// no team membership is known
+	dataflowTester.FlushTabler(&crossdomain.UserAccount{})
+	dataflowTester.FlushTabler(&crossdomain.TeamMembership{})
+	dataflowTester.FlushTabler(&crossdomain.Team{})
+
 	// verify converter
 	dataflowTester.FlushTabler(&crossdomain.ProjectPrMetric{})
 	dataflowTester.Subtask(tasks.CalculateChangeLeadTimeMeta, taskData)
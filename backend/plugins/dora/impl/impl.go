@@ -20,10 +20,12 @@ package impl
 import (
 	"encoding/json"
 
+	"github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	coreModels "github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/dora/api"
 	"github.com/apache/incubator-devlake/plugins/dora/models/migrationscripts"
 	"github.com/apache/incubator-devlake/plugins/dora/tasks"
 )
@@ -31,7 +33,9 @@ import (
 // make sure interface is implemented
 var _ interface {
 	plugin.PluginMeta
+	plugin.PluginInit
 	plugin.PluginTask
+	plugin.PluginApi
 	plugin.PluginModel
 	plugin.PluginMetric
 	plugin.PluginMigration
@@ -40,6 +44,11 @@ var _ interface {
 
 type Dora struct{}
 
+func (p Dora) Init(basicRes context.BasicRes) errors.Error {
+	api.Init(basicRes)
+	return nil
+}
+
 func (p Dora) Description() string {
 	return "collect some Dora data"
 }
@@ -92,9 +101,13 @@ func (p Dora) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.DeploymentGeneratorMeta,
 		tasks.DeploymentCommitsGeneratorMeta,
 		tasks.EnrichPrevSuccessDeploymentCommitMeta,
+		tasks.ClassifyDeploymentsMeta,
 		tasks.EnrichTaskEnvMeta,
 		tasks.CalculateChangeLeadTimeMeta,
 		tasks.ConnectIncidentToDeploymentMeta,
+		tasks.CalculateDoraMetricsMeta,
+		tasks.CalculatePrReviewMetricsMeta,
+		tasks.CalculateTeamAttributionsMeta,
 	}
 }
 
@@ -113,6 +126,14 @@ func (p Dora) RootPkgPath() string {
 	return "github.com/apache/incubator-devlake/plugins/dora"
 }
 
+func (p Dora) ApiResources() map[string]map[string]plugin.ApiResourceHandler {
+	return map[string]map[string]plugin.ApiResourceHandler{
+		"projects/:projectName/metrics": {
+			"GET": api.GetProjectMetrics,
+		},
+	}
+}
+
 func (p Dora) MigrationScripts() []plugin.MigrationScript {
 	return migrationscripts.All()
 }
@@ -126,17 +147,32 @@ func (p Dora) MakeMetricPluginPipelinePlanV200(projectName string, options json.
 		}
 	}
 
+	doraOptions := map[string]interface{}{
+		"projectName": projectName,
+	}
+	if op.HotfixBranchPattern != "" {
+		doraOptions["hotfixBranchPattern"] = op.HotfixBranchPattern
+	}
+	if op.HotfixTitlePattern != "" {
+		doraOptions["hotfixTitlePattern"] = op.HotfixTitlePattern
+	}
+	if op.RollbackAsChangeFailure {
+		doraOptions["rollbackAsChangeFailure"] = true
+	}
+	if len(op.IncidentAttributionRules) > 0 {
+		doraOptions["incidentAttributionRules"] = op.IncidentAttributionRules
+	}
+
 	plan := coreModels.PipelinePlan{
 		{
 			{
-				Plugin: "dora",
-				Options: map[string]interface{}{
-					"projectName": projectName,
-				},
+				Plugin:  "dora",
+				Options: doraOptions,
 				Subtasks: []string{
 					"generateDeployments",
 					"generateDeploymentCommits",
 					"enrichPrevSuccessDeploymentCommits",
+					"classifyDeployments",
 				},
 			},
 		},
@@ -153,13 +189,14 @@ func (p Dora) MakeMetricPluginPipelinePlanV200(projectName string, options json.
 		},
 		{
 			{
-				Plugin: "dora",
-				Options: map[string]interface{}{
-					"projectName": projectName,
-				},
+				Plugin:  "dora",
+				Options: doraOptions,
 				Subtasks: []string{
 					"calculateChangeLeadTime",
+					"calculateTeamAttributions",
 					"ConnectIncidentToDeployment",
+					"calculateDoraMetrics",
+					"calculatePrReviewMetrics",
 				},
 			},
 		},
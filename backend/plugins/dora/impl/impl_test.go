@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/plugins/dora/tasks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,6 +45,7 @@ func TestMakeMetricPluginPipelinePlanV200(t *testing.T) {
 					"generateDeployments",
 					"generateDeploymentCommits",
 					"enrichPrevSuccessDeploymentCommits",
+					"classifyDeployments",
 				},
 				Options: map[string]interface{}{"projectName": projectName},
 			},
@@ -60,11 +62,40 @@ func TestMakeMetricPluginPipelinePlanV200(t *testing.T) {
 				Plugin: "dora",
 				Subtasks: []string{
 					"calculateChangeLeadTime",
+					"calculateTeamAttributions",
 					"ConnectIncidentToDeployment",
+					"calculateDoraMetrics",
+					"calculatePrReviewMetrics",
 				},
 				Options: map[string]interface{}{"projectName": projectName},
 			},
 		},
 	}
 	assert.Equal(t, doraOutputPlan, plan)
+
+	// deployment classification options are passed to both dora stages
+	option["hotfixBranchPattern"] = "^hotfix/"
+	option["rollbackAsChangeFailure"] = true
+	optionJson, err = json.Marshal(option)
+	assert.Nil(t, err)
+	plan, err = dora.MakeMetricPluginPipelinePlanV200(projectName, optionJson)
+	assert.Nil(t, err)
+	expectedOptions := map[string]interface{}{
+		"projectName":             projectName,
+		"hotfixBranchPattern":     "^hotfix/",
+		"rollbackAsChangeFailure": true,
+	}
+	assert.Equal(t, expectedOptions, plan[0][0].Options)
+	assert.Equal(t, map[string]interface{}{"projectName": projectName}, plan[1][0].Options)
+	assert.Equal(t, expectedOptions, plan[2][0].Options)
+
+	// incident attribution rules come from the PluginOption of the metric setting
+	pluginOption := `{"incidentAttributionRules":[{"component":"^payments$","cicdScopeIds":["github:GithubRepo:1:1"]}]}`
+	plan, err = dora.MakeMetricPluginPipelinePlanV200(projectName, json.RawMessage(pluginOption))
+	assert.Nil(t, err)
+	rules := []tasks.IncidentAttributionRule{{Component: "^payments$", CicdScopeIds: []string{"github:GithubRepo:1:1"}}}
+	assert.Equal(t, rules, plan[2][0].Options["incidentAttributionRules"])
+	op, err := tasks.DecodeAndValidateTaskOptions(plan[2][0].Options)
+	assert.Nil(t, err)
+	assert.Equal(t, rules, op.IncidentAttributionRules)
 }
@@ -38,6 +38,17 @@ var CalculateChangeLeadTimeMeta = plugin.SubTaskMeta{
 	EnabledByDefault: true,
 	Description:      "Calculate change lead time",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD, plugin.DOMAIN_TYPE_CODE},
+	DependencyTables: []string{
+		code.PullRequest{}.TableName(),
+		code.PullRequestCommit{}.TableName(),
+		code.PullRequestComment{}.TableName(),
+		code.CommitsDiff{}.TableName(),
+		devops.CicdDeploymentCommit{}.TableName(),
+		crossdomain.ProjectMapping{}.TableName(),
+		crossdomain.UserAccount{}.TableName(),
+		crossdomain.TeamMembership{}.TableName(),
+	},
+	ProductTables: []string{crossdomain.ProjectPrMetric{}.TableName()},
 }
 
 // CalculateChangeLeadTime calculates change lead time for a project.
@@ -51,6 +62,10 @@ func CalculateChangeLeadTime(taskCtx plugin.SubTaskContext) errors.Error {
 	if err != nil {
 		return errors.Default.Wrap(err, "error deleting previous project_pr_metrics")
 	}
+	teamResolver, err := api.NewTeamResolver(db)
+	if err != nil {
+		return err
+	}
 
 	// Get pull requests by repo project_name
 	var clauses = []dal.Clause{
@@ -83,6 +98,7 @@ func CalculateChangeLeadTime(taskCtx plugin.SubTaskContext) errors.Error {
 			projectPrMetric := &crossdomain.ProjectPrMetric{}
 			projectPrMetric.Id = pr.Id
 			projectPrMetric.ProjectName = data.Options.ProjectName
+			projectPrMetric.AuthorTeamId = teamResolver.AccountTeamAt(pr.AuthorId, pr.CreatedDate)
 
 			// Get the first commit for the PR
 			firstCommit, err := getFirstCommit(pr.Id, db)
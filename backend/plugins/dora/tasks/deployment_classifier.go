@@ -0,0 +1,258 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"regexp"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+var ClassifyDeploymentsMeta = plugin.SubTaskMeta{
+	Name:             "classifyDeployments",
+	EntryPoint:       ClassifyDeployments,
+	EnabledByDefault: false,
+	Description:      "classify cicd_deployment_commits as forward, rollback or hotfix deployments",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		devops.CicdDeploymentCommit{}.TableName(),
+		code.CommitParent{}.TableName(),
+		crossdomain.ProjectMapping{}.TableName(),
+	},
+	ProductTables: []string{devops.CicdDeploymentCommit{}.TableName()},
+}
+
+// maxRollbackSearchCommits bounds the number of commits walked through commit_parents from each of the deployed
+// commit and the previously deployed one when looking for one among the ancestors of the other
+const maxRollbackSearchCommits = 10000
+
+// ClassifyDeployments sets the deployment_type of the successful deployment commits in the project:
+// a deployment whose commit is an ancestor of the commit deployed previously to the same environment is a
+// rollback, one matching the hotfix branch or title pattern is a hotfix, and any other one is a forward deployment.
+// It relies on prev_success_deployment_commit_id, so it must run after enrichPrevSuccessDeploymentCommits.
+func ClassifyDeployments(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*DoraTaskData)
+
+	classifier, err := newDeploymentClassifier(data.Options, func(shas []string) (map[string][]string, errors.Error) {
+		var commitParents []code.CommitParent
+		err := db.All(&commitParents, dal.Where("commit_sha IN ?", shas))
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "error loading commit_parents")
+		}
+		parents := make(map[string][]string, len(shas))
+		for _, commitParent := range commitParents {
+			parents[commitParent.CommitSha] = append(parents[commitParent.CommitSha], commitParent.ParentCommitSha)
+		}
+		return parents, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var clauses = []dal.Clause{
+		dal.From("cicd_deployment_commits dc"),
+		dal.Where("dc.finished_date IS NOT NULL AND dc.result = ?", devops.RESULT_SUCCESS),
+	}
+	if data.Options.ScopeId != nil {
+		clauses = append(clauses, dal.Where("dc.cicd_scope_id = ?", data.Options.ScopeId))
+	} else {
+		clauses = append(clauses,
+			dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'cicd_scopes' AND pm.row_id = dc.cicd_scope_id)"),
+			dal.Where("pm.project_name = ?", data.Options.ProjectName),
+		)
+	}
+
+	// step 1. load the commit sha of every successful deployment commit so the previous one can be looked up
+	var deployedCommits []devops.CicdDeploymentCommit
+	err = db.All(&deployedCommits, append([]dal.Clause{dal.Select("dc.id, dc.commit_sha")}, clauses...)...)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading deployed commits")
+	}
+	commitShas := make(map[string]string, len(deployedCommits))
+	for _, deployedCommit := range deployedCommits {
+		commitShas[deployedCommit.Id] = deployedCommit.CommitSha
+	}
+
+	// step 2. classify each of them against its previous successful deployment
+	cursor, err := db.Cursor(append([]dal.Clause{dal.Select("dc.*")}, clauses...)...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	enricher, err := api.NewDataEnricher(api.DataEnricherArgs[devops.CicdDeploymentCommit]{
+		Ctx:   taskCtx,
+		Name:  "deployment_classifier",
+		Input: cursor,
+		Enrich: func(deploymentCommit *devops.CicdDeploymentCommit) ([]interface{}, errors.Error) {
+			deploymentType, err := classifier.classify(
+				deploymentCommit,
+				commitShas[deploymentCommit.PrevSuccessDeploymentCommitId],
+			)
+			if err != nil {
+				return nil, err
+			}
+			deploymentCommit.DeploymentType = deploymentType
+			return []interface{}{deploymentCommit}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return enricher.Execute()
+}
+
+type deploymentClassifier struct {
+	hotfixBranch *regexp.Regexp
+	hotfixTitle  *regexp.Regexp
+	// parentsOf returns the parent commits of each of the given commits
+	parentsOf func(shas []string) (map[string][]string, errors.Error)
+	// parents caches the parents of the commits walked through, the deployments of a project share most of their history
+	parents map[string][]string
+}
+
+func newDeploymentClassifier(
+	op *DoraOptions,
+	parentsOf func(shas []string) (map[string][]string, errors.Error),
+) (*deploymentClassifier, errors.Error) {
+	classifier := &deploymentClassifier{parentsOf: parentsOf, parents: make(map[string][]string)}
+	var err error
+	if op.HotfixBranchPattern != "" {
+		classifier.hotfixBranch, err = regexp.Compile(op.HotfixBranchPattern)
+		if err != nil {
+			return nil, errors.BadInput.Wrap(err, "invalid hotfixBranchPattern")
+		}
+	}
+	if op.HotfixTitlePattern != "" {
+		classifier.hotfixTitle, err = regexp.Compile(op.HotfixTitlePattern)
+		if err != nil {
+			return nil, errors.BadInput.Wrap(err, "invalid hotfixTitlePattern")
+		}
+	}
+	return classifier, nil
+}
+
+// classify returns the deployment type of the deployment commit, prevCommitSha being the commit deployed by the
+// previous successful deployment to the same environment, if any
+func (c *deploymentClassifier) classify(deploymentCommit *devops.CicdDeploymentCommit, prevCommitSha string) (string, errors.Error) {
+	// redeploying the very same commit is not a rollback
+	if prevCommitSha != "" && prevCommitSha != deploymentCommit.CommitSha {
+		isRollback, err := c.isRollback(deploymentCommit.CommitSha, prevCommitSha)
+		if err != nil {
+			return "", err
+		}
+		if isRollback {
+			return devops.DEPLOYMENT_TYPE_ROLLBACK, nil
+		}
+	}
+	if c.hotfixBranch != nil && c.hotfixBranch.MatchString(deploymentCommit.RefName) {
+		return devops.DEPLOYMENT_TYPE_HOTFIX, nil
+	}
+	if c.hotfixTitle != nil && c.hotfixTitle.MatchString(deploymentCommit.DisplayTitle) {
+		return devops.DEPLOYMENT_TYPE_HOTFIX, nil
+	}
+	return devops.DEPLOYMENT_TYPE_FORWARD, nil
+}
+
+// isRollback tells whether the commit is an ancestor of the previously deployed one. The histories of both commits
+// are walked together one level at a time, so a forward deployment, whose previous commit is usually a close
+// ancestor, is told as soon as it is found instead of walking the whole history of the previous commit
+func (c *deploymentClassifier) isRollback(commitSha, prevCommitSha string) (bool, errors.Error) {
+	forward := newHistoryWalker(commitSha)
+	backward := newHistoryWalker(prevCommitSha)
+	for !forward.done() || !backward.done() {
+		var frontier []string
+		if !forward.done() {
+			frontier = append(frontier, forward.frontier...)
+		}
+		if !backward.done() {
+			frontier = append(frontier, backward.frontier...)
+		}
+		if err := c.loadParents(frontier); err != nil {
+			return false, err
+		}
+		if !forward.done() && forward.step(c.parents, prevCommitSha) {
+			return false, nil
+		}
+		if !backward.done() && backward.step(c.parents, commitSha) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// loadParents caches the parents of the given commits, the ones not cached yet are loaded at once
+func (c *deploymentClassifier) loadParents(shas []string) errors.Error {
+	var missing []string
+	for _, sha := range shas {
+		if _, ok := c.parents[sha]; !ok {
+			missing = append(missing, sha)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	parents, err := c.parentsOf(missing)
+	if err != nil {
+		return err
+	}
+	for _, sha := range missing {
+		c.parents[sha] = parents[sha]
+	}
+	return nil
+}
+
+// historyWalker walks the history of a commit breadth first, up to maxRollbackSearchCommits commits
+type historyWalker struct {
+	visited  map[string]bool
+	frontier []string
+}
+
+func newHistoryWalker(sha string) *historyWalker {
+	return &historyWalker{visited: map[string]bool{sha: true}, frontier: []string{sha}}
+}
+
+func (w *historyWalker) done() bool {
+	return len(w.frontier) == 0 || len(w.visited) >= maxRollbackSearchCommits
+}
+
+// step walks one level up the history, and reports whether the target is among the parents
+func (w *historyWalker) step(parents map[string][]string, target string) bool {
+	var next []string
+	for _, sha := range w.frontier {
+		for _, parent := range parents[sha] {
+			if parent == target {
+				return true
+			}
+			if !w.visited[parent] {
+				w.visited[parent] = true
+				next = append(next, parent)
+			}
+		}
+	}
+	w.frontier = next
+	return false
+}
@@ -0,0 +1,89 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentClassifier(t *testing.T) {
+	// c1 <- c2 <- c3 <- c5 (merge) and c2 <- c4 <- c5
+	history := map[string][]string{
+		"c2": {"c1"},
+		"c3": {"c2"},
+		"c4": {"c2"},
+		"c5": {"c3", "c4"},
+	}
+	var lookups int
+	classifier, err := newDeploymentClassifier(
+		&DoraOptions{HotfixBranchPattern: `^(refs/heads/)?hotfix/`, HotfixTitlePattern: `(?i)\bhotfix\b`},
+		func(shas []string) (map[string][]string, errors.Error) {
+			lookups++
+			parents := make(map[string][]string)
+			for _, sha := range shas {
+				parents[sha] = history[sha]
+			}
+			return parents, nil
+		},
+	)
+	assert.Nil(t, err)
+
+	classify := func(commitSha, refName, title, prevCommitSha string) string {
+		deploymentType, err := classifier.classify(&devops.CicdDeploymentCommit{
+			CommitSha:    commitSha,
+			RefName:      refName,
+			DisplayTitle: title,
+		}, prevCommitSha)
+		assert.Nil(t, err)
+		return deploymentType
+	}
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_FORWARD, classify("c5", "main", "release", "c3"))
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_FORWARD, classify("c1", "main", "first deployment", ""))
+	// redeploying the same commit is not a rollback
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_FORWARD, classify("c5", "main", "redeploy", "c5"))
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_ROLLBACK, classify("c3", "main", "release", "c5"))
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_ROLLBACK, classify("c1", "hotfix/login", "release", "c5"))
+	// c4 and c3 are siblings
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_FORWARD, classify("c4", "main", "release", "c3"))
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_HOTFIX, classify("c4", "refs/heads/hotfix/login", "release", "c3"))
+	assert.Equal(t, devops.DEPLOYMENT_TYPE_HOTFIX, classify("c4", "main", "Hotfix: login", "c3"))
+
+	// a forward deployment stops at its previous commit, and the parents are loaded once per level for both commits
+	classifier.parents = make(map[string][]string)
+	lookups = 0
+	isRollback, err := classifier.isRollback("c3", "c2")
+	assert.Nil(t, err)
+	assert.False(t, isRollback)
+	assert.Equal(t, 1, lookups)
+	isRollback, err = classifier.isRollback("c1", "c5")
+	assert.Nil(t, err)
+	assert.True(t, isRollback)
+	assert.Equal(t, 3, lookups)
+	// the parents walked through are cached
+	isRollback, err = classifier.isRollback("c2", "c5")
+	assert.Nil(t, err)
+	assert.True(t, isRollback)
+	assert.Equal(t, 3, lookups)
+
+	_, err = newDeploymentClassifier(&DoraOptions{HotfixTitlePattern: "("}, nil)
+	assert.NotNil(t, err)
+}
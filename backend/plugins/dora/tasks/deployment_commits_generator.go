@@ -25,6 +25,7 @@ import (
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
@@ -38,6 +39,8 @@ var DeploymentCommitsGeneratorMeta = plugin.SubTaskMeta{
 	EnabledByDefault: false, // it should be executed before refdiff.calculateDeploymentCommitsDiff, check https://github.com/apache/incubator-devlake/issues/4869 for detail
 	Description:      "Generate deployment_commits from cicd_pipeline_commits if cicd_pipeline.type == DEPLOYMENT or any of its cicd_tasks is a deployment task",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{devops.CiCDPipelineCommit{}.TableName(), devops.CICDPipeline{}.TableName(), devops.CICDTask{}.TableName(), crossdomain.ProjectMapping{}.TableName()},
+	ProductTables:    []string{devops.CicdDeploymentCommit{}.TableName()},
 }
 
 type pipelineCommitEx struct {
@@ -23,6 +23,7 @@ import (
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
@@ -36,6 +37,8 @@ var DeploymentGeneratorMeta = plugin.SubTaskMeta{
 	EnabledByDefault: true,
 	Description:      "Generate cicd_deployments from cicd_pipelines if cicd_pipeline.type == DEPLOYMENT or any of its cicd_tasks is a deployment task",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{devops.CICDPipeline{}.TableName(), devops.CICDTask{}.TableName(), crossdomain.ProjectMapping{}.TableName()},
+	ProductTables:    []string{devops.CICDDeployment{}.TableName()},
 }
 
 type pipelineEx struct {
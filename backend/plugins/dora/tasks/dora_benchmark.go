@@ -0,0 +1,140 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+const (
+	DORA_REPORT_2021 = "2021"
+	DORA_REPORT_2023 = "2023"
+
+	DORA_LEVEL_ELITE  = "elite"
+	DORA_LEVEL_HIGH   = "high"
+	DORA_LEVEL_MEDIUM = "medium"
+	DORA_LEVEL_LOW    = "low"
+)
+
+const (
+	minutesPerHour = 60
+	minutesPerDay  = 24 * minutesPerHour
+)
+
+// DoraBenchmark buckets the DORA metrics into the performance levels of a State of DevOps report, the
+// thresholds are the same as the ones of the dora_benchmarks table and the dashboards. Empty string is
+// returned when the metric is unavailable
+type DoraBenchmark struct {
+	report string
+}
+
+// NewDoraBenchmark returns the benchmark of the given report, 2023 by default
+func NewDoraBenchmark(report string) (*DoraBenchmark, errors.Error) {
+	switch report {
+	case "":
+		report = DORA_REPORT_2023
+	case DORA_REPORT_2021, DORA_REPORT_2023:
+	default:
+		return nil, errors.BadInput.New("doraReport must be either 2021 or 2023")
+	}
+	return &DoraBenchmark{report: report}, nil
+}
+
+// Report returns the year of the report
+func (b *DoraBenchmark) Report() string {
+	return b.report
+}
+
+// DeploymentFrequency buckets the median number of deployment days per week, month and six months
+func (b *DoraBenchmark) DeploymentFrequency(daysPerWeek, daysPerMonth, daysPerSixMonths *float64) string {
+	if daysPerWeek == nil || daysPerMonth == nil {
+		return ""
+	}
+	if b.report == DORA_REPORT_2021 {
+		switch {
+		case *daysPerWeek >= 7:
+			return DORA_LEVEL_ELITE
+		case *daysPerMonth >= 1:
+			return DORA_LEVEL_HIGH
+		case daysPerSixMonths != nil && *daysPerSixMonths >= 1:
+			return DORA_LEVEL_MEDIUM
+		}
+		return DORA_LEVEL_LOW
+	}
+	switch {
+	case *daysPerWeek >= 7:
+		return DORA_LEVEL_ELITE
+	case *daysPerWeek >= 1:
+		return DORA_LEVEL_HIGH
+	case *daysPerMonth >= 1:
+		return DORA_LEVEL_MEDIUM
+	}
+	return DORA_LEVEL_LOW
+}
+
+// LeadTime buckets the median lead time for changes in minutes
+func (b *DoraBenchmark) LeadTime(minutes *int64) string {
+	if minutes == nil {
+		return ""
+	}
+	if b.report == DORA_REPORT_2021 {
+		return bucketMinutes(*minutes, minutesPerHour, 7*minutesPerDay, 180*minutesPerDay)
+	}
+	return bucketMinutes(*minutes, minutesPerDay, 7*minutesPerDay, 30*minutesPerDay)
+}
+
+// ChangeFailureRate buckets the ratio of deployments causing incidents
+func (b *DoraBenchmark) ChangeFailureRate(rate *float64) string {
+	if rate == nil {
+		return ""
+	}
+	elite, high, medium := .05, .10, .15
+	if b.report == DORA_REPORT_2021 {
+		elite, high, medium = .15, .20, .30
+	}
+	switch {
+	case *rate <= elite:
+		return DORA_LEVEL_ELITE
+	case *rate <= high:
+		return DORA_LEVEL_HIGH
+	case *rate <= medium:
+		return DORA_LEVEL_MEDIUM
+	}
+	return DORA_LEVEL_LOW
+}
+
+// TimeToRestore buckets the median time to restore service (failed deployment recovery time in the 2023 report)
+// in minutes
+func (b *DoraBenchmark) TimeToRestore(minutes *int64) string {
+	if minutes == nil {
+		return ""
+	}
+	return bucketMinutes(*minutes, minutesPerHour, minutesPerDay, 7*minutesPerDay)
+}
+
+func bucketMinutes(minutes, elite, high, medium int64) string {
+	switch {
+	case minutes < elite:
+		return DORA_LEVEL_ELITE
+	case minutes < high:
+		return DORA_LEVEL_HIGH
+	case minutes < medium:
+		return DORA_LEVEL_MEDIUM
+	}
+	return DORA_LEVEL_LOW
+}
@@ -0,0 +1,346 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+var CalculateDoraMetricsMeta = plugin.SubTaskMeta{
+	Name:             "calculateDoraMetrics",
+	EntryPoint:       CalculateDoraMetrics,
+	EnabledByDefault: true,
+	Description:      "Rollup deployment frequency, lead time, change failure rate and time to restore by week and month",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD, plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_TICKET},
+	DependencyTables: []string{
+		devops.CicdDeploymentCommit{}.TableName(),
+		crossdomain.ProjectMapping{}.TableName(),
+		crossdomain.ProjectPrMetric{}.TableName(),
+		crossdomain.ProjectIssueMetric{}.TableName(),
+		ticket.Issue{}.TableName(),
+	},
+	ProductTables: []string{crossdomain.ProjectDoraMetric{}.TableName()},
+}
+
+type doraDeployment struct {
+	Id           string
+	Environment  string
+	FinishedDate *time.Time
+}
+
+type doraChange struct {
+	DeploymentId string
+	PrCycleTime  int64
+}
+
+type doraIncident struct {
+	DeploymentId   string
+	ResolutionDate *time.Time
+}
+
+// CalculateDoraMetrics rolls up the deployments, the deployed pull requests and the incidents caused by the
+// deployments of the project into project_dora_metrics
+func CalculateDoraMetrics(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*DoraTaskData)
+	projectName := data.Options.ProjectName
+
+	deployments, changes, incidents, err := loadDoraInputs(db, projectName)
+	if err != nil {
+		return err
+	}
+
+	// a rollback undoes the deployment right before it, which may be counted as failed without any incident
+	var rolledBackDeploymentIds []string
+	if data.Options.RollbackAsChangeFailure {
+		err = db.Pluck(
+			"prev.cicd_deployment_id",
+			&rolledBackDeploymentIds,
+			dal.From("cicd_deployment_commits cdc"),
+			dal.Join("JOIN cicd_deployment_commits prev ON prev.id = cdc.prev_success_deployment_commit_id"),
+			dal.Join("JOIN project_mapping pm ON cdc.cicd_scope_id = pm.row_id"),
+			dal.Where(
+				"pm.table = ? AND pm.project_name = ? AND cdc.deployment_type = ?",
+				"cicd_scopes", projectName, devops.DEPLOYMENT_TYPE_ROLLBACK,
+			),
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "error loading rolled back deployments")
+		}
+	}
+
+	// Clear previous results from the project
+	err = db.Delete(&crossdomain.ProjectDoraMetric{}, dal.Where("project_name = ?", projectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting previous project_dora_metrics")
+	}
+	batch, err := api.NewBatchSave(taskCtx, reflect.TypeOf(&crossdomain.ProjectDoraMetric{}), 500)
+	if err != nil {
+		return err
+	}
+	for _, metric := range rollupDoraMetrics(projectName, deployments, changes, incidents, rolledBackDeploymentIds) {
+		err = batch.Add(metric)
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Close()
+}
+
+// loadDoraInputs loads the successful deployments of the project, the pull requests they deployed and the incidents
+// they caused
+func loadDoraInputs(db dal.Dal, projectName string) ([]doraDeployment, []doraChange, []doraIncident, errors.Error) {
+	// multiple deployment commits of the same deployment are considered as ONE deployment, the last
+	// finished_date is used as the finished date
+	var deployments []doraDeployment
+	err := db.All(
+		&deployments,
+		dal.Select("cdc.cicd_deployment_id AS id, cdc.environment, MAX(cdc.finished_date) AS finished_date"),
+		dal.From("cicd_deployment_commits cdc"),
+		dal.Join("JOIN project_mapping pm ON cdc.cicd_scope_id = pm.row_id"),
+		dal.Where(
+			"pm.table = ? AND pm.project_name = ? AND cdc.result = ? AND cdc.finished_date IS NOT NULL",
+			"cicd_scopes", projectName, devops.RESULT_SUCCESS,
+		),
+		dal.Groupby("cdc.cicd_deployment_id, cdc.environment"),
+	)
+	if err != nil {
+		return nil, nil, nil, errors.Default.Wrap(err, "error loading deployments")
+	}
+
+	var changes []doraChange
+	err = db.All(
+		&changes,
+		dal.Select("cdc.cicd_deployment_id AS deployment_id, ppm.pr_cycle_time"),
+		dal.From("project_pr_metrics ppm"),
+		dal.Join("JOIN cicd_deployment_commits cdc ON ppm.deployment_commit_id = cdc.id"),
+		dal.Where("ppm.project_name = ? AND ppm.pr_cycle_time IS NOT NULL", projectName),
+	)
+	if err != nil {
+		return nil, nil, nil, errors.Default.Wrap(err, "error loading deployed pull requests")
+	}
+
+	var incidents []doraIncident
+	err = db.All(
+		&incidents,
+		dal.Select("pim.deployment_id, i.resolution_date"),
+		dal.From("project_issue_metrics pim"),
+		dal.Join("JOIN issues i ON i.id = pim.id"),
+		dal.Where("pim.project_name = ? AND i.type = ?", projectName, ticket.INCIDENT),
+	)
+	if err != nil {
+		return nil, nil, nil, errors.Default.Wrap(err, "error loading incidents")
+	}
+	return deployments, changes, incidents, nil
+}
+
+// GetDoraRawValues returns the lead times of the pull requests deployed to the environment and the times to restore
+// of the incidents they caused, in minutes, within the periods of the type from the one containing `from` to the one
+// containing `to`. They are attributed to the periods the same way as in project_dora_metrics
+func GetDoraRawValues(
+	db dal.Dal,
+	projectName, environment, periodType string,
+	from, to time.Time,
+) (leadTimes []int64, timesToRestore []int64, err errors.Error) {
+	deployments, changes, incidents, err := loadDoraInputs(db, projectName)
+	if err != nil {
+		return nil, nil, err
+	}
+	leadTimes, timesToRestore = filterDoraRawValues(deployments, changes, incidents, environment, periodType, from, to)
+	return leadTimes, timesToRestore, nil
+}
+
+func filterDoraRawValues(
+	deployments []doraDeployment,
+	changes []doraChange,
+	incidents []doraIncident,
+	environment, periodType string,
+	from, to time.Time,
+) (leadTimes []int64, timesToRestore []int64) {
+	first := GetDoraPeriodStart(periodType, from)
+	inRange := func(date time.Time) bool {
+		start := GetDoraPeriodStart(periodType, date)
+		return !start.Before(first) && !start.After(to)
+	}
+	deploymentsById := make(map[string]doraDeployment, len(deployments))
+	for _, deployment := range deployments {
+		if deployment.Environment == environment {
+			deploymentsById[deployment.Id] = deployment
+		}
+	}
+	for _, change := range changes {
+		if deployment, ok := deploymentsById[change.DeploymentId]; ok && inRange(*deployment.FinishedDate) {
+			leadTimes = append(leadTimes, change.PrCycleTime)
+		}
+	}
+	for _, incident := range incidents {
+		deployment, ok := deploymentsById[incident.DeploymentId]
+		if !ok || incident.ResolutionDate == nil || incident.ResolutionDate.Before(*deployment.FinishedDate) {
+			continue
+		}
+		if inRange(*incident.ResolutionDate) {
+			timesToRestore = append(timesToRestore, int64(incident.ResolutionDate.Sub(*deployment.FinishedDate).Minutes()))
+		}
+	}
+	return leadTimes, timesToRestore
+}
+
+type doraPeriodKey struct {
+	environment string
+	periodType  string
+	periodStart time.Time
+}
+
+type doraPeriod struct {
+	metric          *crossdomain.ProjectDoraMetric
+	deploymentDays  map[time.Time]bool
+	leadTimes       []int64
+	timesToRestore  []int64
+	failedDeployIds map[string]bool
+}
+
+func rollupDoraMetrics(
+	projectName string,
+	deployments []doraDeployment,
+	changes []doraChange,
+	incidents []doraIncident,
+	rolledBackDeploymentIds []string,
+) []*crossdomain.ProjectDoraMetric {
+	periods := make(map[doraPeriodKey]*doraPeriod)
+	var keys []doraPeriodKey
+	getPeriods := func(environment string, date time.Time) []*doraPeriod {
+		var result []*doraPeriod
+		for _, periodType := range []string{crossdomain.DORA_PERIOD_WEEK, crossdomain.DORA_PERIOD_MONTH} {
+			key := doraPeriodKey{environment, periodType, GetDoraPeriodStart(periodType, date)}
+			period, ok := periods[key]
+			if !ok {
+				period = &doraPeriod{
+					metric: &crossdomain.ProjectDoraMetric{
+						ProjectName: projectName,
+						Environment: key.environment,
+						PeriodType:  key.periodType,
+						PeriodStart: key.periodStart,
+					},
+					deploymentDays:  make(map[time.Time]bool),
+					failedDeployIds: make(map[string]bool),
+				}
+				periods[key] = period
+				keys = append(keys, key)
+			}
+			result = append(result, period)
+		}
+		return result
+	}
+
+	deploymentsById := make(map[string]doraDeployment, len(deployments))
+	for _, deployment := range deployments {
+		deploymentsById[deployment.Id] = deployment
+		for _, period := range getPeriods(deployment.Environment, *deployment.FinishedDate) {
+			period.metric.DeploymentCount++
+			period.deploymentDays[GetDoraPeriodStart("", *deployment.FinishedDate)] = true
+		}
+	}
+	for _, change := range changes {
+		deployment, ok := deploymentsById[change.DeploymentId]
+		if !ok {
+			continue
+		}
+		for _, period := range getPeriods(deployment.Environment, *deployment.FinishedDate) {
+			period.metric.ChangeCount++
+			period.leadTimes = append(period.leadTimes, change.PrCycleTime)
+		}
+	}
+	for _, incident := range incidents {
+		deployment, ok := deploymentsById[incident.DeploymentId]
+		if !ok {
+			continue
+		}
+		for _, period := range getPeriods(deployment.Environment, *deployment.FinishedDate) {
+			period.failedDeployIds[deployment.Id] = true
+		}
+		if incident.ResolutionDate == nil || incident.ResolutionDate.Before(*deployment.FinishedDate) {
+			continue
+		}
+		minutes := int64(incident.ResolutionDate.Sub(*deployment.FinishedDate).Minutes())
+		for _, period := range getPeriods(deployment.Environment, *incident.ResolutionDate) {
+			period.metric.IncidentCount++
+			period.timesToRestore = append(period.timesToRestore, minutes)
+		}
+	}
+	for _, deploymentId := range rolledBackDeploymentIds {
+		deployment, ok := deploymentsById[deploymentId]
+		if !ok {
+			continue
+		}
+		for _, period := range getPeriods(deployment.Environment, *deployment.FinishedDate) {
+			period.failedDeployIds[deployment.Id] = true
+		}
+	}
+
+	metrics := make([]*crossdomain.ProjectDoraMetric, 0, len(keys))
+	for _, key := range keys {
+		period := periods[key]
+		metric := period.metric
+		metric.DeploymentDays = len(period.deploymentDays)
+		metric.MedianLeadTimeMinutes = medianInt64(period.leadTimes)
+		metric.MedianTimeToRestoreMinutes = medianInt64(period.timesToRestore)
+		metric.FailedDeploymentCount = len(period.failedDeployIds)
+		if metric.DeploymentCount > 0 {
+			rate := float64(metric.FailedDeploymentCount) / float64(metric.DeploymentCount)
+			metric.ChangeFailureRate = &rate
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+// GetDoraPeriodStart returns the first day of the week (Monday) or month containing the date in UTC, the day
+// itself for any other period type
+func GetDoraPeriodStart(periodType string, date time.Time) time.Time {
+	date = date.UTC()
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch periodType {
+	case crossdomain.DORA_PERIOD_WEEK:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case crossdomain.DORA_PERIOD_MONTH:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// medianInt64 returns the median the same way as the dashboards do: the largest value whose percent rank is no
+// more than 0.5, nil for empty values
+func medianInt64(values []int64) *int64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[(len(sorted)-1)/2]
+	return &median
+}
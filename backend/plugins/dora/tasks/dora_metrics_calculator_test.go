@@ -0,0 +1,167 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollupDoraMetrics(t *testing.T) {
+	date := func(value string) *time.Time {
+		d, err := time.Parse(time.RFC3339, value)
+		assert.Nil(t, err)
+		return &d
+	}
+	deployments := []doraDeployment{
+		// Tuesday and Wednesday of the same week
+		{Id: "d1", Environment: "PRODUCTION", FinishedDate: date("2024-10-01T10:00:00Z")},
+		{Id: "d2", Environment: "PRODUCTION", FinishedDate: date("2024-10-02T10:00:00Z")},
+		{Id: "d3", Environment: "PRODUCTION", FinishedDate: date("2024-10-02T18:00:00Z")},
+		// the week starts in September
+		{Id: "d4", Environment: "PRODUCTION", FinishedDate: date("2024-09-30T10:00:00Z")},
+		{Id: "d5", Environment: "STAGING", FinishedDate: date("2024-10-01T10:00:00Z")},
+	}
+	changes := []doraChange{
+		{DeploymentId: "d1", PrCycleTime: 300},
+		{DeploymentId: "d1", PrCycleTime: 100},
+		{DeploymentId: "d2", PrCycleTime: 200},
+		{DeploymentId: "d3", PrCycleTime: 400},
+		{DeploymentId: "unknown", PrCycleTime: 1},
+	}
+	incidents := []doraIncident{
+		{DeploymentId: "d1", ResolutionDate: date("2024-10-01T12:00:00Z")},
+		{DeploymentId: "d1", ResolutionDate: nil},
+		{DeploymentId: "d2", ResolutionDate: date("2024-10-08T10:00:00Z")},
+	}
+	metrics := make(map[string]*crossdomain.ProjectDoraMetric)
+	for _, metric := range rollupDoraMetrics("p", deployments, changes, incidents, nil) {
+		metrics[metric.Environment+" "+metric.PeriodType+" "+metric.PeriodStart.Format("2006-01-02")] = metric
+	}
+	assert.Len(t, metrics, 6)
+
+	week := metrics["PRODUCTION WEEK 2024-09-30"]
+	assert.Equal(t, 4, week.DeploymentCount)
+	assert.Equal(t, 3, week.DeploymentDays)
+	assert.Equal(t, 4, week.ChangeCount)
+	assert.Equal(t, int64(200), *week.MedianLeadTimeMinutes)
+	assert.Equal(t, 2, week.FailedDeploymentCount)
+	assert.Equal(t, 0.5, *week.ChangeFailureRate)
+	assert.Equal(t, 1, week.IncidentCount)
+	assert.Equal(t, int64(120), *week.MedianTimeToRestoreMinutes)
+
+	// the incident is resolved the week after
+	nextWeek := metrics["PRODUCTION WEEK 2024-10-07"]
+	assert.Equal(t, 0, nextWeek.DeploymentCount)
+	assert.Nil(t, nextWeek.ChangeFailureRate)
+	assert.Equal(t, int64(6*24*60), *nextWeek.MedianTimeToRestoreMinutes)
+
+	september := metrics["PRODUCTION MONTH 2024-09-01"]
+	assert.Equal(t, 1, september.DeploymentCount)
+	assert.Nil(t, september.MedianLeadTimeMinutes)
+	october := metrics["PRODUCTION MONTH 2024-10-01"]
+	assert.Equal(t, 3, october.DeploymentCount)
+	assert.Equal(t, 2, october.DeploymentDays)
+	assert.Equal(t, 2, october.IncidentCount)
+
+	assert.Equal(t, 1, metrics["STAGING WEEK 2024-09-30"].DeploymentCount)
+
+	// d3 is rolled back, d1 is already failed by its incident and unknown deployments are ignored
+	metrics = make(map[string]*crossdomain.ProjectDoraMetric)
+	for _, metric := range rollupDoraMetrics("p", deployments, changes, incidents, []string{"d1", "d3", "unknown"}) {
+		metrics[metric.Environment+" "+metric.PeriodType+" "+metric.PeriodStart.Format("2006-01-02")] = metric
+	}
+	week = metrics["PRODUCTION WEEK 2024-09-30"]
+	assert.Equal(t, 3, week.FailedDeploymentCount)
+	assert.Equal(t, 0.75, *week.ChangeFailureRate)
+	assert.Equal(t, 1, week.IncidentCount)
+}
+
+func TestFilterDoraRawValues(t *testing.T) {
+	date := func(value string) *time.Time {
+		d, err := time.Parse(time.RFC3339, value)
+		assert.Nil(t, err)
+		return &d
+	}
+	deployments := []doraDeployment{
+		{Id: "d1", Environment: "PRODUCTION", FinishedDate: date("2024-09-30T10:00:00Z")},
+		{Id: "d2", Environment: "PRODUCTION", FinishedDate: date("2024-10-15T10:00:00Z")},
+		{Id: "d3", Environment: "PRODUCTION", FinishedDate: date("2024-11-01T10:00:00Z")},
+		{Id: "d4", Environment: "STAGING", FinishedDate: date("2024-10-15T10:00:00Z")},
+	}
+	changes := []doraChange{
+		{DeploymentId: "d1", PrCycleTime: 100},
+		{DeploymentId: "d2", PrCycleTime: 200},
+		{DeploymentId: "d2", PrCycleTime: 300},
+		{DeploymentId: "d3", PrCycleTime: 400},
+		{DeploymentId: "d4", PrCycleTime: 500},
+	}
+	incidents := []doraIncident{
+		// caused in September and resolved in October
+		{DeploymentId: "d1", ResolutionDate: date("2024-10-01T10:00:00Z")},
+		{DeploymentId: "d2", ResolutionDate: date("2024-10-15T11:00:00Z")},
+		{DeploymentId: "d2", ResolutionDate: nil},
+		{DeploymentId: "d4", ResolutionDate: date("2024-10-15T12:00:00Z")},
+	}
+	leadTimes, timesToRestore := filterDoraRawValues(
+		deployments, changes, incidents, "PRODUCTION", crossdomain.DORA_PERIOD_MONTH,
+		*date("2024-10-10T00:00:00Z"), *date("2024-10-31T00:00:00Z"),
+	)
+	assert.Equal(t, []int64{200, 300}, leadTimes)
+	assert.Equal(t, []int64{24 * 60, 60}, timesToRestore)
+
+	// the range starts with the week of the 30th of September, and ends with the one of the 28th of October
+	leadTimes, _ = filterDoraRawValues(
+		deployments, changes, incidents, "PRODUCTION", crossdomain.DORA_PERIOD_WEEK,
+		*date("2024-10-01T00:00:00Z"), *date("2024-10-31T00:00:00Z"),
+	)
+	assert.Equal(t, []int64{100, 200, 300, 400}, leadTimes)
+}
+
+func TestDoraBenchmark(t *testing.T) {
+	minutes := func(v int64) *int64 { return &v }
+	ratio := func(v float64) *float64 { return &v }
+	_, err := NewDoraBenchmark("2022")
+	assert.NotNil(t, err)
+
+	b2023, err := NewDoraBenchmark("")
+	assert.Nil(t, err)
+	assert.Equal(t, DORA_REPORT_2023, b2023.Report())
+	assert.Equal(t, DORA_LEVEL_ELITE, b2023.DeploymentFrequency(ratio(7), ratio(30), nil))
+	assert.Equal(t, DORA_LEVEL_HIGH, b2023.DeploymentFrequency(ratio(1), ratio(4), nil))
+	assert.Equal(t, DORA_LEVEL_MEDIUM, b2023.DeploymentFrequency(ratio(0), ratio(2), nil))
+	assert.Equal(t, DORA_LEVEL_LOW, b2023.DeploymentFrequency(ratio(0), ratio(0), nil))
+	assert.Equal(t, "", b2023.DeploymentFrequency(nil, nil, nil))
+	assert.Equal(t, DORA_LEVEL_ELITE, b2023.LeadTime(minutes(23*60)))
+	assert.Equal(t, DORA_LEVEL_MEDIUM, b2023.LeadTime(minutes(8*24*60)))
+	assert.Equal(t, DORA_LEVEL_HIGH, b2023.ChangeFailureRate(ratio(.1)))
+	assert.Equal(t, DORA_LEVEL_LOW, b2023.ChangeFailureRate(ratio(.2)))
+	assert.Equal(t, DORA_LEVEL_HIGH, b2023.TimeToRestore(minutes(60)))
+	assert.Equal(t, "", b2023.TimeToRestore(nil))
+
+	b2021, err := NewDoraBenchmark(DORA_REPORT_2021)
+	assert.Nil(t, err)
+	assert.Equal(t, DORA_LEVEL_HIGH, b2021.DeploymentFrequency(ratio(1), ratio(4), ratio(24)))
+	assert.Equal(t, DORA_LEVEL_MEDIUM, b2021.DeploymentFrequency(ratio(0), ratio(0), ratio(2)))
+	assert.Equal(t, DORA_LEVEL_HIGH, b2021.LeadTime(minutes(23*60)))
+	assert.Equal(t, DORA_LEVEL_ELITE, b2021.ChangeFailureRate(ratio(.15)))
+	assert.Equal(t, DORA_LEVEL_MEDIUM, b2021.ChangeFailureRate(ratio(.25)))
+}
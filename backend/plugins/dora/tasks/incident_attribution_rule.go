@@ -0,0 +1,124 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+)
+
+type incidentAttributionRule struct {
+	component    *regexp.Regexp
+	label        *regexp.Regexp
+	service      *regexp.Regexp
+	cicdScopeIds []string
+	repoUrls     []string
+}
+
+func compileIncidentAttributionRules(rules []IncidentAttributionRule) ([]*incidentAttributionRule, errors.Error) {
+	compiled := make([]*incidentAttributionRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Component == "" && rule.Label == "" && rule.Service == "" {
+			return nil, errors.BadInput.New(fmt.Sprintf("incident attribution rule #%d matches no component, label or service", i))
+		}
+		if len(rule.CicdScopeIds) == 0 && len(rule.RepoUrls) == 0 {
+			return nil, errors.BadInput.New(fmt.Sprintf("incident attribution rule #%d targets no cicd scope or repo", i))
+		}
+		c := &incidentAttributionRule{cicdScopeIds: rule.CicdScopeIds, repoUrls: rule.RepoUrls}
+		var err error
+		for _, pattern := range []struct {
+			name  string
+			value string
+			dest  **regexp.Regexp
+		}{
+			{"component", rule.Component, &c.component},
+			{"label", rule.Label, &c.label},
+			{"service", rule.Service, &c.service},
+		} {
+			if pattern.value == "" {
+				continue
+			}
+			*pattern.dest, err = regexp.Compile(pattern.value)
+			if err != nil {
+				return nil, errors.BadInput.Wrap(err, fmt.Sprintf("invalid %s pattern in incident attribution rule #%d", pattern.name, i))
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matchIncidentAttributionRule returns the first rule matching the incident, nil if there is none
+func matchIncidentAttributionRule(
+	rules []*incidentAttributionRule,
+	issue *ticket.Issue,
+	labels []string,
+	boardId string,
+) *incidentAttributionRule {
+	for _, rule := range rules {
+		if rule.matches(issue, labels, boardId) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (r *incidentAttributionRule) matches(issue *ticket.Issue, labels []string, boardId string) bool {
+	if r.component != nil && !r.component.MatchString(issue.Component) {
+		return false
+	}
+	if r.label != nil {
+		matched := false
+		for _, label := range labels {
+			if r.label.MatchString(label) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.service != nil {
+		serviceId := boardId[strings.LastIndex(boardId, ":")+1:]
+		if !r.service.MatchString(boardId) && !r.service.MatchString(serviceId) {
+			return false
+		}
+	}
+	return true
+}
+
+// deploymentClause restricts cicd_deployment_commits to the cicd scopes or repos of the rule
+func (r *incidentAttributionRule) deploymentClause() dal.Clause {
+	var conditions []string
+	var params []interface{}
+	if len(r.cicdScopeIds) > 0 {
+		conditions = append(conditions, "cicd_deployment_commits.cicd_scope_id IN ?")
+		params = append(params, r.cicdScopeIds)
+	}
+	if len(r.repoUrls) > 0 {
+		conditions = append(conditions, "cicd_deployment_commits.repo_url IN ?")
+		params = append(params, r.repoUrls)
+	}
+	return dal.Where("("+strings.Join(conditions, " OR ")+")", params...)
+}
@@ -0,0 +1,56 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncidentAttributionRules(t *testing.T) {
+	rules, err := compileIncidentAttributionRules([]IncidentAttributionRule{
+		{Component: "^payments$", Label: "^sev[12]$", CicdScopeIds: []string{"github:GithubRepo:1:1"}},
+		{Service: "^PCHECKOUT$", RepoUrls: []string{"https://github.com/org/checkout"}},
+		{Label: "(?i)^frontend$", CicdScopeIds: []string{"gitlab:GitlabProject:1:2"}, RepoUrls: []string{"https://gitlab.com/org/web"}},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, rules, 3)
+
+	match := func(component string, labels []string, boardId string) *incidentAttributionRule {
+		return matchIncidentAttributionRule(rules, &ticket.Issue{Component: component}, labels, boardId)
+	}
+	// all patterns of a rule must match
+	assert.Equal(t, rules[0], match("payments", []string{"bug", "sev1"}, ""))
+	assert.Nil(t, match("payments", []string{"sev3"}, ""))
+	// the service matches either the board id or the original service id
+	assert.Equal(t, rules[1], match("", nil, "pagerduty:Service:1:PCHECKOUT"))
+	assert.Equal(t, rules[1], match("", nil, "PCHECKOUT"))
+	assert.Nil(t, match("", nil, "opsgenie:Service:1:PCHECKOUT2"))
+	// the first matching rule wins
+	assert.Equal(t, rules[0], match("payments", []string{"sev2", "Frontend"}, "pagerduty:Service:1:PCHECKOUT"))
+	assert.Equal(t, rules[2], match("", []string{"Frontend"}, ""))
+
+	_, err = compileIncidentAttributionRules([]IncidentAttributionRule{{CicdScopeIds: []string{"s"}}})
+	assert.NotNil(t, err)
+	_, err = compileIncidentAttributionRules([]IncidentAttributionRule{{Component: "c"}})
+	assert.NotNil(t, err)
+	_, err = compileIncidentAttributionRules([]IncidentAttributionRule{{Label: "(", RepoUrls: []string{"r"}}})
+	assert.NotNil(t, err)
+}
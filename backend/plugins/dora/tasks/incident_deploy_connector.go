@@ -37,6 +37,14 @@ var ConnectIncidentToDeploymentMeta = plugin.SubTaskMeta{
 	EnabledByDefault: true,
 	Description:      "Connect incident issue to deployment",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CICD},
+	DependencyTables: []string{
+		ticket.Issue{}.TableName(),
+		ticket.BoardIssue{}.TableName(),
+		ticket.IssueLabel{}.TableName(),
+		devops.CicdDeploymentCommit{}.TableName(),
+		crossdomain.ProjectMapping{}.TableName(),
+	},
+	ProductTables: []string{crossdomain.ProjectIssueMetric{}.TableName()},
 }
 
 type simpleCicdDeploymentCommit struct {
@@ -44,16 +52,52 @@ type simpleCicdDeploymentCommit struct {
 	FinishedDate *time.Time
 }
 
+type incidentIssue struct {
+	ticket.Issue
+	BoardId string
+}
+
+// ConnectIncidentToDeployment attributes each incident to the latest successful production deployment before it.
+// An incident matching one of the incident attribution rules is only attributed to the deployments of the cicd
+// scopes or repos of the rule, and left unattributed if there is none.
 func ConnectIncidentToDeployment(taskCtx plugin.SubTaskContext) errors.Error {
 	db := taskCtx.GetDal()
 	data := taskCtx.GetData().(*DoraTaskData)
+	rules, err := compileIncidentAttributionRules(data.Options.IncidentAttributionRules)
+	if err != nil {
+		return err
+	}
 	// Clear previous results from the project
-	err := db.Exec("DELETE FROM project_issue_metrics WHERE project_name = ?", data.Options.ProjectName)
+	err = db.Exec("DELETE FROM project_issue_metrics WHERE project_name = ?", data.Options.ProjectName)
 	if err != nil {
 		return errors.Default.Wrap(err, "error deleting previous project_issue_metrics")
 	}
+	// labels are only needed to match the rules
+	issueLabels := make(map[string][]string)
+	if len(rules) > 0 {
+		var labels []ticket.IssueLabel
+		err = db.All(
+			&labels,
+			dal.Select("il.*"),
+			dal.From("issue_labels il"),
+			dal.Join("JOIN issues i ON i.id = il.issue_id"),
+			dal.Join("JOIN board_issues bi ON bi.issue_id = i.id"),
+			dal.Join("JOIN project_mapping pm ON pm.row_id = bi.board_id"),
+			dal.Where(
+				"i.type = ? and pm.project_name = ? and pm.table = ?",
+				ticket.INCIDENT, data.Options.ProjectName, "boards",
+			),
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "error loading incident labels")
+		}
+		for _, label := range labels {
+			issueLabels[label.IssueId] = append(issueLabels[label.IssueId], label.LabelName)
+		}
+	}
 	// select all issues belongs to the board
 	clauses := []dal.Clause{
+		dal.Select("i.*, bi.board_id"),
 		dal.From(`issues i`),
 		dal.Join(`left join board_issues bi on bi.issue_id = i.id`),
 		dal.Join(`left join project_mapping pm on pm.row_id = bi.board_id`),
@@ -76,10 +120,11 @@ func ConnectIncidentToDeployment(taskCtx plugin.SubTaskContext) errors.Error {
 			},
 			Table: "issues",
 		},
-		InputRowType: reflect.TypeOf(ticket.Issue{}),
+		InputRowType: reflect.TypeOf(incidentIssue{}),
 		Input:        cursor,
 		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
-			issue := inputRow.(*ticket.Issue)
+			incident := inputRow.(*incidentIssue)
+			issue := &incident.Issue
 			projectIssueMetric := &crossdomain.ProjectIssueMetric{
 				DomainEntity: domainlayer.DomainEntity{
 					Id: issue.Id,
@@ -103,6 +148,10 @@ func ConnectIncidentToDeployment(taskCtx plugin.SubTaskContext) errors.Error {
 				dal.Orderby("finished_date DESC"),
 				dal.Limit(1),
 			}
+			rule := matchIncidentAttributionRule(rules, issue, issueLabels[issue.Id], incident.BoardId)
+			if rule != nil {
+				cicdDeploymentCommitClauses = append(cicdDeploymentCommitClauses, rule.deploymentClause())
+			}
 
 			scdc := &simpleCicdDeploymentCommit{}
 			err = db.All(scdc, cicdDeploymentCommitClauses...)
@@ -0,0 +1,441 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+var CalculatePrReviewMetricsMeta = plugin.SubTaskMeta{
+	Name:             "calculatePrReviewMetrics",
+	EntryPoint:       CalculatePrReviewMetrics,
+	EnabledByDefault: true,
+	Description:      "Calculate review rounds, approvals and response times of pull requests and the weekly load of reviewers",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_CODE_REVIEW},
+	DependencyTables: []string{
+		code.PullRequest{}.TableName(),
+		code.PullRequestReviewer{}.TableName(),
+		code.PullRequestComment{}.TableName(),
+		code.PullRequestCommit{}.TableName(),
+		crossdomain.ProjectMapping{}.TableName(),
+	},
+	ProductTables: []string{
+		crossdomain.ProjectPrReviewMetric{}.TableName(),
+		crossdomain.ProjectPrReviewerMetric{}.TableName(),
+		crossdomain.ProjectReviewerWeeklyMetric{}.TableName(),
+	},
+}
+
+// approvals are stored as comments or reviews with the APPROVED status, the bitbucket and azuredevops plugins
+// convert the approvals and votes of reviewers into such reviews
+const reviewStatusApproved = "APPROVED"
+
+type reviewComment struct {
+	PullRequestId string
+	AccountId     string
+	CreatedDate   time.Time
+	Status        string
+}
+
+type prReviewerRow struct {
+	PullRequestId string
+	ReviewerId    string
+}
+
+type prCommitRow struct {
+	PullRequestId      string
+	CommitAuthoredDate time.Time
+}
+
+// prReview keeps what the weekly rollup needs of a pull request once its metrics are calculated
+type prReview struct {
+	pullRequestId string
+	createdDate   time.Time
+	reviewers     []*crossdomain.ProjectPrReviewerMetric
+	comments      []reviewComment
+}
+
+// prChildRows walks a cursor over rows of the project's pull requests sorted by pull request id alongside the
+// pull requests cursor, so the rows of each pull request are loaded without a query per pull request
+type prChildRows[T any] struct {
+	db            dal.Dal
+	rows          dal.Rows
+	pullRequestId func(*T) string
+	next          *T
+}
+
+func newPrChildRows[T any](db dal.Dal, pullRequestId func(*T) string, clauses ...dal.Clause) (*prChildRows[T], errors.Error) {
+	rows, err := db.Cursor(clauses...)
+	if err != nil {
+		return nil, err
+	}
+	return &prChildRows[T]{db: db, rows: rows, pullRequestId: pullRequestId}, nil
+}
+
+// take returns the rows of the pull request, both cursors must be sorted the same way so rows of pull
+// requests coming before it have been taken already
+func (r *prChildRows[T]) take(pullRequestId string) ([]T, errors.Error) {
+	var result []T
+	for {
+		if r.next == nil {
+			if !r.rows.Next() {
+				return result, nil
+			}
+			r.next = new(T)
+			err := r.db.Fetch(r.rows, r.next)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if r.pullRequestId(r.next) != pullRequestId {
+			return result, nil
+		}
+		result = append(result, *r.next)
+		r.next = nil
+	}
+}
+
+func (r *prChildRows[T]) Close() {
+	r.rows.Close()
+}
+
+// CalculatePrReviewMetrics works on the domain layer only, so it covers every data source filling
+// pull_request_comments and pull_request_commits; reviewers who were never requested through
+// pull_request_reviewers are picked up from their comments
+func CalculatePrReviewMetrics(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*DoraTaskData)
+	projectName := data.Options.ProjectName
+
+	// Clear previous results from the project
+	for _, table := range []dal.Tabler{
+		&crossdomain.ProjectPrReviewMetric{},
+		&crossdomain.ProjectPrReviewerMetric{},
+		&crossdomain.ProjectReviewerWeeklyMetric{},
+	} {
+		err := db.Delete(table, dal.Where("project_name = ?", projectName))
+		if err != nil {
+			return errors.Default.Wrap(err, "error deleting previous "+table.TableName())
+		}
+	}
+
+	projectPrs := []dal.Clause{
+		dal.Join("JOIN project_mapping pm ON (pm.row_id = pr.base_repo_id)"),
+		dal.Where("pm.project_name = ? AND pm.table = 'repos'", projectName),
+	}
+	cursor, err := db.Cursor(append([]dal.Clause{
+		dal.Select("pr.id, pr.author_id, pr.created_date, pr.merged_date"),
+		dal.From("pull_requests pr"),
+		dal.Orderby("pr.id"),
+	}, projectPrs...)...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	reviewerRows, err := newPrChildRows(db, func(row *prReviewerRow) string { return row.PullRequestId }, append([]dal.Clause{
+		dal.Select("r.pull_request_id, r.reviewer_id"),
+		dal.From("pull_request_reviewers r"),
+		dal.Join("JOIN pull_requests pr ON (pr.id = r.pull_request_id)"),
+		dal.Orderby("r.pull_request_id"),
+	}, projectPrs...)...)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading pull_request_reviewers")
+	}
+	defer reviewerRows.Close()
+	commentRows, err := newPrChildRows(db, func(row *reviewComment) string { return row.PullRequestId }, append([]dal.Clause{
+		dal.Select("c.pull_request_id, c.account_id, c.created_date, c.status"),
+		dal.From("pull_request_comments c"),
+		dal.Join("JOIN pull_requests pr ON (pr.id = c.pull_request_id)"),
+		dal.Orderby("c.pull_request_id, c.created_date ASC"),
+	}, projectPrs...)...)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading pull_request_comments")
+	}
+	defer commentRows.Close()
+	commitRows, err := newPrChildRows(db, func(row *prCommitRow) string { return row.PullRequestId }, append([]dal.Clause{
+		dal.Select("c.pull_request_id, c.commit_authored_date"),
+		dal.From("pull_request_commits c"),
+		dal.Join("JOIN pull_requests pr ON (pr.id = c.pull_request_id)"),
+		dal.Orderby("c.pull_request_id"),
+	}, projectPrs...)...)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading pull_request_commits")
+	}
+	defer commitRows.Close()
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	rollup := newReviewerWeeklyRollup(projectName)
+	ctx := taskCtx.GetContext()
+	taskCtx.SetProgress(0, -1)
+	for cursor.Next() {
+		select {
+		case <-ctx.Done():
+			return errors.Convert(ctx.Err())
+		default:
+		}
+		pr := &code.PullRequest{}
+		err = db.Fetch(cursor, pr)
+		if err != nil {
+			return errors.Default.Wrap(err, "error fetching pull request")
+		}
+		reviewers, err := reviewerRows.take(pr.Id)
+		if err != nil {
+			return errors.Default.Wrap(err, "error fetching pull_request_reviewers")
+		}
+		requested := make([]string, len(reviewers))
+		for i, reviewer := range reviewers {
+			requested[i] = reviewer.ReviewerId
+		}
+		comments, err := commentRows.take(pr.Id)
+		if err != nil {
+			return errors.Default.Wrap(err, "error fetching pull_request_comments")
+		}
+		commits, err := commitRows.take(pr.Id)
+		if err != nil {
+			return errors.Default.Wrap(err, "error fetching pull_request_commits")
+		}
+		commitDates := make([]time.Time, len(commits))
+		for i, commit := range commits {
+			commitDates[i] = commit.CommitAuthoredDate
+		}
+
+		review, metric := computePrReviewMetrics(projectName, pr, requested, comments, commitDates)
+		rollup.add(review)
+		for _, row := range append([]interface{}{metric}, toInterfaces(review.reviewers)...) {
+			batch, err := divider.ForType(reflect.TypeOf(row))
+			if err != nil {
+				return err
+			}
+			err = batch.Add(row)
+			if err != nil {
+				return err
+			}
+		}
+		taskCtx.IncProgress(1)
+	}
+	for _, weekly := range rollup.metrics() {
+		batch, err := divider.ForType(reflect.TypeOf(weekly))
+		if err != nil {
+			return err
+		}
+		err = batch.Add(weekly)
+		if err != nil {
+			return err
+		}
+	}
+	return divider.Close()
+}
+
+func toInterfaces(reviewers []*crossdomain.ProjectPrReviewerMetric) []interface{} {
+	rows := make([]interface{}, len(reviewers))
+	for i, reviewer := range reviewers {
+		rows[i] = reviewer
+	}
+	return rows
+}
+
+// computePrReviewMetrics calculates the review metrics of the pull request and of each of its reviewers from
+// the requested reviewers, the comments sorted by created date and the authored dates of the commits
+func computePrReviewMetrics(
+	projectName string,
+	pr *code.PullRequest,
+	requested []string,
+	comments []reviewComment,
+	commitDates []time.Time,
+) (*prReview, *crossdomain.ProjectPrReviewMetric) {
+	review := &prReview{pullRequestId: pr.Id, createdDate: pr.CreatedDate}
+	metric := &crossdomain.ProjectPrReviewMetric{
+		DomainEntity: domainlayer.DomainEntity{Id: pr.Id},
+		ProjectName:  projectName,
+	}
+	reviewers := make(map[string]*crossdomain.ProjectPrReviewerMetric)
+	getReviewer := func(reviewerId string) *crossdomain.ProjectPrReviewerMetric {
+		reviewer, ok := reviewers[reviewerId]
+		if !ok {
+			reviewer = &crossdomain.ProjectPrReviewerMetric{
+				ProjectName:   projectName,
+				PullRequestId: pr.Id,
+				ReviewerId:    reviewerId,
+			}
+			reviewers[reviewerId] = reviewer
+			review.reviewers = append(review.reviewers, reviewer)
+		}
+		return reviewer
+	}
+	for _, reviewerId := range requested {
+		getReviewer(reviewerId).Requested = true
+	}
+
+	approvers := make(map[string]bool)
+	var reviewsBeforeMerge []reviewComment
+	for _, comment := range comments {
+		// comments of the author are answers, not reviews
+		if comment.AccountId == "" || comment.AccountId == pr.AuthorId {
+			continue
+		}
+		review.comments = append(review.comments, comment)
+		metric.CommentCount++
+		if metric.FirstReviewDate == nil {
+			firstReviewDate := comment.CreatedDate
+			metric.FirstReviewDate = &firstReviewDate
+		}
+		beforeMerge := pr.MergedDate == nil || !comment.CreatedDate.After(*pr.MergedDate)
+		if beforeMerge {
+			reviewsBeforeMerge = append(reviewsBeforeMerge, comment)
+		}
+
+		reviewer := getReviewer(comment.AccountId)
+		reviewer.CommentCount++
+		if reviewer.FirstResponseDate == nil {
+			firstResponseDate := comment.CreatedDate
+			responseTime := int64(firstResponseDate.Sub(pr.CreatedDate).Minutes())
+			reviewer.FirstResponseDate = &firstResponseDate
+			reviewer.ResponseTimeMinutes = &responseTime
+		}
+		if comment.Status == reviewStatusApproved {
+			reviewer.Approved = true
+			if beforeMerge {
+				approvers[comment.AccountId] = true
+			}
+		}
+	}
+	for _, reviewer := range review.reviewers {
+		if reviewer.CommentCount > 0 {
+			metric.ReviewerCount++
+		}
+	}
+	metric.ApprovalsBeforeMerge = len(approvers)
+	metric.MergedWithoutReview = pr.MergedDate != nil && len(reviewsBeforeMerge) == 0
+	// comments left after the merge do not start a review round
+	metric.ReviewRounds, metric.CommitsAfterFirstReview = countReviewRounds(reviewsBeforeMerge, commitDates)
+	return review, metric
+}
+
+// countReviewRounds walks through the reviews and the commits in chronological order, a review following
+// commits pushed after the previous review starts a new round
+func countReviewRounds(reviews []reviewComment, commitDates []time.Time) (rounds int, commitsAfterFirstReview int) {
+	commits := append([]time.Time(nil), commitDates...)
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Before(commits[j]) })
+	pushedSinceReview := false
+	i := 0
+	for _, review := range reviews {
+		for ; i < len(commits) && !commits[i].After(review.CreatedDate); i++ {
+			if rounds > 0 {
+				pushedSinceReview = true
+				commitsAfterFirstReview++
+			}
+		}
+		if rounds == 0 || pushedSinceReview {
+			rounds++
+			pushedSinceReview = false
+		}
+	}
+	if rounds > 0 {
+		commitsAfterFirstReview += len(commits) - i
+	}
+	return
+}
+
+type reviewerWeekKey struct {
+	reviewerId string
+	weekStart  time.Time
+}
+
+type reviewerWeek struct {
+	metric        *crossdomain.ProjectReviewerWeeklyMetric
+	responseTimes []int64
+}
+
+// reviewerWeeklyRollup counts the requests, comments, approvals and first responses of each reviewer by
+// the week they happened in, pull requests are added one by one so only the weekly counters are kept
+type reviewerWeeklyRollup struct {
+	projectName string
+	weeks       map[reviewerWeekKey]*reviewerWeek
+	keys        []reviewerWeekKey
+}
+
+func newReviewerWeeklyRollup(projectName string) *reviewerWeeklyRollup {
+	return &reviewerWeeklyRollup{
+		projectName: projectName,
+		weeks:       make(map[reviewerWeekKey]*reviewerWeek),
+	}
+}
+
+func (r *reviewerWeeklyRollup) getWeek(key reviewerWeekKey) *reviewerWeek {
+	week, ok := r.weeks[key]
+	if !ok {
+		week = &reviewerWeek{
+			metric: &crossdomain.ProjectReviewerWeeklyMetric{
+				ProjectName: r.projectName,
+				ReviewerId:  key.reviewerId,
+				WeekStart:   key.weekStart,
+			},
+		}
+		r.weeks[key] = week
+		r.keys = append(r.keys, key)
+	}
+	return week
+}
+
+func (r *reviewerWeeklyRollup) add(review *prReview) {
+	weekOf := func(reviewerId string, date time.Time) reviewerWeekKey {
+		return reviewerWeekKey{reviewerId, GetDoraPeriodStart(crossdomain.DORA_PERIOD_WEEK, date)}
+	}
+	for _, reviewer := range review.reviewers {
+		if reviewer.Requested {
+			r.getWeek(weekOf(reviewer.ReviewerId, review.createdDate)).metric.RequestedPrCount++
+		}
+		if reviewer.FirstResponseDate != nil {
+			week := r.getWeek(weekOf(reviewer.ReviewerId, *reviewer.FirstResponseDate))
+			week.responseTimes = append(week.responseTimes, *reviewer.ResponseTimeMinutes)
+		}
+	}
+	reviewed := make(map[reviewerWeekKey]bool)
+	for _, comment := range review.comments {
+		key := weekOf(comment.AccountId, comment.CreatedDate)
+		week := r.getWeek(key)
+		week.metric.CommentCount++
+		if !reviewed[key] {
+			reviewed[key] = true
+			week.metric.ReviewedPrCount++
+		}
+		if comment.Status == reviewStatusApproved {
+			week.metric.ApprovalCount++
+		}
+	}
+}
+
+func (r *reviewerWeeklyRollup) metrics() []*crossdomain.ProjectReviewerWeeklyMetric {
+	metrics := make([]*crossdomain.ProjectReviewerWeeklyMetric, 0, len(r.keys))
+	for _, key := range r.keys {
+		week := r.weeks[key]
+		week.metric.MedianResponseTimeMinutes = medianInt64(week.responseTimes)
+		metrics = append(metrics, week.metric)
+	}
+	return metrics
+}
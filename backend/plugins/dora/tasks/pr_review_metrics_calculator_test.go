@@ -0,0 +1,109 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputePrReviewMetrics(t *testing.T) {
+	date := func(value string) time.Time {
+		d, err := time.Parse(time.RFC3339, value)
+		assert.Nil(t, err)
+		return d
+	}
+	mergedDate := date("2024-10-03T12:00:00Z")
+	pr := &code.PullRequest{
+		DomainEntity: domainlayer.DomainEntity{Id: "pr1"},
+		AuthorId:     "author",
+		CreatedDate:  date("2024-10-01T10:00:00Z"),
+		MergedDate:   &mergedDate,
+	}
+	comments := []reviewComment{
+		{AccountId: "alice", CreatedDate: date("2024-10-01T11:00:00Z")},
+		{AccountId: "alice", CreatedDate: date("2024-10-01T11:05:00Z")},
+		{AccountId: "author", CreatedDate: date("2024-10-01T12:00:00Z")},
+		{AccountId: "bob", CreatedDate: date("2024-10-02T10:00:00Z"), Status: reviewStatusApproved},
+		{AccountId: "alice", CreatedDate: date("2024-10-03T10:00:00Z"), Status: reviewStatusApproved},
+		// approving after the merge does not count
+		{AccountId: "carol", CreatedDate: date("2024-10-07T10:00:00Z"), Status: reviewStatusApproved},
+	}
+	commitDates := []time.Time{
+		date("2024-10-02T09:00:00Z"),
+		date("2024-10-01T09:00:00Z"),
+		date("2024-10-02T08:00:00Z"),
+		date("2024-10-03T11:00:00Z"),
+	}
+	review, metric := computePrReviewMetrics("p", pr, []string{"bob", "dave"}, comments, commitDates)
+
+	assert.Equal(t, "pr1", metric.Id)
+	assert.Equal(t, 3, metric.ReviewerCount)
+	assert.Equal(t, 5, metric.CommentCount)
+	// alice reviews, 2 commits are pushed, bob reviews, then alice approves without any new commit in between
+	assert.Equal(t, 2, metric.ReviewRounds)
+	assert.Equal(t, 3, metric.CommitsAfterFirstReview)
+	assert.Equal(t, 2, metric.ApprovalsBeforeMerge)
+	assert.Equal(t, date("2024-10-01T11:00:00Z"), *metric.FirstReviewDate)
+	assert.False(t, metric.MergedWithoutReview)
+
+	reviewers := make(map[string]int)
+	for i, reviewer := range review.reviewers {
+		reviewers[reviewer.ReviewerId] = i
+	}
+	assert.Len(t, reviewers, 4)
+	bob := review.reviewers[reviewers["bob"]]
+	assert.True(t, bob.Requested)
+	assert.True(t, bob.Approved)
+	assert.Equal(t, int64(24*60), *bob.ResponseTimeMinutes)
+	alice := review.reviewers[reviewers["alice"]]
+	assert.False(t, alice.Requested)
+	assert.Equal(t, 3, alice.CommentCount)
+	assert.Equal(t, int64(60), *alice.ResponseTimeMinutes)
+	dave := review.reviewers[reviewers["dave"]]
+	assert.Equal(t, 0, dave.CommentCount)
+	assert.Nil(t, dave.FirstResponseDate)
+
+	weekly := make(map[string]int)
+	rollup := newReviewerWeeklyRollup("p")
+	rollup.add(review)
+	metrics := rollup.metrics()
+	for i, m := range metrics {
+		weekly[m.ReviewerId+" "+m.WeekStart.Format("2006-01-02")] = i
+	}
+	assert.Len(t, metrics, 4)
+	aliceWeek := metrics[weekly["alice 2024-09-30"]]
+	assert.Equal(t, 3, aliceWeek.CommentCount)
+	assert.Equal(t, 1, aliceWeek.ReviewedPrCount)
+	assert.Equal(t, 1, aliceWeek.ApprovalCount)
+	assert.Equal(t, int64(60), *aliceWeek.MedianResponseTimeMinutes)
+	daveWeek := metrics[weekly["dave 2024-09-30"]]
+	assert.Equal(t, 1, daveWeek.RequestedPrCount)
+	assert.Nil(t, daveWeek.MedianResponseTimeMinutes)
+	carolWeek := metrics[weekly["carol 2024-10-07"]]
+	assert.Equal(t, 1, carolWeek.ApprovalCount)
+
+	// only the author commented before the merge
+	_, metric = computePrReviewMetrics("p", pr, nil, comments[2:3], nil)
+	assert.True(t, metric.MergedWithoutReview)
+	assert.Equal(t, 0, metric.ReviewRounds)
+}
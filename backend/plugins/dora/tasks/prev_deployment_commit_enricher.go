@@ -20,6 +20,7 @@ package tasks
 import (
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
@@ -31,6 +32,8 @@ var EnrichPrevSuccessDeploymentCommitMeta = plugin.SubTaskMeta{
 	EnabledByDefault: false,
 	Description:      "filling the prev_success_deployment_commit_id for cicd_deployment_commits table",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
+	DependencyTables: []string{devops.CicdDeploymentCommit{}.TableName(), crossdomain.ProjectMapping{}.TableName()},
+	ProductTables:    []string{devops.CicdDeploymentCommit{}.TableName()},
 }
 
 // EnrichPrevSuccessDeploymentCommit
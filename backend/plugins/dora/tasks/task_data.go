@@ -31,6 +31,30 @@ type DoraOptions struct {
 	Since       string
 	ProjectName string  `json:"projectName"`
 	ScopeId     *string `json:"scopeId,omitempty"`
+	// HotfixBranchPattern and HotfixTitlePattern are regular expressions matched against the ref name and the
+	// display title of a deployment, a deployment matching either one is classified as a hotfix
+	HotfixBranchPattern string `json:"hotfixBranchPattern,omitempty"`
+	HotfixTitlePattern  string `json:"hotfixTitlePattern,omitempty"`
+	// RollbackAsChangeFailure counts the deployment undone by a rollback as a failed deployment even when no
+	// incident is linked to it
+	RollbackAsChangeFailure bool `json:"rollbackAsChangeFailure,omitempty"`
+	// IncidentAttributionRules narrow down the deployments an incident may be attributed to, incidents matching
+	// none of them are attributed to the latest production deployment of the project
+	IncidentAttributionRules []IncidentAttributionRule `json:"incidentAttributionRules,omitempty"`
+}
+
+// IncidentAttributionRule attributes the incidents matching all of its non-empty patterns to the latest
+// production deployment of the given cicd scopes or repos
+type IncidentAttributionRule struct {
+	// Component, Label and Service are regular expressions matched against the component of the incident, any
+	// of its labels and the service it belongs to, either the board id (e.g. pagerduty:Service:1:PABC123) or the
+	// original service id (e.g. PABC123)
+	Component string `json:"component,omitempty"`
+	Label     string `json:"label,omitempty"`
+	Service   string `json:"service,omitempty"`
+	// CicdScopeIds and RepoUrls are the deployments' cicd_scope_id and repo_url the incident is attributed to
+	CicdScopeIds []string `json:"cicdScopeIds,omitempty"`
+	RepoUrls     []string `json:"repoUrls,omitempty"`
 }
 
 type DoraTaskData struct {
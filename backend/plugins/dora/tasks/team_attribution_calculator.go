@@ -0,0 +1,179 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+var CalculateTeamAttributionsMeta = plugin.SubTaskMeta{
+	Name:             "calculateTeamAttributions",
+	EntryPoint:       CalculateTeamAttributions,
+	EnabledByDefault: true,
+	Description:      "Attribute the commits, pull requests and issues of the project to the teams their authors belonged to at the time",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+	DependencyTables: []string{
+		code.Commit{}.TableName(),
+		code.RepoCommit{}.TableName(),
+		code.PullRequest{}.TableName(),
+		ticket.Issue{}.TableName(),
+		ticket.BoardIssue{}.TableName(),
+		crossdomain.ProjectMapping{}.TableName(),
+		crossdomain.TeamMembership{}.TableName(),
+		crossdomain.UserAccount{}.TableName(),
+	},
+	ProductTables: []string{crossdomain.ProjectTeamAttribution{}.TableName()},
+}
+
+// teamAttributionRow is a commit, pull request or issue with its author and the time the work was done
+type teamAttributionRow struct {
+	RowId    string
+	AuthorId string
+	Date     *time.Time
+}
+
+type teamAttributionSource struct {
+	table   string
+	clauses []dal.Clause
+	// teamAt resolves the team of the author, commits only have the email of their author
+	teamAt func(resolver *api.TeamResolver, authorId string, at time.Time) string
+}
+
+// CalculateTeamAttributions attributes commits by their authored date, pull requests by their created date like
+// project_pr_metrics does, and issues to their assignee by their resolution date, or created date while unresolved
+func CalculateTeamAttributions(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*DoraTaskData)
+	projectName := data.Options.ProjectName
+
+	err := db.Delete(&crossdomain.ProjectTeamAttribution{}, dal.Where("project_name = ?", projectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting previous project_team_attributions")
+	}
+	teamResolver, err := api.NewTeamResolver(db)
+	if err != nil {
+		return err
+	}
+
+	sources := []teamAttributionSource{
+		{
+			table: code.Commit{}.TableName(),
+			clauses: []dal.Clause{
+				dal.Select("DISTINCT c.sha AS row_id, c.author_email AS author_id, c.authored_date AS date"),
+				dal.From("commits c"),
+				dal.Join("JOIN repo_commits rc ON (rc.commit_sha = c.sha)"),
+				dal.Join("JOIN project_mapping pm ON (pm.row_id = rc.repo_id)"),
+				dal.Where("pm.project_name = ? AND pm.table = 'repos'", projectName),
+			},
+			teamAt: (*api.TeamResolver).EmailTeamAt,
+		},
+		{
+			table: code.PullRequest{}.TableName(),
+			clauses: []dal.Clause{
+				dal.Select("pr.id AS row_id, pr.author_id, pr.created_date AS date"),
+				dal.From("pull_requests pr"),
+				dal.Join("JOIN project_mapping pm ON (pm.row_id = pr.base_repo_id)"),
+				dal.Where("pm.project_name = ? AND pm.table = 'repos'", projectName),
+			},
+			teamAt: (*api.TeamResolver).AccountTeamAt,
+		},
+		{
+			table: ticket.Issue{}.TableName(),
+			clauses: []dal.Clause{
+				dal.Select("DISTINCT i.id AS row_id, i.assignee_id AS author_id, COALESCE(i.resolution_date, i.created_date) AS date"),
+				dal.From("issues i"),
+				dal.Join("JOIN board_issues bi ON (bi.issue_id = i.id)"),
+				dal.Join("JOIN project_mapping pm ON (pm.row_id = bi.board_id)"),
+				dal.Where("pm.project_name = ? AND pm.table = 'boards'", projectName),
+			},
+			teamAt: (*api.TeamResolver).AccountTeamAt,
+		},
+	}
+
+	batch, err := api.NewBatchSave(taskCtx, reflect.TypeOf(&crossdomain.ProjectTeamAttribution{}), 500)
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		err = attributeSourceToTeams(db, batch, teamResolver, projectName, source)
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Close()
+}
+
+func attributeSourceToTeams(
+	db dal.Dal,
+	batch *api.BatchSave,
+	resolver *api.TeamResolver,
+	projectName string,
+	source teamAttributionSource,
+) errors.Error {
+	cursor, err := db.Cursor(source.clauses...)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading "+source.table)
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		row := &teamAttributionRow{}
+		err = db.Fetch(cursor, row)
+		if err != nil {
+			return errors.Default.Wrap(err, "error fetching "+source.table)
+		}
+		if row.AuthorId == "" || row.Date == nil {
+			continue
+		}
+		teamId := source.teamAt(resolver, row.AuthorId, *row.Date)
+		if teamId == "" {
+			continue
+		}
+		teamIds := append([]string{teamId}, resolver.TeamAncestors(teamId)...)
+		for _, attribution := range attributeToTeams(projectName, source.table, row.RowId, teamIds) {
+			err = batch.Add(attribution)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// attributeToTeams attributes the row to the team of its author followed by the ancestors of the team
+func attributeToTeams(projectName, table, rowId string, teamIds []string) []*crossdomain.ProjectTeamAttribution {
+	attributions := make([]*crossdomain.ProjectTeamAttribution, len(teamIds))
+	for depth, id := range teamIds {
+		attributions[depth] = &crossdomain.ProjectTeamAttribution{
+			ProjectName: projectName,
+			Table:       table,
+			RowId:       rowId,
+			TeamId:      id,
+			Depth:       depth,
+		}
+	}
+	return attributions
+}
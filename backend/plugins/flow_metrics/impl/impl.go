@@ -0,0 +1,162 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impl
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/flow_metrics/models"
+	"github.com/apache/incubator-devlake/plugins/flow_metrics/models/migrationscripts"
+	"github.com/apache/incubator-devlake/plugins/flow_metrics/tasks"
+)
+
+// make sure interface is implemented
+var _ interface {
+	plugin.PluginMeta
+	plugin.PluginTask
+	plugin.PluginModel
+	plugin.PluginMetric
+	plugin.PluginMigration
+	plugin.MetricPluginBlueprintV200
+} = (*FlowMetrics)(nil)
+
+type FlowMetrics struct{}
+
+func (p FlowMetrics) Name() string {
+	return "flow_metrics"
+}
+
+func (p FlowMetrics) Description() string {
+	return "Calculate cycle time, flow efficiency, WIP and throughput from the issue status history"
+}
+
+func (p FlowMetrics) RootPkgPath() string {
+	return "github.com/apache/incubator-devlake/plugins/flow_metrics"
+}
+
+func (p FlowMetrics) RequiredDataEntities() (data []map[string]interface{}, err errors.Error) {
+	return []map[string]interface{}{
+		{
+			"model": "issue_changelogs",
+			"requiredFields": map[string]string{
+				"column":        "type",
+				"execptedValue": "Issue",
+			},
+		},
+	}, nil
+}
+
+func (p FlowMetrics) IsProjectMetric() bool {
+	return true
+}
+
+func (p FlowMetrics) RunAfter() ([]string, errors.Error) {
+	return []string{"issue_trace"}, nil
+}
+
+func (p FlowMetrics) Settings() interface{} {
+	return nil
+}
+
+func (p FlowMetrics) GetTablesInfo() []dal.Tabler {
+	return []dal.Tabler{
+		&models.IssueFlowMetric{},
+		&models.FlowDailyWip{},
+		&models.FlowWeeklyThroughput{},
+	}
+}
+
+func (p FlowMetrics) SubTaskMetas() []plugin.SubTaskMeta {
+	return []plugin.SubTaskMeta{
+		tasks.CalculateIssueFlowMetricsMeta,
+		tasks.CalculateDailyWipMeta,
+		tasks.CalculateWeeklyThroughputMeta,
+	}
+}
+
+func (p FlowMetrics) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]interface{}) (interface{}, errors.Error) {
+	op, err := tasks.DecodeAndValidateTaskOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	boardIds := op.ScopeIds
+	if len(boardIds) == 0 {
+		err = taskCtx.GetDal().Pluck(
+			"pm.row_id",
+			&boardIds,
+			dal.From("project_mapping pm"),
+			dal.Where("pm.project_name = ? and pm.table = ?", op.ProjectName, "boards"),
+		)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "failed to get project mapping")
+		}
+	}
+	return &tasks.TaskData{
+		Options:  op,
+		BoardIds: boardIds,
+	}, nil
+}
+
+func (p FlowMetrics) MigrationScripts() []plugin.MigrationScript {
+	return migrationscripts.All()
+}
+
+// MakeMetricPluginPipelinePlanV200 runs issue_trace first since metric plugins run in parallel
+func (p FlowMetrics) MakeMetricPluginPipelinePlanV200(projectName string, options json.RawMessage) (coreModels.PipelinePlan, errors.Error) {
+	op := &tasks.Options{}
+	if options != nil && string(options) != "\"\"" {
+		err := json.Unmarshal(options, op)
+		if err != nil {
+			return nil, errors.Default.WrapRaw(err)
+		}
+	}
+	plan := coreModels.PipelinePlan{
+		{
+			{
+				Plugin: "issue_trace",
+				Options: map[string]interface{}{
+					"projectName": projectName,
+					"scopeIds":    op.ScopeIds,
+				},
+				Subtasks: []string{
+					"ConvertIssueStatusHistory",
+				},
+			},
+		},
+		{
+			{
+				Plugin: "flow_metrics",
+				Options: map[string]interface{}{
+					"projectName":   projectName,
+					"scopeIds":      op.ScopeIds,
+					"statusMapping": op.StatusMapping,
+				},
+				Subtasks: []string{
+					"calculateIssueFlowMetrics",
+					"calculateDailyWip",
+					"calculateWeeklyThroughput",
+				},
+			},
+		},
+	}
+	return plan, nil
+}
@@ -0,0 +1,44 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// IssueFlowMetric records the cycle time of an issue of a board, split into the time it was actively worked on
+// and the time it was waiting. The cycle starts when the issue enters an active status for the first time and
+// ends when it's done, EndDate and CycleTimeMinutes are nil if the issue is still in progress
+type IssueFlowMetric struct {
+	common.NoPKModel
+	ProjectName       string `gorm:"primaryKey;type:varchar(100)"`
+	BoardId           string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId           string `gorm:"primaryKey;type:varchar(255)"`
+	StartDate         *time.Time
+	EndDate           *time.Time
+	CycleTimeMinutes  *int64
+	ActiveTimeMinutes int64
+	WaitTimeMinutes   int64
+	FlowEfficiency    *float64
+}
+
+func (IssueFlowMetric) TableName() string {
+	return "issue_flow_metrics"
+}
@@ -0,0 +1,89 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type addFlowMetricTables struct{}
+
+type issueFlowMetric20241022 struct {
+	archived.NoPKModel
+	ProjectName       string `gorm:"primaryKey;type:varchar(100)"`
+	BoardId           string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId           string `gorm:"primaryKey;type:varchar(255)"`
+	StartDate         *time.Time
+	EndDate           *time.Time
+	CycleTimeMinutes  *int64
+	ActiveTimeMinutes int64
+	WaitTimeMinutes   int64
+	FlowEfficiency    *float64
+}
+
+func (issueFlowMetric20241022) TableName() string {
+	return "issue_flow_metrics"
+}
+
+type flowDailyWip20241022 struct {
+	archived.NoPKModel
+	ProjectName string    `gorm:"primaryKey;type:varchar(100)"`
+	BoardId     string    `gorm:"primaryKey;type:varchar(255)"`
+	Date        time.Time `gorm:"primaryKey"`
+	WipCount    int
+}
+
+func (flowDailyWip20241022) TableName() string {
+	return "flow_daily_wip"
+}
+
+type flowWeeklyThroughput20241022 struct {
+	archived.NoPKModel
+	ProjectName            string    `gorm:"primaryKey;type:varchar(100)"`
+	BoardId                string    `gorm:"primaryKey;type:varchar(255)"`
+	WeekStart              time.Time `gorm:"primaryKey"`
+	ThroughputCount        int
+	MedianCycleTimeMinutes *int64
+	FlowEfficiency         *float64
+}
+
+func (flowWeeklyThroughput20241022) TableName() string {
+	return "flow_weekly_throughput"
+}
+
+func (*addFlowMetricTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&issueFlowMetric20241022{},
+		&flowDailyWip20241022{},
+		&flowWeeklyThroughput20241022{},
+	)
+}
+
+func (*addFlowMetricTables) Version() uint64 {
+	return 20241022101530
+}
+
+func (*addFlowMetricTables) Name() string {
+	return "add issue_flow_metrics, flow_daily_wip and flow_weekly_throughput"
+}
@@ -0,0 +1,146 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/plugins/flow_metrics/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseTime(t *testing.T, value string) time.Time {
+	d, err := time.Parse(time.RFC3339, value)
+	assert.Nil(t, err)
+	return d
+}
+
+func TestCalculateIssueFlowMetric(t *testing.T) {
+	now := parseTime(t, "2024-10-10T00:00:00Z")
+	segment := func(status, originalStatus, start, end string) statusSegment {
+		s := statusSegment{BoardId: "b1", IssueId: "i1", Status: status, OriginalStatus: originalStatus, StartDate: parseTime(t, start)}
+		if end != "" {
+			e := parseTime(t, end)
+			s.EndDate = &e
+		}
+		return s
+	}
+	op := &Options{StatusMapping: map[string]string{"Blocked": FLOW_STATE_WAIT, "Review": FLOW_STATE_ACTIVE}}
+
+	testCases := []struct {
+		name       string
+		segments   []statusSegment
+		expected   *models.IssueFlowMetric
+		efficiency float64
+	}{
+		{
+			name: "never started",
+			segments: []statusSegment{
+				segment(ticket.TODO, "To Do", "2024-10-01T00:00:00Z", ""),
+			},
+		},
+		{
+			name: "done",
+			segments: []statusSegment{
+				segment(ticket.TODO, "To Do", "2024-10-01T00:00:00Z", "2024-10-02T00:00:00Z"),
+				segment(ticket.IN_PROGRESS, "In Progress", "2024-10-02T00:00:00Z", "2024-10-02T06:00:00Z"),
+				segment(ticket.IN_PROGRESS, "Blocked", "2024-10-02T06:00:00Z", "2024-10-02T12:00:00Z"),
+				segment(ticket.TODO, "Review", "2024-10-02T12:00:00Z", "2024-10-03T00:00:00Z"),
+				segment(ticket.DONE, "Done", "2024-10-03T00:00:00Z", "2024-10-04T00:00:00Z"),
+				segment(ticket.DONE, "Closed", "2024-10-04T00:00:00Z", ""),
+			},
+			expected: &models.IssueFlowMetric{
+				CycleTimeMinutes:  int64Ptr(24 * 60),
+				ActiveTimeMinutes: 18 * 60,
+				WaitTimeMinutes:   6 * 60,
+			},
+			efficiency: .75,
+		},
+		{
+			name: "reopened and in progress",
+			segments: []statusSegment{
+				segment(ticket.IN_PROGRESS, "In Progress", "2024-10-08T00:00:00Z", "2024-10-08T12:00:00Z"),
+				segment(ticket.DONE, "Done", "2024-10-08T12:00:00Z", "2024-10-09T00:00:00Z"),
+				segment(ticket.IN_PROGRESS, "In Progress", "2024-10-09T00:00:00Z", ""),
+			},
+			expected: &models.IssueFlowMetric{
+				ActiveTimeMinutes: 36 * 60,
+				WaitTimeMinutes:   12 * 60,
+			},
+			efficiency: .75,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			metric := calculateIssueFlowMetric(op, tc.segments, now)
+			if tc.expected == nil {
+				assert.Nil(t, metric)
+				return
+			}
+			assert.Equal(t, "i1", metric.IssueId)
+			assert.Equal(t, tc.expected.CycleTimeMinutes, metric.CycleTimeMinutes)
+			assert.Equal(t, tc.expected.CycleTimeMinutes == nil, metric.EndDate == nil)
+			assert.Equal(t, tc.expected.ActiveTimeMinutes, metric.ActiveTimeMinutes)
+			assert.Equal(t, tc.expected.WaitTimeMinutes, metric.WaitTimeMinutes)
+			assert.InDelta(t, tc.efficiency, *metric.FlowEfficiency, 0.0001)
+		})
+	}
+}
+
+func TestRollupFlowMetrics(t *testing.T) {
+	now := parseTime(t, "2024-10-09T08:00:00Z")
+	metric := func(start, end string, cycleTime, active, wait int64) *models.IssueFlowMetric {
+		m := &models.IssueFlowMetric{ProjectName: "p", BoardId: "b1", ActiveTimeMinutes: active, WaitTimeMinutes: wait}
+		s := parseTime(t, start)
+		m.StartDate = &s
+		if end != "" {
+			e := parseTime(t, end)
+			m.EndDate = &e
+			m.CycleTimeMinutes = &cycleTime
+		}
+		return m
+	}
+	metrics := []*models.IssueFlowMetric{
+		metric("2024-10-03T10:00:00Z", "2024-10-04T10:00:00Z", 1440, 1000, 440),
+		metric("2024-10-04T10:00:00Z", "2024-10-07T10:00:00Z", 4320, 1000, 3320),
+		metric("2024-10-06T10:00:00Z", "2024-10-06T12:00:00Z", 120, 120, 0),
+		metric("2024-10-08T10:00:00Z", "", 0, 60, 0),
+	}
+
+	var wip []int
+	for _, day := range rollupDailyWip(metrics, now) {
+		wip = append(wip, day.WipCount)
+	}
+	// from Oct 3rd to Oct 9th
+	assert.Equal(t, []int{1, 2, 1, 2, 1, 1, 1}, wip)
+
+	throughput := rollupWeeklyThroughput(metrics)
+	assert.Len(t, throughput, 2)
+	assert.Equal(t, parseTime(t, "2024-09-30T00:00:00Z"), throughput[0].WeekStart)
+	assert.Equal(t, 2, throughput[0].ThroughputCount)
+	assert.Equal(t, int64(120), *throughput[0].MedianCycleTimeMinutes)
+	assert.InDelta(t, 1120.0/1560.0, *throughput[0].FlowEfficiency, 0.0001)
+	assert.Equal(t, parseTime(t, "2024-10-07T00:00:00Z"), throughput[1].WeekStart)
+	assert.Equal(t, 1, throughput[1].ThroughputCount)
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
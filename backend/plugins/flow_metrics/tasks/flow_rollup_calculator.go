@@ -0,0 +1,201 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/flow_metrics/models"
+)
+
+var CalculateDailyWipMeta = plugin.SubTaskMeta{
+	Name:             "calculateDailyWip",
+	EntryPoint:       CalculateDailyWip,
+	EnabledByDefault: true,
+	Description:      "Calculate the number of issues in progress of each board by day",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET},
+	DependencyTables: []string{models.IssueFlowMetric{}.TableName()},
+	ProductTables:    []string{models.FlowDailyWip{}.TableName()},
+}
+
+var CalculateWeeklyThroughputMeta = plugin.SubTaskMeta{
+	Name:             "calculateWeeklyThroughput",
+	EntryPoint:       CalculateWeeklyThroughput,
+	EnabledByDefault: true,
+	Description:      "Calculate the number of issues done of each board by week",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET},
+	DependencyTables: []string{models.IssueFlowMetric{}.TableName()},
+	ProductTables:    []string{models.FlowWeeklyThroughput{}.TableName()},
+}
+
+func CalculateDailyWip(taskCtx plugin.SubTaskContext) errors.Error {
+	return saveFlowRollups(taskCtx, &models.FlowDailyWip{}, func(metrics []*models.IssueFlowMetric) []interface{} {
+		var result []interface{}
+		for _, wip := range rollupDailyWip(metrics, time.Now()) {
+			result = append(result, wip)
+		}
+		return result
+	})
+}
+
+func CalculateWeeklyThroughput(taskCtx plugin.SubTaskContext) errors.Error {
+	return saveFlowRollups(taskCtx, &models.FlowWeeklyThroughput{}, func(metrics []*models.IssueFlowMetric) []interface{} {
+		var result []interface{}
+		for _, throughput := range rollupWeeklyThroughput(metrics) {
+			result = append(result, throughput)
+		}
+		return result
+	})
+}
+
+// saveFlowRollups replaces the rollups of the project with the ones calculated from its issue_flow_metrics
+func saveFlowRollups(
+	taskCtx plugin.SubTaskContext,
+	rollupType dal.Tabler,
+	rollup func(metrics []*models.IssueFlowMetric) []interface{},
+) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*TaskData)
+	var metrics []*models.IssueFlowMetric
+	err := db.All(&metrics, dal.Where("project_name = ?", data.Options.ProjectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading issue_flow_metrics")
+	}
+	err = db.Delete(rollupType, dal.Where("project_name = ?", data.Options.ProjectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting previous "+rollupType.TableName())
+	}
+	batch, err := api.NewBatchSave(taskCtx, reflect.TypeOf(rollupType), 500)
+	if err != nil {
+		return err
+	}
+	for _, row := range rollup(metrics) {
+		err = batch.Add(row)
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Close()
+}
+
+type flowRollupKey struct {
+	projectName string
+	boardId     string
+	date        time.Time
+}
+
+// rollupDailyWip counts the issues in progress at any time of each day, from the first day an issue of the board
+// started to today. Days are in UTC
+func rollupDailyWip(metrics []*models.IssueFlowMetric, now time.Time) []*models.FlowDailyWip {
+	today := truncateToDay(now)
+	counts := make(map[flowRollupKey]int)
+	firstDays := make(map[flowRollupKey]time.Time)
+	for _, metric := range metrics {
+		if metric.StartDate == nil {
+			continue
+		}
+		last := today
+		if metric.EndDate != nil {
+			last = truncateToDay(*metric.EndDate)
+		}
+		first := truncateToDay(*metric.StartDate)
+		board := flowRollupKey{projectName: metric.ProjectName, boardId: metric.BoardId}
+		if firstDay, ok := firstDays[board]; !ok || first.Before(firstDay) {
+			firstDays[board] = first
+		}
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			counts[flowRollupKey{metric.ProjectName, metric.BoardId, day}]++
+		}
+	}
+	var result []*models.FlowDailyWip
+	for board, firstDay := range firstDays {
+		for day := firstDay; !day.After(today); day = day.AddDate(0, 0, 1) {
+			result = append(result, &models.FlowDailyWip{
+				ProjectName: board.projectName,
+				BoardId:     board.boardId,
+				Date:        day,
+				WipCount:    counts[flowRollupKey{board.projectName, board.boardId, day}],
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BoardId != result[j].BoardId {
+			return result[i].BoardId < result[j].BoardId
+		}
+		return result[i].Date.Before(result[j].Date)
+	})
+	return result
+}
+
+// rollupWeeklyThroughput counts the issues done in each week, along with their median cycle time and flow
+// efficiency. Weeks start on Monday in UTC
+func rollupWeeklyThroughput(metrics []*models.IssueFlowMetric) []*models.FlowWeeklyThroughput {
+	type weekStats struct {
+		throughput *models.FlowWeeklyThroughput
+		cycleTimes []int64
+		active     int64
+		total      int64
+	}
+	weeks := make(map[flowRollupKey]*weekStats)
+	var keys []flowRollupKey
+	for _, metric := range metrics {
+		if metric.EndDate == nil || metric.CycleTimeMinutes == nil {
+			continue
+		}
+		day := truncateToDay(*metric.EndDate)
+		key := flowRollupKey{metric.ProjectName, metric.BoardId, day.AddDate(0, 0, -(int(day.Weekday())+6)%7)}
+		stats, ok := weeks[key]
+		if !ok {
+			stats = &weekStats{throughput: &models.FlowWeeklyThroughput{
+				ProjectName: key.projectName,
+				BoardId:     key.boardId,
+				WeekStart:   key.date,
+			}}
+			weeks[key] = stats
+			keys = append(keys, key)
+		}
+		stats.throughput.ThroughputCount++
+		stats.cycleTimes = append(stats.cycleTimes, *metric.CycleTimeMinutes)
+		stats.active += metric.ActiveTimeMinutes
+		stats.total += metric.ActiveTimeMinutes + metric.WaitTimeMinutes
+	}
+	result := make([]*models.FlowWeeklyThroughput, 0, len(keys))
+	for _, key := range keys {
+		stats := weeks[key]
+		sort.Slice(stats.cycleTimes, func(i, j int) bool { return stats.cycleTimes[i] < stats.cycleTimes[j] })
+		median := stats.cycleTimes[(len(stats.cycleTimes)-1)/2]
+		stats.throughput.MedianCycleTimeMinutes = &median
+		if stats.total > 0 {
+			efficiency := float64(stats.active) / float64(stats.total)
+			stats.throughput.FlowEfficiency = &efficiency
+		}
+		result = append(result, stats.throughput)
+	}
+	return result
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
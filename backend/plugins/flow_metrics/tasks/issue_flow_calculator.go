@@ -0,0 +1,171 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/flow_metrics/models"
+)
+
+var CalculateIssueFlowMetricsMeta = plugin.SubTaskMeta{
+	Name:             "calculateIssueFlowMetrics",
+	EntryPoint:       CalculateIssueFlowMetrics,
+	EnabledByDefault: true,
+	Description:      "Calculate the cycle time, active and waiting time of issues from issue_status_history",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET},
+	DependencyTables: []string{"issue_status_history", ticket.BoardIssue{}.TableName()},
+	ProductTables:    []string{models.IssueFlowMetric{}.TableName()},
+}
+
+type statusSegment struct {
+	BoardId        string
+	IssueId        string
+	Status         string
+	OriginalStatus string
+	StartDate      time.Time
+	EndDate        *time.Time
+}
+
+func CalculateIssueFlowMetrics(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*TaskData)
+	// Clear previous results from the project
+	err := db.Delete(&models.IssueFlowMetric{}, dal.Where("project_name = ?", data.Options.ProjectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting previous issue_flow_metrics")
+	}
+	if len(data.BoardIds) == 0 {
+		return nil
+	}
+
+	cursor, err := db.Cursor(
+		dal.Select("bi.board_id, ish.issue_id, ish.status, ish.original_status, ish.start_date, ish.end_date"),
+		dal.From("issue_status_history ish"),
+		dal.Join("JOIN board_issues bi ON bi.issue_id = ish.issue_id"),
+		dal.Where("bi.board_id IN ?", data.BoardIds),
+		dal.Orderby("bi.board_id, ish.issue_id, ish.start_date"),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading issue_status_history")
+	}
+	defer cursor.Close()
+
+	batch, err := api.NewBatchSave(taskCtx, reflect.TypeOf(&models.IssueFlowMetric{}), 500)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var segments []statusSegment
+	flush := func() errors.Error {
+		if len(segments) == 0 {
+			return nil
+		}
+		metric := calculateIssueFlowMetric(data.Options, segments, now)
+		segments = segments[:0]
+		if metric == nil {
+			return nil
+		}
+		metric.ProjectName = data.Options.ProjectName
+		return batch.Add(metric)
+	}
+	for cursor.Next() {
+		segment := statusSegment{}
+		err = db.Fetch(cursor, &segment)
+		if err != nil {
+			return errors.Default.Wrap(err, "error fetching issue_status_history")
+		}
+		if len(segments) > 0 && (segments[0].BoardId != segment.BoardId || segments[0].IssueId != segment.IssueId) {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+		segments = append(segments, segment)
+	}
+	err = flush()
+	if err != nil {
+		return err
+	}
+	return batch.Close()
+}
+
+// calculateIssueFlowMetric calculates the flow metric of an issue from its status history sorted by start date,
+// nil is returned if the issue never entered an active status
+func calculateIssueFlowMetric(op *Options, segments []statusSegment, now time.Time) *models.IssueFlowMetric {
+	states := make([]string, len(segments))
+	startIndex := -1
+	for i, segment := range segments {
+		states[i] = op.GetFlowState(segment.Status, segment.OriginalStatus)
+		if startIndex < 0 && states[i] == FLOW_STATE_ACTIVE {
+			startIndex = i
+		}
+	}
+	if startIndex < 0 {
+		return nil
+	}
+	// segments are reused by the caller, copy the dates
+	cycleStart := segments[startIndex].StartDate
+	metric := &models.IssueFlowMetric{
+		BoardId:   segments[0].BoardId,
+		IssueId:   segments[0].IssueId,
+		StartDate: &cycleStart,
+	}
+	// the cycle ends when the issue enters the trailing done statuses, i.e. Done -> Closed
+	cycleEnd := now
+	endIndex := len(segments)
+	for endIndex > startIndex+1 && states[endIndex-1] == FLOW_STATE_DONE {
+		endIndex--
+	}
+	if endIndex < len(segments) {
+		cycleEnd = segments[endIndex].StartDate
+		metric.EndDate = &cycleEnd
+		cycleTime := int64(cycleEnd.Sub(cycleStart).Minutes())
+		metric.CycleTimeMinutes = &cycleTime
+	}
+	for i := startIndex; i < endIndex; i++ {
+		segmentEnd := now
+		if segments[i].EndDate != nil {
+			segmentEnd = *segments[i].EndDate
+		}
+		if segmentEnd.After(cycleEnd) {
+			segmentEnd = cycleEnd
+		}
+		if !segmentEnd.After(segments[i].StartDate) {
+			continue
+		}
+		minutes := int64(segmentEnd.Sub(segments[i].StartDate).Minutes())
+		if states[i] == FLOW_STATE_ACTIVE {
+			metric.ActiveTimeMinutes += minutes
+		} else {
+			// reopened issues wait in the done statuses as well
+			metric.WaitTimeMinutes += minutes
+		}
+	}
+	if total := metric.ActiveTimeMinutes + metric.WaitTimeMinutes; total > 0 {
+		efficiency := float64(metric.ActiveTimeMinutes) / float64(total)
+		metric.FlowEfficiency = &efficiency
+	}
+	return metric
+}
@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+const (
+	FLOW_STATE_ACTIVE = "ACTIVE"
+	FLOW_STATE_WAIT   = "WAIT"
+	FLOW_STATE_DONE   = "DONE"
+)
+
+// Options original parameter from bp (or pipeline)
+type Options struct {
+	ProjectName string `json:"projectName" mapstructure:"projectName"`
+	// ScopeIds are the boards to calculate, all the boards of the project by default
+	ScopeIds []string `json:"scopeIds" mapstructure:"scopeIds"`
+	// StatusMapping maps original statuses to ACTIVE or WAIT, the other statuses follow their standard status:
+	// IN_PROGRESS is active, DONE ends the cycle and the rest are waiting
+	StatusMapping map[string]string `json:"statusMapping" mapstructure:"statusMapping"`
+}
+
+// TaskData converted parameter
+type TaskData struct {
+	Options  *Options
+	BoardIds []string
+}
+
+func DecodeAndValidateTaskOptions(options map[string]interface{}) (*Options, errors.Error) {
+	var op Options
+	err := helper.Decode(options, &op, nil)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error decoding flow_metrics task options")
+	}
+	if op.ProjectName == "" {
+		return nil, errors.BadInput.New("projectName is required")
+	}
+	for status, state := range op.StatusMapping {
+		state = strings.ToUpper(state)
+		if state != FLOW_STATE_ACTIVE && state != FLOW_STATE_WAIT {
+			return nil, errors.BadInput.New(fmt.Sprintf("status %s must be mapped to either ACTIVE or WAIT", status))
+		}
+		op.StatusMapping[status] = state
+	}
+	return &op, nil
+}
+
+// GetFlowState returns whether the status is active, waiting or done
+func (op *Options) GetFlowState(status, originalStatus string) string {
+	if state, ok := op.StatusMapping[originalStatus]; ok {
+		return state
+	}
+	switch status {
+	case ticket.IN_PROGRESS:
+		return FLOW_STATE_ACTIVE
+	case ticket.DONE:
+		return FLOW_STATE_DONE
+	}
+	return FLOW_STATE_WAIT
+}
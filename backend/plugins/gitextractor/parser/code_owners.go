@@ -0,0 +1,81 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/gitextractor/models"
+)
+
+// codeOwnersFilePaths are the locations where GitHub, GitLab and Bitbucket look for the CODEOWNERS file, the
+// first one found is used
+var codeOwnersFilePaths = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	".bitbucket/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// readFileFunc reads a file of the head commit, found is false when the file does not exist
+type readFileFunc func(filePath string) (content string, found bool, err error)
+
+// collectCodeOwners replaces the code owners of the repo with the rules of its CODEOWNERS file at the head commit
+func collectCodeOwners(subtaskCtx plugin.SubTaskContext, store models.Store, repoId, headSha string, readFile readFileFunc) error {
+	db := subtaskCtx.GetDal()
+	err := db.Delete(&code.CodeOwner{}, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return errors.Convert(err)
+	}
+	for _, filePath := range codeOwnersFilePaths {
+		content, found, err := readFile(filePath)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for _, rule := range helper.ParseCodeOwners(content) {
+			owners := rule.Owners
+			if len(owners) == 0 {
+				owners = []string{""}
+			}
+			for _, owner := range owners {
+				err = store.CodeOwners(&code.CodeOwner{
+					RepoId:    repoId,
+					LineNo:    rule.LineNo,
+					Owner:     owner,
+					CommitSha: headSha,
+					FilePath:  filePath,
+					Section:   rule.Section,
+					Optional:  rule.Optional,
+					Pattern:   rule.Pattern,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return nil
+}
@@ -90,6 +90,10 @@ func (r *GogitRepoCollector) CollectAll(subtaskCtx plugin.SubTaskContext) error
 	if err != nil {
 		return err
 	}
+	err = r.CollectCodeOwners(subtaskCtx)
+	if err != nil {
+		return err
+	}
 	return r.CollectDiffLine(subtaskCtx)
 }
 
@@ -561,3 +565,26 @@ func (r *GogitRepoCollector) CollectDiffLine(subtaskCtx plugin.SubTaskContext) e
 	// So we just ignore it.
 	return nil
 }
+
+// CollectCodeOwners parses the CODEOWNERS file of the head commit
+func (r *GogitRepoCollector) CollectCodeOwners(subtaskCtx plugin.SubTaskContext) error {
+	head, err := r.repo.Head()
+	if err != nil {
+		return err
+	}
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	return collectCodeOwners(subtaskCtx, r.store, r.id, head.Hash().String(), func(filePath string) (string, bool, error) {
+		file, err := commit.File(filePath)
+		if err == object.ErrFileNotFound {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		content, err := file.Contents()
+		return content, true, err
+	})
+}
@@ -85,6 +85,10 @@ func (r *Libgit2RepoCollector) CollectAll(subtaskCtx plugin.SubTaskContext) erro
 	if err != nil {
 		return err
 	}
+	err = r.CollectCodeOwners(subtaskCtx)
+	if err != nil {
+		return err
+	}
 	opt := subtaskCtx.GetData().(*GitExtractorTaskData).Options
 	if !*opt.SkipCommitStat {
 		return r.CollectDiffLine(subtaskCtx)
@@ -602,6 +606,36 @@ func (r *Libgit2RepoCollector) CollectDiffLine(subtaskCtx plugin.SubTaskContext)
 	return nil
 }
 
+// CollectCodeOwners parses the CODEOWNERS file of the head commit
+func (r *Libgit2RepoCollector) CollectCodeOwners(subtaskCtx plugin.SubTaskContext) error {
+	head, err := r.repo.Head()
+	if err != nil {
+		return err
+	}
+	commit, err := r.repo.LookupCommit(head.Target())
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	return collectCodeOwners(subtaskCtx, r.store, r.id, head.Target().String(), func(filePath string) (string, bool, error) {
+		entry, err := tree.EntryByPath(filePath)
+		if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		blob, err := r.repo.LookupBlob(entry.Id)
+		if err != nil {
+			return "", false, err
+		}
+		return string(blob.Contents()), true, nil
+	})
+}
+
 func updateSnapshotFileBlame(currentCommit *git.Commit, deleted models.DiffLines, added models.DiffLines, lastFile string, snapshot map[string]*models.FileBlame) {
 	sort.Sort(deleted)
 	for _, line := range deleted {
@@ -136,6 +136,15 @@ func (d *Database) CommitLineChange(commitLineChange *code.CommitLineChange) err
 	return batch.Add(commitLineChange)
 }
 
+func (d *Database) CodeOwners(codeOwner *code.CodeOwner) errors.Error {
+	batch, err := d.driver.ForType(reflect.TypeOf(codeOwner))
+	if err != nil {
+		return err
+	}
+	d.updateRawDataFields(&codeOwner.RawDataOrigin)
+	return batch.Add(codeOwner)
+}
+
 func (d *Database) CommitParents(pp []*code.CommitParent) errors.Error {
 	if len(pp) == 0 {
 		return nil
@@ -37,11 +37,10 @@ const (
 	AppKey      = "AppKey"
 )
 
-// GithubAccessToken supports fetching data with multiple tokens
+// GithubAccessToken supports fetching data with multiple tokens separated by comma, the ApiClient rotates them
+// through its credential pool
 type GithubAccessToken struct {
 	helper.AccessToken `mapstructure:",squash"`
-	tokens             []string `gorm:"-" json:"-" mapstructure:"-"`
-	tokenIndex         int      `gorm:"-" json:"-" mapstructure:"-"`
 }
 
 type GithubAppKey struct {
@@ -57,13 +56,8 @@ type GithubConn struct {
 	GithubAppKey          `mapstructure:",squash" authMethod:"AppKey"`
 }
 
-// PrepareApiClient splits Token to tokens for SetupAuthentication to utilize
+// PrepareApiClient fetches the installation token for the AppKey authentication
 func (conn *GithubConn) PrepareApiClient(apiClient plugin.ApiClient) errors.Error {
-
-	if conn.AuthMethod == AccessToken {
-		conn.tokens = strings.Split(conn.Token, ",")
-	}
-
 	if conn.AuthMethod == AppKey && conn.InstallationID != 0 {
 		token, err := conn.getInstallationAccessToken(apiClient)
 		if err != nil {
@@ -71,26 +65,32 @@ func (conn *GithubConn) PrepareApiClient(apiClient plugin.ApiClient) errors.Erro
 		}
 
 		conn.Token = token.Token
-		conn.tokens = []string{token.Token}
 	}
 
 	return nil
 }
 
-// SetupAuthentication sets up the HTTP Request Authentication
+// SetupAuthentication sets up the HTTP Request Authentication with the first token, the others are picked by
+// the credential pool of the ApiClient
 func (conn *GithubConn) SetupAuthentication(req *http.Request) errors.Error {
-	// Rotates token on each request.
-	if len(conn.tokens) > 0 {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", conn.tokens[conn.tokenIndex]))
-		// Set next token index
-		conn.tokenIndex = (conn.tokenIndex + 1) % len(conn.tokens)
+	if tokens := conn.getTokens(); len(tokens) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", tokens[0]))
 	}
-
 	return nil
 }
 
+func (gat *GithubAccessToken) getTokens() []string {
+	var tokens []string
+	for _, token := range strings.Split(gat.Token, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
 func (gat *GithubAccessToken) GetTokensCount() int {
-	return len(gat.tokens)
+	return len(gat.getTokens())
 }
 
 // GithubConnection holds GithubConn plus ID/Name for database storage
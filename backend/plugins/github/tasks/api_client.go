@@ -38,6 +38,7 @@ func CreateApiClient(taskCtx plugin.TaskContext, connection *models.GithubConnec
 	rateLimiter := &api.ApiRateLimitCalculator{
 		UserRateLimitPerHour: connection.RateLimitPerHour,
 		Method:               http.MethodGet,
+		Credentials:          connection.GetTokensCount(),
 		DynamicRateLimit: func(res *http.Response) (int, time.Duration, errors.Error) {
 			/* calculate by number of remaining requests
 			remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining"))
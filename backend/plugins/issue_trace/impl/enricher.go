@@ -90,6 +90,8 @@ func (p IssueTrace) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.ConvertIssueStatusHistoryMeta,
 		// issue_assignee_history
 		tasks.ConvertIssueAssigneeHistoryMeta,
+		// sprint_issue_events and sprint_daily_snapshots
+		tasks.ConvertSprintSnapshotsMeta,
 	}
 }
 
@@ -137,6 +139,7 @@ func (p IssueTrace) RootPkgPath() string {
 func (p IssueTrace) MigrationScripts() []plugin.MigrationScript {
 	return []plugin.MigrationScript{
 		&migrationscripts.NewIssueTable{},
+		&migrationscripts.AddSprintHistoryTables{},
 	}
 }
 
@@ -148,6 +151,8 @@ func (p IssueTrace) GetTablesInfo() []dal.Tabler {
 	return []dal.Tabler{
 		&models.IssueAssigneeHistory{},
 		&models.IssueStatusHistory{},
+		&models.SprintIssueEvent{},
+		&models.SprintDailySnapshot{},
 	}
 }
 
@@ -165,12 +170,14 @@ func (p IssueTrace) MakeMetricPluginPipelinePlanV200(projectName string, options
 			{
 				Plugin: "issue_trace",
 				Options: map[string]interface{}{
-					"projectName": projectName,
-					"scopeIds":    op.ScopeIds,
+					"projectName":      projectName,
+					"scopeIds":         op.ScopeIds,
+					"storyPointFields": op.StoryPointFields,
 				},
 				Subtasks: []string{
 					"ConvertIssueStatusHistory",
 					"ConvertIssueAssigneeHistory",
+					"ConvertSprintSnapshots",
 				},
 			},
 		},
@@ -0,0 +1,74 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type AddSprintHistoryTables struct {
+}
+
+func (*AddSprintHistoryTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &SprintIssueEvent20241023{}, &SprintDailySnapshot20241023{})
+}
+
+func (*AddSprintHistoryTables) Version() uint64 {
+	return 20241023091500
+}
+
+func (*AddSprintHistoryTables) Name() string {
+	return "add sprint_issue_events and sprint_daily_snapshots"
+}
+
+type SprintIssueEvent20241023 struct {
+	archived.NoPKModel
+	SprintId   string    `gorm:"primaryKey;type:varchar(255)"`
+	IssueId    string    `gorm:"primaryKey;type:varchar(255)"`
+	EventType  string    `gorm:"primaryKey;type:varchar(20)"`
+	EventDate  time.Time `gorm:"primaryKey"`
+	StoryPoint *float64
+}
+
+func (SprintIssueEvent20241023) TableName() string {
+	return "sprint_issue_events"
+}
+
+type SprintDailySnapshot20241023 struct {
+	archived.NoPKModel
+	SprintId             string    `gorm:"primaryKey;type:varchar(255)"`
+	Date                 time.Time `gorm:"primaryKey"`
+	CommittedIssues      int
+	CommittedStoryPoints float64
+	ScopeIssues          int
+	ScopeStoryPoints     float64
+	AddedIssues          int
+	RemovedIssues        int
+	CompletedIssues      int
+	CompletedStoryPoints float64
+	RemainingStoryPoints float64
+}
+
+func (SprintDailySnapshot20241023) TableName() string {
+	return "sprint_daily_snapshots"
+}
@@ -0,0 +1,46 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// SprintDailySnapshot records the scope and progress of a sprint at the end of each day (UTC) between its start
+// and end, the basis of burndown charts. Completed issues count the issues resolved in the sprint so far.
+// handled by ConvertSprintSnapshots task
+type SprintDailySnapshot struct {
+	common.NoPKModel
+	SprintId             string    `gorm:"primaryKey;type:varchar(255)"`
+	Date                 time.Time `gorm:"primaryKey"`
+	CommittedIssues      int
+	CommittedStoryPoints float64
+	ScopeIssues          int
+	ScopeStoryPoints     float64
+	AddedIssues          int
+	RemovedIssues        int
+	CompletedIssues      int
+	CompletedStoryPoints float64
+	RemainingStoryPoints float64
+}
+
+func (SprintDailySnapshot) TableName() string {
+	return "sprint_daily_snapshots"
+}
@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	SPRINT_ISSUE_COMMITTED    = "COMMITTED"
+	SPRINT_ISSUE_ADDED        = "ADDED"
+	SPRINT_ISSUE_REMOVED      = "REMOVED"
+	SPRINT_ISSUE_COMPLETED    = "COMPLETED"
+	SPRINT_ISSUE_CARRIED_OVER = "CARRIED_OVER"
+)
+
+// SprintIssueEvent records how an issue entered, left or finished a sprint, replayed from the sprint changelogs.
+// An issue is committed if it was in the sprint when the sprint started, and carried over if it was unfinished in
+// the sprint when the sprint ended. StoryPoint is the estimation of the issue at the time of the event.
+// handled by ConvertSprintSnapshots task
+type SprintIssueEvent struct {
+	common.NoPKModel
+	SprintId   string    `gorm:"primaryKey;type:varchar(255)"`
+	IssueId    string    `gorm:"primaryKey;type:varchar(255)"`
+	EventType  string    `gorm:"primaryKey;type:varchar(20)"`
+	EventDate  time.Time `gorm:"primaryKey"`
+	StoryPoint *float64
+}
+
+func (SprintIssueEvent) TableName() string {
+	return "sprint_issue_events"
+}
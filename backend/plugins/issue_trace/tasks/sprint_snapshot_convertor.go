@@ -0,0 +1,415 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/utils"
+)
+
+var ConvertSprintSnapshotsMeta = plugin.SubTaskMeta{
+	Name:             "ConvertSprintSnapshots",
+	EntryPoint:       ConvertSprintSnapshots,
+	EnabledByDefault: true,
+	Description:      "Replay sprint and story point changelogs to sprint issue events and daily sprint snapshots",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET},
+	DependencyTables: []string{
+		ticket.Sprint{}.TableName(),
+		ticket.BoardSprint{}.TableName(),
+		ticket.SprintIssue{}.TableName(),
+		ticket.Issue{}.TableName(),
+		ticket.IssueChangelogs{}.TableName(),
+	},
+	ProductTables: []string{models.SprintIssueEvent{}.TableName(), models.SprintDailySnapshot{}.TableName()},
+}
+
+const sprintChangelogField = "Sprint"
+
+type sprintToReplay struct {
+	Id            string
+	StartedDate   *time.Time
+	EndedDate     *time.Time
+	CompletedDate *time.Time
+}
+
+type sprintIssueToReplay struct {
+	Id             string
+	StoryPoint     *float64
+	ResolutionDate *time.Time
+}
+
+type sprintChangelog struct {
+	IssueId           string
+	FieldName         string
+	OriginalFromValue string
+	OriginalToValue   string
+	CreatedDate       time.Time
+}
+
+// issueTimeline holds the sprints and story points of an issue over time
+type issueTimeline struct {
+	issue *sprintIssueToReplay
+	// sprints before the first sprint change, the current sprints if never changed
+	initialSprints map[string]bool
+	sprintChanges  []sprintChangelog
+	// story point before the first story point change, the current one if never changed
+	initialStoryPoint *float64
+	storyPointChanges []sprintChangelog
+}
+
+func ConvertSprintSnapshots(taskCtx plugin.SubTaskContext) errors.Error {
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*TaskData)
+	db := taskCtx.GetDal()
+	if len(data.ScopeIds) == 0 {
+		return nil
+	}
+	storyPointFields := data.Options.StoryPointFields
+	if len(storyPointFields) == 0 {
+		storyPointFields = DefaultStoryPointFields
+	}
+
+	var sprints []*sprintToReplay
+	err := db.All(
+		&sprints,
+		dal.Select("DISTINCT s.id, s.started_date, s.ended_date, s.completed_date"),
+		dal.From("sprints s"),
+		dal.Join("INNER JOIN board_sprints bs ON bs.sprint_id = s.id"),
+		dal.Where("bs.board_id IN ? AND s.started_date IS NOT NULL", data.ScopeIds),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load sprints")
+	}
+	if len(sprints) == 0 {
+		return nil
+	}
+	sprintIds := make([]string, 0, len(sprints))
+	for _, sprint := range sprints {
+		sprintIds = append(sprintIds, sprint.Id)
+	}
+
+	var issues []*sprintIssueToReplay
+	err = db.All(
+		&issues,
+		dal.Select("DISTINCT i.id, i.story_point, i.resolution_date"),
+		dal.From("issues i"),
+		dal.Join("INNER JOIN board_issues bi ON bi.issue_id = i.id"),
+		dal.Where("bi.board_id IN ?", data.ScopeIds),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load issues")
+	}
+	var sprintIssues []ticket.SprintIssue
+	err = db.All(&sprintIssues, dal.Where("sprint_id IN ?", sprintIds))
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load sprint_issues")
+	}
+	var changelogs []sprintChangelog
+	err = db.All(
+		&changelogs,
+		dal.Select("DISTINCT ic.issue_id, ic.field_name, ic.original_from_value, ic.original_to_value, ic.created_date"),
+		dal.From("issue_changelogs ic"),
+		dal.Join("INNER JOIN board_issues bi ON bi.issue_id = ic.issue_id"),
+		dal.Where("bi.board_id IN ? AND ic.field_name IN ?", data.ScopeIds, append([]string{sprintChangelogField}, storyPointFields...)),
+		dal.Orderby("ic.issue_id, ic.created_date"),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load sprint changelogs")
+	}
+	timelines, issuesBySprint := buildIssueTimelines(issues, sprintIssues, changelogs)
+
+	// Clear previous results of the sprints
+	err = db.Delete(&models.SprintIssueEvent{}, dal.Where("sprint_id IN ?", sprintIds))
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to delete previous sprint_issue_events")
+	}
+	err = db.Delete(&models.SprintDailySnapshot{}, dal.Where("sprint_id IN ?", sprintIds))
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to delete previous sprint_daily_snapshots")
+	}
+	inserter := helper.NewBatchSaveDivider(taskCtx, utils.BATCH_SIZE, "", "")
+	defer inserter.Close()
+	eventInserter, err := inserter.ForType(reflect.TypeOf(&models.SprintIssueEvent{}))
+	if err != nil {
+		return err
+	}
+	snapshotInserter, err := inserter.ForType(reflect.TypeOf(&models.SprintDailySnapshot{}))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, sprint := range sprints {
+		if ctxErr := utils.CheckCancel(taskCtx); ctxErr != nil {
+			return ctxErr
+		}
+		var sprintTimelines []*issueTimeline
+		for issueId := range issuesBySprint[sprint.Id] {
+			if timeline, ok := timelines[issueId]; ok {
+				sprintTimelines = append(sprintTimelines, timeline)
+			}
+		}
+		events, snapshots := replaySprint(sprint, sprintTimelines, now)
+		for _, event := range events {
+			err = eventInserter.Add(event)
+			if err != nil {
+				return err
+			}
+		}
+		for _, snapshot := range snapshots {
+			err = snapshotInserter.Add(snapshot)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	logger.Info("replayed %d sprints of boards %s", len(sprints), data.ScopeIds)
+	return nil
+}
+
+// buildIssueTimelines returns the timelines of the issues, and the issues that have ever been in each sprint
+func buildIssueTimelines(
+	issues []*sprintIssueToReplay,
+	sprintIssues []ticket.SprintIssue,
+	changelogs []sprintChangelog,
+) (map[string]*issueTimeline, map[string]map[string]bool) {
+	timelines := make(map[string]*issueTimeline, len(issues))
+	for _, issue := range issues {
+		timelines[issue.Id] = &issueTimeline{
+			issue:             issue,
+			initialSprints:    make(map[string]bool),
+			initialStoryPoint: issue.StoryPoint,
+		}
+	}
+	issuesBySprint := make(map[string]map[string]bool)
+	addIssueToSprints := func(issueId string, sprintIds map[string]bool) {
+		for sprintId := range sprintIds {
+			if issuesBySprint[sprintId] == nil {
+				issuesBySprint[sprintId] = make(map[string]bool)
+			}
+			issuesBySprint[sprintId][issueId] = true
+		}
+	}
+	for _, sprintIssue := range sprintIssues {
+		if timeline, ok := timelines[sprintIssue.IssueId]; ok {
+			timeline.initialSprints[sprintIssue.SprintId] = true
+			addIssueToSprints(sprintIssue.IssueId, timeline.initialSprints)
+		}
+	}
+	for _, changelog := range changelogs {
+		timeline, ok := timelines[changelog.IssueId]
+		if !ok {
+			continue
+		}
+		if changelog.FieldName == sprintChangelogField {
+			if len(timeline.sprintChanges) == 0 {
+				timeline.initialSprints = parseSprintIds(changelog.OriginalFromValue)
+			}
+			timeline.sprintChanges = append(timeline.sprintChanges, changelog)
+			addIssueToSprints(changelog.IssueId, parseSprintIds(changelog.OriginalFromValue))
+			addIssueToSprints(changelog.IssueId, parseSprintIds(changelog.OriginalToValue))
+		} else {
+			if len(timeline.storyPointChanges) == 0 {
+				timeline.initialStoryPoint = parseStoryPoint(changelog.OriginalFromValue)
+			}
+			timeline.storyPointChanges = append(timeline.storyPointChanges, changelog)
+		}
+	}
+	return timelines, issuesBySprint
+}
+
+// inSprintAt checks if the issue was in the sprint at the time, changes made at the time are taken into account
+func (t *issueTimeline) inSprintAt(sprintId string, at time.Time) bool {
+	sprints := t.initialSprints
+	for _, change := range t.sprintChanges {
+		if change.CreatedDate.After(at) {
+			break
+		}
+		sprints = parseSprintIds(change.OriginalToValue)
+	}
+	return sprints[sprintId]
+}
+
+func (t *issueTimeline) storyPointAt(at time.Time) *float64 {
+	storyPoint := t.initialStoryPoint
+	for _, change := range t.storyPointChanges {
+		if change.CreatedDate.After(at) {
+			break
+		}
+		storyPoint = parseStoryPoint(change.OriginalToValue)
+	}
+	return storyPoint
+}
+
+// resolvedBy checks if the issue was resolved at the time
+func (t *issueTimeline) resolvedBy(at time.Time) bool {
+	return t.issue.ResolutionDate != nil && !t.issue.ResolutionDate.After(at)
+}
+
+// completedBetween checks if the issue was resolved in the sprint between from and to
+func (t *issueTimeline) completedBetween(sprintId string, from, to time.Time) bool {
+	resolved := t.issue.ResolutionDate
+	return resolved != nil && !resolved.Before(from) && !resolved.After(to) && t.inSprintAt(sprintId, *resolved)
+}
+
+// replaySprint generates the issue events and daily snapshots of a started sprint. The sprint is considered
+// in progress until its completed date, or its end date if it was never completed
+func replaySprint(
+	sprint *sprintToReplay,
+	timelines []*issueTimeline,
+	now time.Time,
+) ([]*models.SprintIssueEvent, []*models.SprintDailySnapshot) {
+	start := *sprint.StartedDate
+	end := sprint.CompletedDate
+	if end == nil {
+		end = sprint.EndedDate
+	}
+	ended := end != nil && !end.After(now)
+	limit := now
+	if ended {
+		limit = *end
+	}
+	if limit.Before(start) {
+		return nil, nil
+	}
+	sort.Slice(timelines, func(i, j int) bool { return timelines[i].issue.Id < timelines[j].issue.Id })
+
+	var events []*models.SprintIssueEvent
+	addEvent := func(timeline *issueTimeline, eventType string, at time.Time) {
+		events = append(events, &models.SprintIssueEvent{
+			SprintId:   sprint.Id,
+			IssueId:    timeline.issue.Id,
+			EventType:  eventType,
+			EventDate:  at,
+			StoryPoint: timeline.storyPointAt(at),
+		})
+	}
+	committed := make(map[*issueTimeline]bool)
+	for _, timeline := range timelines {
+		if timeline.inSprintAt(sprint.Id, start) {
+			committed[timeline] = true
+			addEvent(timeline, models.SPRINT_ISSUE_COMMITTED, start)
+		}
+		wasIn := committed[timeline]
+		for _, change := range timeline.sprintChanges {
+			if !change.CreatedDate.After(start) {
+				continue
+			}
+			if change.CreatedDate.After(limit) {
+				break
+			}
+			isIn := parseSprintIds(change.OriginalToValue)[sprint.Id]
+			if !wasIn && isIn {
+				addEvent(timeline, models.SPRINT_ISSUE_ADDED, change.CreatedDate)
+			} else if wasIn && !isIn {
+				addEvent(timeline, models.SPRINT_ISSUE_REMOVED, change.CreatedDate)
+			}
+			wasIn = isIn
+		}
+		if timeline.completedBetween(sprint.Id, start, limit) {
+			addEvent(timeline, models.SPRINT_ISSUE_COMPLETED, *timeline.issue.ResolutionDate)
+		} else if ended && timeline.inSprintAt(sprint.Id, limit) && !timeline.resolvedBy(limit) {
+			addEvent(timeline, models.SPRINT_ISSUE_CARRIED_OVER, limit)
+		}
+	}
+
+	var snapshots []*models.SprintDailySnapshot
+	var committedStoryPoints float64
+	for timeline := range committed {
+		committedStoryPoints += valueOf(timeline.storyPointAt(start))
+	}
+	for day := truncateToDay(start); day.Before(limit); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		if dayEnd.After(limit) {
+			dayEnd = limit
+		}
+		snapshot := &models.SprintDailySnapshot{
+			SprintId:             sprint.Id,
+			Date:                 day,
+			CommittedIssues:      len(committed),
+			CommittedStoryPoints: committedStoryPoints,
+		}
+		for _, timeline := range timelines {
+			storyPoint := valueOf(timeline.storyPointAt(dayEnd))
+			if timeline.completedBetween(sprint.Id, start, dayEnd) {
+				snapshot.CompletedIssues++
+				snapshot.CompletedStoryPoints += valueOf(timeline.storyPointAt(*timeline.issue.ResolutionDate))
+			}
+			if timeline.inSprintAt(sprint.Id, dayEnd) {
+				snapshot.ScopeIssues++
+				snapshot.ScopeStoryPoints += storyPoint
+				if !timeline.resolvedBy(dayEnd) {
+					snapshot.RemainingStoryPoints += storyPoint
+				}
+			}
+		}
+		for _, event := range events {
+			if event.EventDate.Before(day) || !event.EventDate.Before(day.AddDate(0, 0, 1)) {
+				continue
+			}
+			switch event.EventType {
+			case models.SPRINT_ISSUE_ADDED:
+				snapshot.AddedIssues++
+			case models.SPRINT_ISSUE_REMOVED:
+				snapshot.RemovedIssues++
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return events, snapshots
+}
+
+func parseSprintIds(value string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+func parseStoryPoint(value string) *float64 {
+	storyPoint, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return &storyPoint
+}
+
+func valueOf(storyPoint *float64) float64 {
+	if storyPoint == nil {
+		return 0
+	}
+	return *storyPoint
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
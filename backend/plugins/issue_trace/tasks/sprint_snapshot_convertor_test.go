@@ -0,0 +1,127 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaySprint(t *testing.T) {
+	date := func(value string) time.Time {
+		d, err := time.Parse(time.RFC3339, value)
+		assert.Nil(t, err)
+		return d
+	}
+	ptr := func(value string) *time.Time {
+		d := date(value)
+		return &d
+	}
+	points := func(v float64) *float64 { return &v }
+	sprint := &sprintToReplay{
+		Id:            "s1",
+		StartedDate:   ptr("2024-10-01T09:00:00Z"),
+		EndedDate:     ptr("2024-10-04T09:00:00Z"),
+		CompletedDate: ptr("2024-10-03T18:00:00Z"),
+	}
+	issues := []*sprintIssueToReplay{
+		// committed and completed
+		{Id: "i1", StoryPoint: points(3), ResolutionDate: ptr("2024-10-02T10:00:00Z")},
+		// committed, re-estimated and carried over
+		{Id: "i2", StoryPoint: points(8)},
+		// added, then completed
+		{Id: "i3", StoryPoint: points(2), ResolutionDate: ptr("2024-10-03T10:00:00Z")},
+		// committed then removed
+		{Id: "i4", StoryPoint: points(1)},
+		// planned into the next sprint before the sprint started
+		{Id: "i5", StoryPoint: points(5)},
+	}
+	sprintIssues := []ticket.SprintIssue{
+		{SprintId: "s1", IssueId: "i1"},
+		{SprintId: "s1", IssueId: "i2"},
+		{SprintId: "s1", IssueId: "i3"},
+		{SprintId: "s2", IssueId: "i2"},
+		{SprintId: "s2", IssueId: "i4"},
+		{SprintId: "s2", IssueId: "i5"},
+	}
+	changelogs := []sprintChangelog{
+		{IssueId: "i2", FieldName: "Story Points", OriginalFromValue: "5", OriginalToValue: "8", CreatedDate: date("2024-10-02T12:00:00Z")},
+		{IssueId: "i2", FieldName: sprintChangelogField, OriginalFromValue: "s1", OriginalToValue: "s1, s2", CreatedDate: date("2024-10-03T18:00:01Z")},
+		{IssueId: "i3", FieldName: sprintChangelogField, OriginalFromValue: "", OriginalToValue: "s1", CreatedDate: date("2024-10-02T08:00:00Z")},
+		{IssueId: "i4", FieldName: sprintChangelogField, OriginalFromValue: "s1", OriginalToValue: "s2", CreatedDate: date("2024-10-01T15:00:00Z")},
+		{IssueId: "i5", FieldName: sprintChangelogField, OriginalFromValue: "", OriginalToValue: "s2", CreatedDate: date("2024-09-30T15:00:00Z")},
+	}
+	timelines, issuesBySprint := buildIssueTimelines(issues, sprintIssues, changelogs)
+	assert.Equal(t, map[string]bool{"i1": true, "i2": true, "i3": true, "i4": true}, issuesBySprint["s1"])
+
+	var sprintTimelines []*issueTimeline
+	for issueId := range issuesBySprint["s1"] {
+		sprintTimelines = append(sprintTimelines, timelines[issueId])
+	}
+	events, snapshots := replaySprint(sprint, sprintTimelines, date("2024-10-10T00:00:00Z"))
+
+	type event struct {
+		issueId    string
+		eventType  string
+		storyPoint float64
+	}
+	var actualEvents []event
+	for _, e := range events {
+		actualEvents = append(actualEvents, event{e.IssueId, e.EventType, *e.StoryPoint})
+	}
+	assert.Equal(t, []event{
+		{"i1", models.SPRINT_ISSUE_COMMITTED, 3},
+		{"i1", models.SPRINT_ISSUE_COMPLETED, 3},
+		{"i2", models.SPRINT_ISSUE_COMMITTED, 5},
+		{"i2", models.SPRINT_ISSUE_CARRIED_OVER, 8},
+		{"i3", models.SPRINT_ISSUE_ADDED, 2},
+		{"i3", models.SPRINT_ISSUE_COMPLETED, 2},
+		{"i4", models.SPRINT_ISSUE_COMMITTED, 1},
+		{"i4", models.SPRINT_ISSUE_REMOVED, 1},
+	}, actualEvents)
+
+	assert.Len(t, snapshots, 3)
+	assert.Equal(t, models.SprintDailySnapshot{
+		SprintId:             "s1",
+		Date:                 date("2024-10-01T00:00:00Z"),
+		CommittedIssues:      3,
+		CommittedStoryPoints: 9,
+		ScopeIssues:          2,
+		ScopeStoryPoints:     8,
+		RemovedIssues:        1,
+		RemainingStoryPoints: 8,
+	}, *snapshots[0])
+	assert.Equal(t, models.SprintDailySnapshot{
+		SprintId:             "s1",
+		Date:                 date("2024-10-02T00:00:00Z"),
+		CommittedIssues:      3,
+		CommittedStoryPoints: 9,
+		ScopeIssues:          3,
+		ScopeStoryPoints:     13,
+		AddedIssues:          1,
+		CompletedIssues:      1,
+		CompletedStoryPoints: 3,
+		RemainingStoryPoints: 10,
+	}, *snapshots[1])
+	assert.Equal(t, 2, snapshots[2].CompletedIssues)
+	assert.Equal(t, float64(8), snapshots[2].RemainingStoryPoints)
+}
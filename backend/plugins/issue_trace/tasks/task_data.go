@@ -22,8 +22,13 @@ type Options struct {
 	Plugin      string   `json:"plugin"`   // jira
 	ScopeIds    []string `json:"scopeIds"` // 68
 	ProjectName string   `json:"projectName"`
+	// StoryPointFields are the changelog field names of story points, DefaultStoryPointFields if empty
+	StoryPointFields []string `json:"storyPointFields"`
 }
 
+// DefaultStoryPointFields are the story point fields of Jira (classic and next-gen projects) and TAPD
+var DefaultStoryPointFields = []string{"Story Points", "Story point estimate", "size"}
+
 // TaskData converted parameter
 type TaskData struct {
 	Options     Options
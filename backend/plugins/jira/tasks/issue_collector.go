@@ -43,6 +43,11 @@ var CollectIssuesMeta = plugin.SubTaskMeta{
 	EnabledByDefault: true,
 	Description:      "collect Jira issues, supports both timeFilter and diffSync.",
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET, plugin.DOMAIN_TYPE_CROSS},
+	RetryPolicy: &plugin.SubTaskRetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+		MaxBackoff:  10 * time.Minute,
+	},
 }
 
 func CollectIssues(taskCtx plugin.SubTaskContext) errors.Error {
@@ -24,6 +24,7 @@ import (
 	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"reflect"
+	"time"
 )
 
 type store interface {
@@ -34,6 +35,16 @@ type store interface {
 	findAllProjectMapping() ([]projectMapping, errors.Error)
 	deleteAll(i interface{}) errors.Error
 	save(items []interface{}) errors.Error
+	findUserAccountSuggestions(status string, minConfidence float64, limit, offset int) ([]userAccountSuggestion, int64, errors.Error)
+	approveUserAccountSuggestions(accountIds []string, minConfidence float64) (int, errors.Error)
+	rejectUserAccountSuggestions(accountIds []string) (int, errors.Error)
+	exists(table string, id string) (bool, errors.Error)
+	findSubTeamIds(teamId string) ([]string, errors.Error)
+	findTeamMemberships(clauses ...dal.Clause) ([]crossdomain.TeamMembership, errors.Error)
+	findTeamMembership(id uint64) (*crossdomain.TeamMembership, errors.Error)
+	saveTeamMembership(membership *crossdomain.TeamMembership) errors.Error
+	deleteTeamMembership(id uint64) errors.Error
+	syncTeamMemberships(teamUsers []*crossdomain.TeamUser, now time.Time) errors.Error
 }
 
 type dbStore struct {
@@ -122,3 +133,220 @@ func (d *dbStore) save(items []interface{}) errors.Error {
 	d.driver.Close()
 	return nil
 }
+
+func (d *dbStore) findUserAccountSuggestions(status string, minConfidence float64, limit, offset int) ([]userAccountSuggestion, int64, errors.Error) {
+	clauses := []dal.Clause{
+		dal.From("user_account_suggestions s"),
+		dal.Join("LEFT JOIN accounts a ON a.id = s.account_id"),
+		dal.Join("LEFT JOIN users u ON u.id = s.user_id"),
+		dal.Where("s.status = ? AND s.confidence >= ?", status, minConfidence),
+	}
+	count, err := d.db.Count(clauses...)
+	if err != nil {
+		return nil, 0, err
+	}
+	var suggestions []userAccountSuggestion
+	err = d.db.All(
+		&suggestions,
+		append(
+			clauses,
+			dal.Select(`s.account_id, s.user_id, s.confidence, s.reasons, s.status,
+				a.email AS account_email, a.full_name AS account_full_name, a.user_name AS account_user_name,
+				u.email AS user_email, u.name AS user_name`),
+			dal.Orderby("s.user_id, s.confidence DESC, s.account_id"),
+			dal.Limit(limit),
+			dal.Offset(offset),
+		)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	return suggestions, count, nil
+}
+
+// approveUserAccountSuggestions maps the accounts of the pending suggestions, either the given ones or the ones
+// with at least minConfidence, to their users, creating the users proposed for unknown identities from the accounts
+// they are identified by. Either all the suggestions are approved or none
+func (d *dbStore) approveUserAccountSuggestions(accountIds []string, minConfidence float64) (int, errors.Error) {
+	tx := d.db.Begin()
+	count, err := approveUserAccountSuggestions(tx, accountIds, minConfidence)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return 0, errors.Default.Wrap(rollbackErr, err.Error())
+		}
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+func approveUserAccountSuggestions(tx dal.Dal, accountIds []string, minConfidence float64) (int, errors.Error) {
+	clauses := []dal.Clause{dal.Where("status = ?", crossdomain.USER_ACCOUNT_SUGGESTION_PENDING)}
+	if len(accountIds) > 0 {
+		clauses = append(clauses, dal.Where("account_id IN ?", accountIds))
+	} else {
+		clauses = append(clauses, dal.Where("confidence >= ?", minConfidence))
+	}
+	var suggestions []crossdomain.UserAccountSuggestion
+	err := tx.All(&suggestions, clauses...)
+	if err != nil {
+		return 0, err
+	}
+	for i := range suggestions {
+		suggestion := &suggestions[i]
+		count, err := tx.Count(dal.From(&crossdomain.User{}), dal.Where("id = ?", suggestion.UserId))
+		if err != nil {
+			return 0, err
+		}
+		if count == 0 {
+			// a new user is identified by the anchor account of its cluster, whichever account is approved first
+			account := &crossdomain.Account{}
+			err = tx.First(account, dal.Where("id = ?", suggestion.UserId))
+			if err != nil {
+				return 0, errors.Default.Wrap(err, "error loading the account identifying the suggested user")
+			}
+			newUser := &crossdomain.User{Email: account.Email, Name: account.FullName}
+			newUser.Id = suggestion.UserId
+			if newUser.Name == "" {
+				newUser.Name = account.UserName
+			}
+			err = tx.Create(newUser)
+			if err != nil {
+				return 0, err
+			}
+		}
+		err = tx.CreateOrUpdate(&crossdomain.UserAccount{UserId: suggestion.UserId, AccountId: suggestion.AccountId})
+		if err != nil {
+			return 0, err
+		}
+		suggestion.Status = crossdomain.USER_ACCOUNT_SUGGESTION_APPROVED
+		err = tx.Update(suggestion)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(suggestions), nil
+}
+
+// rejectUserAccountSuggestions keeps the rejected suggestions so that they are not suggested again
+func (d *dbStore) rejectUserAccountSuggestions(accountIds []string) (int, errors.Error) {
+	var suggestions []crossdomain.UserAccountSuggestion
+	err := d.db.All(
+		&suggestions,
+		dal.Where("status = ? AND account_id IN ?", crossdomain.USER_ACCOUNT_SUGGESTION_PENDING, accountIds),
+	)
+	if err != nil {
+		return 0, err
+	}
+	for i := range suggestions {
+		suggestions[i].Status = crossdomain.USER_ACCOUNT_SUGGESTION_REJECTED
+		err = d.db.Update(&suggestions[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(suggestions), nil
+}
+
+func (d *dbStore) exists(table string, id string) (bool, errors.Error) {
+	count, err := d.db.Count(dal.From(table), dal.Where("id = ?", id))
+	return count > 0, err
+}
+
+// findSubTeamIds returns the team and all the teams below it in the hierarchy
+func (d *dbStore) findSubTeamIds(teamId string) ([]string, errors.Error) {
+	var teams []crossdomain.Team
+	err := d.db.All(&teams, dal.Select("id, parent_id"))
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[string][]string)
+	for _, t := range teams {
+		children[t.ParentId] = append(children[t.ParentId], t.Id)
+	}
+	teamIds := []string{teamId}
+	visited := map[string]bool{teamId: true}
+	for i := 0; i < len(teamIds); i++ {
+		for _, child := range children[teamIds[i]] {
+			if !visited[child] {
+				visited[child] = true
+				teamIds = append(teamIds, child)
+			}
+		}
+	}
+	return teamIds, nil
+}
+
+func (d *dbStore) findTeamMemberships(clauses ...dal.Clause) ([]crossdomain.TeamMembership, errors.Error) {
+	var memberships []crossdomain.TeamMembership
+	err := d.db.All(&memberships, append(clauses, dal.Orderby("team_id, user_id, start_date, id"))...)
+	return memberships, err
+}
+
+func (d *dbStore) findTeamMembership(id uint64) (*crossdomain.TeamMembership, errors.Error) {
+	membership := &crossdomain.TeamMembership{}
+	err := d.db.First(membership, dal.Where("id = ?", id))
+	if err != nil {
+		if d.db.IsErrorNotFound(err) {
+			return nil, errors.NotFound.New("team membership not found")
+		}
+		return nil, err
+	}
+	return membership, nil
+}
+
+func (d *dbStore) saveTeamMembership(membership *crossdomain.TeamMembership) errors.Error {
+	if membership.Id == 0 {
+		return d.db.Create(membership)
+	}
+	return d.db.Update(membership)
+}
+
+func (d *dbStore) deleteTeamMembership(id uint64) errors.Error {
+	return d.db.Delete(&crossdomain.TeamMembership{}, dal.Where("id = ?", id))
+}
+
+// syncTeamMemberships makes team_memberships follow the current team_users: the users who left a team leave it
+// now, and the users who joined a team join it now, or have always been in it if nothing is known about them yet
+func (d *dbStore) syncTeamMemberships(teamUsers []*crossdomain.TeamUser, now time.Time) errors.Error {
+	var memberships []crossdomain.TeamMembership
+	err := d.db.All(&memberships)
+	if err != nil {
+		return err
+	}
+	current := make(map[[2]string]bool, len(teamUsers))
+	for _, teamUser := range teamUsers {
+		current[[2]string{teamUser.TeamId, teamUser.UserId}] = true
+	}
+	known := make(map[string]bool)
+	effective := make(map[[2]string]bool)
+	for i := range memberships {
+		membership := &memberships[i]
+		known[membership.UserId] = true
+		if !membership.IsEffectiveAt(now) {
+			continue
+		}
+		pair := [2]string{membership.TeamId, membership.UserId}
+		effective[pair] = true
+		if !current[pair] {
+			membership.EndDate = &now
+			err = d.db.Update(membership)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, teamUser := range teamUsers {
+		if effective[[2]string{teamUser.TeamId, teamUser.UserId}] {
+			continue
+		}
+		membership := &crossdomain.TeamMembership{TeamId: teamUser.TeamId, UserId: teamUser.UserId}
+		if known[teamUser.UserId] {
+			membership.StartDate = &now
+		}
+		err = d.db.Create(membership)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+type teamMembershipInput struct {
+	TeamId    string  `json:"teamId"`
+	UserId    string  `json:"userId"`
+	StartDate *string `json:"startDate"`
+	EndDate   *string `json:"endDate"`
+}
+
+// GetTeamMembers returns the memberships of the team effective at the given time
+// @Summary      Get the members of a team
+// @Description  get the memberships of a team, and of the teams below it if includeSubTeams is true, effective at the given time
+// @Tags 		 plugins/org
+// @Param        teamId path string true "team id"
+// @Param        at query string false "date (2006-01-02) or time (RFC3339), now by default"
+// @Param        includeSubTeams query bool false "include the members of the sub teams"
+// @Produce      json
+// @Success      200  {object} []crossdomain.TeamMembership
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/teams/{teamId}/members [get]
+func (h *Handlers) GetTeamMembers(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	teamId := input.Params["teamId"]
+	at := time.Now()
+	if value := input.Query.Get("at"); value != "" {
+		date, err := parseMembershipDate(value)
+		if err != nil {
+			return nil, err
+		}
+		at = *date
+	}
+	teamIds := []string{teamId}
+	if input.Query.Get("includeSubTeams") == "true" {
+		var err errors.Error
+		teamIds, err = h.store.findSubTeamIds(teamId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	memberships, err := h.store.findTeamMemberships(
+		dal.Where("team_id IN ?", teamIds),
+		dal.Where("(start_date IS NULL OR start_date <= ?) AND (end_date IS NULL OR end_date > ?)", at, at),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: memberships, Status: http.StatusOK}, nil
+}
+
+// GetUserMemberships returns the team history of a user
+// @Summary      Get the team memberships of a user
+// @Description  get all the team memberships of a user, past, current and future
+// @Tags 		 plugins/org
+// @Param        userId path string true "user id"
+// @Produce      json
+// @Success      200  {object} []crossdomain.TeamMembership
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/users/{userId}/memberships [get]
+func (h *Handlers) GetUserMemberships(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	memberships, err := h.store.findTeamMemberships(dal.Where("user_id = ?", input.Params["userId"]))
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: memberships, Status: http.StatusOK}, nil
+}
+
+// CreateTeamMembership adds a user to a team for a period of time
+// @Summary      Create a team membership
+// @Description  add a user to a team from startDate to endDate, either of them may be omitted to leave the period open
+// @Tags 		 plugins/org
+// @Accept       json
+// @Param        body body teamMembershipInput true "the membership"
+// @Produce      json
+// @Success      201  {object} crossdomain.TeamMembership
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/team_memberships [post]
+func (h *Handlers) CreateTeamMembership(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	body := &teamMembershipInput{}
+	err := helper.Decode(input.Body, body, nil)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid body")
+	}
+	membership := &crossdomain.TeamMembership{TeamId: body.TeamId, UserId: body.UserId}
+	if body.StartDate != nil {
+		if membership.StartDate, err = parseMembershipDate(*body.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	if body.EndDate != nil {
+		if membership.EndDate, err = parseMembershipDate(*body.EndDate); err != nil {
+			return nil, err
+		}
+	}
+	for table, id := range map[string]string{"teams": membership.TeamId, "users": membership.UserId} {
+		if id == "" {
+			return nil, errors.BadInput.New("teamId and userId are required")
+		}
+		found, err := h.store.exists(table, id)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, errors.NotFound.New(fmt.Sprintf("%s %s not found", table, id))
+		}
+	}
+	err = h.validateTeamMembership(membership)
+	if err != nil {
+		return nil, err
+	}
+	err = h.store.saveTeamMembership(membership)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: membership, Status: http.StatusCreated}, nil
+}
+
+// UpdateTeamMembership changes the period of a team membership, typically to end it when the user leaves the team
+// @Summary      Update a team membership
+// @Description  update the startDate and/or endDate of a membership, null reopens the period on that side
+// @Tags 		 plugins/org
+// @Accept       json
+// @Param        id path int true "membership id"
+// @Param        body body teamMembershipInput true "startDate and/or endDate"
+// @Produce      json
+// @Success      200  {object} crossdomain.TeamMembership
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/team_memberships/{id} [patch]
+func (h *Handlers) UpdateTeamMembership(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	membership, err := h.getTeamMembership(input)
+	if err != nil {
+		return nil, err
+	}
+	for key, date := range map[string]**time.Time{"startDate": &membership.StartDate, "endDate": &membership.EndDate} {
+		value, ok := input.Body[key]
+		if !ok {
+			continue
+		}
+		*date = nil
+		if value != nil {
+			s, isString := value.(string)
+			if !isString {
+				return nil, errors.BadInput.New(key + " should be a string")
+			}
+			if *date, err = parseMembershipDate(s); err != nil {
+				return nil, err
+			}
+		}
+	}
+	err = h.validateTeamMembership(membership)
+	if err != nil {
+		return nil, err
+	}
+	err = h.store.saveTeamMembership(membership)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: membership, Status: http.StatusOK}, nil
+}
+
+// DeleteTeamMembership removes a team membership recorded by mistake
+// @Summary      Delete a team membership
+// @Description  delete a membership, to end a membership update its endDate instead
+// @Tags 		 plugins/org
+// @Param        id path int true "membership id"
+// @Produce      json
+// @Success      200  {object} crossdomain.TeamMembership
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/team_memberships/{id} [delete]
+func (h *Handlers) DeleteTeamMembership(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	membership, err := h.getTeamMembership(input)
+	if err != nil {
+		return nil, err
+	}
+	err = h.store.deleteTeamMembership(membership.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: membership, Status: http.StatusOK}, nil
+}
+
+func (h *Handlers) getTeamMembership(input *plugin.ApiResourceInput) (*crossdomain.TeamMembership, errors.Error) {
+	id, err := strconv.ParseUint(input.Params["id"], 10, 64)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid membership id")
+	}
+	return h.store.findTeamMembership(id)
+}
+
+// validateTeamMembership makes sure the period is not empty and does not overlap another membership of the user
+// in the same team
+func (h *Handlers) validateTeamMembership(membership *crossdomain.TeamMembership) errors.Error {
+	if membership.StartDate != nil && membership.EndDate != nil && !membership.StartDate.Before(*membership.EndDate) {
+		return errors.BadInput.New("startDate should be before endDate")
+	}
+	others, err := h.store.findTeamMemberships(
+		dal.Where("team_id = ? AND user_id = ? AND id != ?", membership.TeamId, membership.UserId, membership.Id),
+	)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if membership.Overlaps(other) {
+			return errors.BadInput.New(fmt.Sprintf("the membership overlaps membership %d of the user in the team", other.Id))
+		}
+	}
+	return nil
+}
+
+// parseMembershipDate accepts either a date or a time in RFC3339
+func parseMembershipDate(value string) (*time.Time, errors.Error) {
+	date, err := time.Parse(TimeFormat, value)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.BadInput.Wrap(err, fmt.Sprintf("invalid date %s, expecting %s or RFC3339", value, TimeFormat))
+		}
+	}
+	return &date, nil
+}
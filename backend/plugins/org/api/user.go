@@ -22,6 +22,7 @@ import (
 	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"net/http"
+	"time"
 
 	"github.com/gocarina/gocsv"
 )
@@ -100,5 +101,9 @@ func (h *Handlers) CreateUser(input *plugin.ApiResourceInput) (*plugin.ApiResour
 	if err != nil {
 		return nil, err
 	}
+	err = h.store.syncTeamMemberships(teamUsers, time.Now())
+	if err != nil {
+		return nil, err
+	}
 	return &plugin.ApiResourceOutput{Status: http.StatusOK}, nil
 }
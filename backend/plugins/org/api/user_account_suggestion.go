@@ -0,0 +1,156 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+type userAccountSuggestion struct {
+	AccountId       string  `json:"accountId"`
+	UserId          string  `json:"userId"`
+	Confidence      float64 `json:"confidence"`
+	Reasons         string  `json:"reasons"`
+	Status          string  `json:"status"`
+	AccountEmail    string  `json:"accountEmail"`
+	AccountFullName string  `json:"accountFullName"`
+	AccountUserName string  `json:"accountUserName"`
+	// UserEmail and UserName are empty when the user is to be created on approval
+	UserEmail string `json:"userEmail"`
+	UserName  string `json:"userName"`
+}
+
+type userAccountSuggestionsOutput struct {
+	Suggestions []userAccountSuggestion `json:"suggestions"`
+	Count       int64                   `json:"count"`
+}
+
+type reviewUserAccountSuggestionsInput struct {
+	AccountIds    []string `json:"accountIds"`
+	MinConfidence *float64 `json:"minConfidence"`
+}
+
+type reviewUserAccountSuggestionsOutput struct {
+	Count int `json:"count"`
+}
+
+// GetUserAccountSuggestions returns the user/account mappings suggested by identity resolution
+// @Summary      Get user account suggestions
+// @Description  get the user/account mappings suggested by the resolveIdentities subtask
+// @Tags 		 plugins/org
+// @Param        status query string false "PENDING (default), APPROVED or REJECTED"
+// @Param        minConfidence query number false "minimum confidence, between 0 and 1"
+// @Param        page query int false "page number, starting from 1"
+// @Param        pageSize query int false "page size, 50 by default"
+// @Produce      json
+// @Success      200  {object} userAccountSuggestionsOutput
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/user_account_suggestions [get]
+func (h *Handlers) GetUserAccountSuggestions(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	status := input.Query.Get("status")
+	if status == "" {
+		status = crossdomain.USER_ACCOUNT_SUGGESTION_PENDING
+	}
+	if status != crossdomain.USER_ACCOUNT_SUGGESTION_PENDING &&
+		status != crossdomain.USER_ACCOUNT_SUGGESTION_APPROVED &&
+		status != crossdomain.USER_ACCOUNT_SUGGESTION_REJECTED {
+		return nil, errors.BadInput.New("status should be PENDING, APPROVED or REJECTED")
+	}
+	var minConfidence float64
+	if value := input.Query.Get("minConfidence"); value != "" {
+		var err error
+		minConfidence, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.BadInput.Wrap(err, "minConfidence should be a number")
+		}
+	}
+	limit, offset := helper.GetLimitOffset(input.Query, "pageSize", "page")
+	suggestions, count, err := h.store.findUserAccountSuggestions(status, minConfidence, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{
+		Body:   userAccountSuggestionsOutput{Suggestions: suggestions, Count: count},
+		Status: http.StatusOK,
+	}, nil
+}
+
+// ApproveUserAccountSuggestions saves the pending suggestions into user_accounts
+// @Summary      Approve user account suggestions
+// @Description  approve the pending suggestions of the given accounts, or all the ones with at least minConfidence
+// @Tags 		 plugins/org
+// @Accept       json
+// @Param        body body reviewUserAccountSuggestionsInput true "accountIds or minConfidence"
+// @Produce      json
+// @Success      200  {object} reviewUserAccountSuggestionsOutput
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/user_account_suggestions/approve [post]
+func (h *Handlers) ApproveUserAccountSuggestions(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	var body reviewUserAccountSuggestionsInput
+	err := helper.Decode(input.Body, &body, nil)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid body")
+	}
+	if len(body.AccountIds) == 0 && body.MinConfidence == nil {
+		return nil, errors.BadInput.New("either accountIds or minConfidence is required")
+	}
+	var minConfidence float64
+	if body.MinConfidence != nil {
+		minConfidence = *body.MinConfidence
+	}
+	count, err := h.store.approveUserAccountSuggestions(body.AccountIds, minConfidence)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: reviewUserAccountSuggestionsOutput{Count: count}, Status: http.StatusOK}, nil
+}
+
+// RejectUserAccountSuggestions marks the pending suggestions as rejected so they are not suggested again
+// @Summary      Reject user account suggestions
+// @Description  reject the pending suggestions of the given accounts
+// @Tags 		 plugins/org
+// @Accept       json
+// @Param        body body reviewUserAccountSuggestionsInput true "accountIds"
+// @Produce      json
+// @Success      200  {object} reviewUserAccountSuggestionsOutput
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/user_account_suggestions/reject [post]
+func (h *Handlers) RejectUserAccountSuggestions(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	var body reviewUserAccountSuggestionsInput
+	err := helper.Decode(input.Body, &body, nil)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid body")
+	}
+	if len(body.AccountIds) == 0 {
+		return nil, errors.BadInput.New("accountIds is required")
+	}
+	count, err := h.store.rejectUserAccountSuggestions(body.AccountIds)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: reviewUserAccountSuggestionsOutput{Count: count}, Status: http.StatusOK}, nil
+}
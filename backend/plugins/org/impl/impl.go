@@ -60,6 +60,7 @@ func (p Org) Name() string {
 func (p Org) SubTaskMetas() []plugin.SubTaskMeta {
 	return []plugin.SubTaskMeta{
 		tasks.ConnectUserAccountsExactMeta,
+		tasks.ResolveIdentitiesMeta,
 		tasks.SetProjectMappingMeta,
 	}
 }
@@ -120,5 +121,27 @@ func (p Org) ApiResources() map[string]map[string]plugin.ApiResourceHandler {
 			"GET": p.handlers.GetProjectMapping,
 			"PUT": p.handlers.CreateProjectMapping,
 		},
+		"user_account_suggestions": {
+			"GET": p.handlers.GetUserAccountSuggestions,
+		},
+		"user_account_suggestions/approve": {
+			"POST": p.handlers.ApproveUserAccountSuggestions,
+		},
+		"user_account_suggestions/reject": {
+			"POST": p.handlers.RejectUserAccountSuggestions,
+		},
+		"teams/:teamId/members": {
+			"GET": p.handlers.GetTeamMembers,
+		},
+		"users/:userId/memberships": {
+			"GET": p.handlers.GetUserMemberships,
+		},
+		"team_memberships": {
+			"POST": p.handlers.CreateTeamMembership,
+		},
+		"team_memberships/:id": {
+			"PATCH":  p.handlers.UpdateTeamMembership,
+			"DELETE": p.handlers.DeleteTeamMembership,
+		},
 	}
 }
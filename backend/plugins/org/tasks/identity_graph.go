@@ -0,0 +1,381 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// confidence of each signal linking two identities
+const (
+	confidenceMapped  = 1.0
+	confidenceEmail   = 0.95
+	confidenceNoreply = 0.9
+	confidenceCommit  = 0.8
+	confidencePr      = 0.75
+	confidenceName    = 0.7
+	confidenceFuzzy   = 0.6
+)
+
+// reasons recorded on the suggestions
+const (
+	reasonMapped  = "mapped"
+	reasonEmail   = "email"
+	reasonNoreply = "noreply"
+	reasonCommit  = "commit"
+	reasonName    = "name"
+)
+
+// minNameSimilarity is the Jaro-Winkler similarity above which two full names are considered the same
+const minNameSimilarity = 0.92
+
+// ignoredEmails are shared by many people, e.g. the committer of the commits made on the GitHub web UI
+var ignoredEmails = map[string]bool{
+	"noreply@github.com": true,
+	"noreply@gitlab.com": true,
+}
+
+var githubNoreplyPattern = regexp.MustCompile(`^(?:\d+\+)?([^@+]+)@users\.noreply\.github\.com$`)
+var gitlabNoreplyPattern = regexp.MustCompile(`^(?:\d+-)?([^@]+)@users\.noreply\.gitlab\.com$`)
+
+// normalizeEmail lowercases the email and drops the +tag of the local part, as well as the dots of gmail
+// addresses. Noreply addresses are only lowercased since their local part carries the login.
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 || ignoredEmails[email] {
+		return ""
+	}
+	if _, login := parseNoreplyEmail(email); login != "" {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// parseNoreplyEmail returns the platform and the login of a GitHub or GitLab noreply address
+func parseNoreplyEmail(email string) (platform string, login string) {
+	email = strings.ToLower(email)
+	if m := githubNoreplyPattern.FindStringSubmatch(email); m != nil {
+		return "github", m[1]
+	}
+	if m := gitlabNoreplyPattern.FindStringSubmatch(email); m != nil {
+		return "gitlab", m[1]
+	}
+	return "", ""
+}
+
+// normalizeName lowercases the name, drops anything but letters and digits and sorts its words, so that
+// "Doe, John" and "john doe" are the same name
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of the two strings, between 0 and 1
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+	window := len(s1)
+	if len(s2) > window {
+		window = len(s2)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(s2) {
+			hi = len(s2)
+		}
+		for j := lo; j < hi; j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+	prefix := 0
+	for prefix < 4 && prefix < len(s1) && prefix < len(s2) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+type identityEdge struct {
+	from       string
+	to         string
+	confidence float64
+	reason     string
+}
+
+// identityGraph links accounts, users, emails and logins, all identified by a prefixed node id
+type identityGraph struct {
+	edges []identityEdge
+}
+
+func accountNode(accountId string) string { return "a:" + accountId }
+func userNode(userId string) string       { return "u:" + userId }
+func emailNode(email string) string       { return "e:" + email }
+func loginNode(platform, login string) string {
+	return "l:" + platform + ":" + strings.ToLower(login)
+}
+
+func (g *identityGraph) link(from, to string, confidence float64, reason string) {
+	if from == "" || to == "" || from == to {
+		return
+	}
+	g.edges = append(g.edges, identityEdge{from, to, confidence, reason})
+}
+
+// linkEmail links the node to the normalized email, and to the login of a noreply email
+func (g *identityGraph) linkEmail(node, email string) {
+	email = normalizeEmail(email)
+	if email == "" {
+		return
+	}
+	g.link(node, emailNode(email), confidenceEmail, reasonEmail)
+	if platform, login := parseNoreplyEmail(email); login != "" {
+		g.link(emailNode(email), loginNode(platform, login), confidenceNoreply, reasonNoreply)
+	}
+}
+
+type identityName struct {
+	node string
+	name string
+}
+
+// linkNames links the nodes with the same or similar full names. Single word names are too common to be
+// trusted. Names are only compared within the block of their first two letters to keep it tractable.
+func (g *identityGraph) linkNames(names []identityName) {
+	blocks := make(map[string][]identityName)
+	for _, n := range names {
+		name := normalizeName(n.name)
+		if !strings.Contains(name, " ") {
+			continue
+		}
+		key := string([]rune(name)[:2])
+		blocks[key] = append(blocks[key], identityName{n.node, name})
+	}
+	for _, block := range blocks {
+		for i := 0; i < len(block); i++ {
+			for j := i + 1; j < len(block); j++ {
+				if block[i].name == block[j].name {
+					g.link(block[i].node, block[j].node, confidenceName, reasonName)
+				} else if jaroWinkler(block[i].name, block[j].name) >= minNameSimilarity {
+					g.link(block[i].node, block[j].node, confidenceFuzzy, reasonName)
+				}
+			}
+		}
+	}
+}
+
+type identityCluster struct {
+	nodes []string
+	// tree holds the strongest links between the nodes of the cluster, a maximum spanning tree
+	tree map[string][]identityEdge
+}
+
+// identityComponent is what a group of linked nodes is made of, as far as merging it with another one is concerned
+type identityComponent struct {
+	users map[string]bool
+	// sources are the data sources of the accounts, e.g. github:GithubAccount:1 for the accounts of a connection
+	sources map[string]bool
+}
+
+func newIdentityComponent(node string) *identityComponent {
+	component := &identityComponent{users: map[string]bool{}, sources: map[string]bool{}}
+	switch {
+	case strings.HasPrefix(node, "u:"):
+		component.users[node] = true
+	case strings.HasPrefix(node, "a:"):
+		accountId := strings.TrimPrefix(node, "a:")
+		if i := strings.LastIndex(accountId, ":"); i > 0 {
+			accountId = accountId[:i]
+		}
+		component.sources[accountId] = true
+	}
+	return component
+}
+
+// acceptsName tells whether a link by name is enough to merge the two components: it is not if both have a user
+// already, or if both have an account of the same data source, which are more likely namesakes than the same person
+func (c *identityComponent) acceptsName(other *identityComponent) bool {
+	if len(c.users) > 0 && len(other.users) > 0 {
+		return false
+	}
+	for source := range c.sources {
+		if other.sources[source] {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *identityComponent) merge(other *identityComponent) {
+	for user := range other.users {
+		c.users[user] = true
+	}
+	for source := range other.sources {
+		c.sources[source] = true
+	}
+}
+
+// clusters groups the linked nodes, keeping the strongest links between them only. The links are followed from the
+// strongest down, so the links by name come last and only join the groups they could not mislead
+func (g *identityGraph) clusters() []*identityCluster {
+	edges := append([]identityEdge(nil), g.edges...)
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].confidence > edges[j].confidence })
+
+	parents := make(map[string]string)
+	components := make(map[string]*identityComponent)
+	var find func(string) string
+	find = func(node string) string {
+		parent, ok := parents[node]
+		if !ok {
+			parents[node] = node
+			components[node] = newIdentityComponent(node)
+			return node
+		}
+		if parent != node {
+			parents[node] = find(parent)
+		}
+		return parents[node]
+	}
+
+	tree := make(map[string][]identityEdge)
+	var order []string
+	for _, edge := range edges {
+		for _, node := range []string{edge.from, edge.to} {
+			if _, ok := tree[node]; !ok {
+				tree[node] = nil
+				order = append(order, node)
+			}
+		}
+		rootFrom, rootTo := find(edge.from), find(edge.to)
+		if rootFrom == rootTo {
+			continue
+		}
+		if edge.reason == reasonName && !components[rootFrom].acceptsName(components[rootTo]) {
+			continue
+		}
+		parents[rootFrom] = rootTo
+		components[rootTo].merge(components[rootFrom])
+		delete(components, rootFrom)
+		tree[edge.from] = append(tree[edge.from], edge)
+		tree[edge.to] = append(tree[edge.to], identityEdge{edge.to, edge.from, edge.confidence, edge.reason})
+	}
+
+	byRoot := make(map[string]*identityCluster)
+	var clusters []*identityCluster
+	for _, node := range order {
+		root := find(node)
+		cluster, ok := byRoot[root]
+		if !ok {
+			cluster = &identityCluster{tree: make(map[string][]identityEdge)}
+			byRoot[root] = cluster
+			clusters = append(clusters, cluster)
+		}
+		cluster.nodes = append(cluster.nodes, node)
+		cluster.tree[node] = tree[node]
+	}
+	return clusters
+}
+
+type identityLink struct {
+	confidence float64
+	reasons    map[string]bool
+}
+
+// linksFrom returns, for every other node of the cluster, the confidence of its weakest link on the way to the
+// anchor node and the reasons of all of those links
+func (c *identityCluster) linksFrom(anchor string) map[string]*identityLink {
+	links := map[string]*identityLink{anchor: {confidence: 1, reasons: map[string]bool{}}}
+	queue := []string{anchor}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range c.tree[node] {
+			if _, visited := links[edge.to]; visited {
+				continue
+			}
+			link := &identityLink{confidence: links[node].confidence, reasons: map[string]bool{edge.reason: true}}
+			if edge.confidence < link.confidence {
+				link.confidence = edge.confidence
+			}
+			for reason := range links[node].reasons {
+				link.reasons[reason] = true
+			}
+			links[edge.to] = link
+			queue = append(queue, edge.to)
+		}
+	}
+	delete(links, anchor)
+	return links
+}
+
+func (l *identityLink) reasonList() string {
+	reasons := make([]string, 0, len(l.reasons))
+	for reason := range l.reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, ",")
+}
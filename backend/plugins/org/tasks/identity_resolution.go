@@ -0,0 +1,236 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+var ResolveIdentitiesMeta = plugin.SubTaskMeta{
+	Name:             "resolveIdentities",
+	EntryPoint:       ResolveIdentities,
+	EnabledByDefault: false,
+	Description:      "cluster accounts across data sources and suggest user_accounts to be reviewed",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+	DependencyTables: []string{
+		crossdomain.Account{}.TableName(),
+		crossdomain.User{}.TableName(),
+		crossdomain.UserAccount{}.TableName(),
+		code.Commit{}.TableName(),
+		code.PullRequest{}.TableName(),
+		code.PullRequestCommit{}.TableName(),
+	},
+	ProductTables: []string{crossdomain.UserAccountSuggestion{}.TableName()},
+}
+
+type commitIdentity struct {
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+}
+
+type prCommitIdentity struct {
+	AuthorId          string
+	CommitAuthorEmail string
+}
+
+// minPrCoOccurrences is the number of pull requests an account must have authored with commits of the same
+// email for them to be linked
+const minPrCoOccurrences = 2
+
+// ResolveIdentities links accounts, users and the emails found in commits by normalized email, noreply address,
+// commit author/committer and pull request author co-occurrence, and similar full names. Each cluster of linked
+// accounts is suggested to be mapped to its only user, or to a new user when it has none. Clusters linked to
+// several users are left alone, as well as suggestions rejected before.
+func ResolveIdentities(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	graph := &identityGraph{}
+	var names []identityName
+
+	var accounts []crossdomain.Account
+	err := db.All(&accounts, dal.Select("id, email, full_name, user_name"))
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading accounts")
+	}
+	for _, account := range accounts {
+		node := accountNode(account.Id)
+		graph.linkEmail(node, account.Email)
+		if account.UserName != "" {
+			// the domain id of an account starts with the plugin name, e.g. github:GithubAccount:1:123
+			graph.link(node, loginNode(account.Id[:strings.Index(account.Id+":", ":")], account.UserName), confidenceNoreply, reasonNoreply)
+		}
+		names = append(names, identityName{node, account.FullName})
+	}
+
+	var users []crossdomain.User
+	err = db.All(&users)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading users")
+	}
+	for _, user := range users {
+		graph.linkEmail(userNode(user.Id), user.Email)
+		names = append(names, identityName{userNode(user.Id), user.Name})
+	}
+	graph.linkNames(names)
+
+	var userAccounts []crossdomain.UserAccount
+	err = db.All(&userAccounts)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading user_accounts")
+	}
+	mapped := make(map[string]bool, len(userAccounts))
+	for _, userAccount := range userAccounts {
+		mapped[userAccount.AccountId] = true
+		graph.link(accountNode(userAccount.AccountId), userNode(userAccount.UserId), confidenceMapped, reasonMapped)
+	}
+
+	var commitIdentities []commitIdentity
+	err = db.All(
+		&commitIdentities,
+		dal.Select("DISTINCT author_name, author_email, committer_name, committer_email"),
+		dal.From(&code.Commit{}),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading commit identities")
+	}
+	for _, identity := range commitIdentities {
+		authorEmail, committerEmail := normalizeEmail(identity.AuthorEmail), normalizeEmail(identity.CommitterEmail)
+		for _, email := range []string{authorEmail, committerEmail} {
+			if email != "" {
+				graph.linkEmail(emailNode(email), email)
+			}
+		}
+		if authorEmail != "" && committerEmail != "" && identity.AuthorName != "" &&
+			normalizeName(identity.AuthorName) == normalizeName(identity.CommitterName) {
+			graph.link(emailNode(authorEmail), emailNode(committerEmail), confidenceCommit, reasonCommit)
+		}
+	}
+
+	var prCommitIdentities []prCommitIdentity
+	err = db.All(
+		&prCommitIdentities,
+		dal.Select("pr.author_id, prc.commit_author_email"),
+		dal.From("pull_requests pr"),
+		dal.Join("JOIN pull_request_commits prc ON prc.pull_request_id = pr.id"),
+		dal.Where("pr.author_id != '' AND prc.commit_author_email != ''"),
+		dal.Groupby("pr.author_id, prc.commit_author_email"),
+		dal.Having("COUNT(DISTINCT pr.id) >= ?", minPrCoOccurrences),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading pull request commit identities")
+	}
+	for _, identity := range prCommitIdentities {
+		if email := normalizeEmail(identity.CommitAuthorEmail); email != "" {
+			graph.link(accountNode(identity.AuthorId), emailNode(email), confidencePr, reasonCommit)
+		}
+	}
+
+	var rejected []crossdomain.UserAccountSuggestion
+	err = db.All(&rejected, dal.Where("status = ?", crossdomain.USER_ACCOUNT_SUGGESTION_REJECTED))
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading rejected user_account_suggestions")
+	}
+	rejectedPairs := make(map[[2]string]bool, len(rejected))
+	for _, suggestion := range rejected {
+		rejectedPairs[[2]string{suggestion.AccountId, suggestion.UserId}] = true
+	}
+
+	err = db.Delete(
+		&crossdomain.UserAccountSuggestion{},
+		dal.Where("status = ?", crossdomain.USER_ACCOUNT_SUGGESTION_PENDING),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting pending user_account_suggestions")
+	}
+	batch, err := api.NewBatchSave(taskCtx, reflect.TypeOf(&crossdomain.UserAccountSuggestion{}), 500)
+	if err != nil {
+		return err
+	}
+	for _, suggestion := range suggestUserAccounts(graph.clusters(), mapped) {
+		if rejectedPairs[[2]string{suggestion.AccountId, suggestion.UserId}] {
+			continue
+		}
+		err = batch.Add(suggestion)
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Close()
+}
+
+// suggestUserAccounts maps the accounts of each cluster to the only user of the cluster, or to a new user
+// identified by the first account of the cluster when it has none
+func suggestUserAccounts(clusters []*identityCluster, mapped map[string]bool) []*crossdomain.UserAccountSuggestion {
+	var suggestions []*crossdomain.UserAccountSuggestion
+	for _, cluster := range clusters {
+		var userNodes, accountNodes []string
+		for _, node := range cluster.nodes {
+			switch {
+			case strings.HasPrefix(node, "u:"):
+				userNodes = append(userNodes, node)
+			case strings.HasPrefix(node, "a:"):
+				accountNodes = append(accountNodes, node)
+			}
+		}
+		if len(userNodes) > 1 || len(accountNodes) == 0 || (len(userNodes) == 0 && len(accountNodes) < 2) {
+			continue
+		}
+		sort.Strings(accountNodes)
+		anchor := accountNodes[0]
+		if len(userNodes) == 1 {
+			anchor = userNodes[0]
+		}
+		userId := strings.TrimPrefix(strings.TrimPrefix(anchor, "u:"), "a:")
+
+		links := cluster.linksFrom(anchor)
+		if len(userNodes) == 0 {
+			// the anchor account is as close to the new user as its strongest link to the other accounts
+			strongest := cluster.tree[anchor][0]
+			for _, edge := range cluster.tree[anchor] {
+				if edge.confidence > strongest.confidence {
+					strongest = edge
+				}
+			}
+			links[anchor] = &identityLink{confidence: strongest.confidence, reasons: map[string]bool{strongest.reason: true}}
+		}
+		for _, node := range accountNodes {
+			accountId := strings.TrimPrefix(node, "a:")
+			if mapped[accountId] {
+				continue
+			}
+			suggestions = append(suggestions, &crossdomain.UserAccountSuggestion{
+				AccountId:  accountId,
+				UserId:     userId,
+				Confidence: links[node].confidence,
+				Reasons:    links[node].reasonList(),
+				Status:     crossdomain.USER_ACCOUNT_SUGGESTION_PENDING,
+			})
+		}
+	}
+	return suggestions
+}
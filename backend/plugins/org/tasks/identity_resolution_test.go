@@ -0,0 +1,127 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "john@example.com", normalizeEmail(" John+devlake@Example.com "))
+	assert.Equal(t, "johndoe@gmail.com", normalizeEmail("John.Doe+ci@googlemail.com"))
+	assert.Equal(t, "123+jdoe@users.noreply.github.com", normalizeEmail("123+JDoe@users.noreply.github.com"))
+	assert.Equal(t, "", normalizeEmail("noreply@github.com"))
+	assert.Equal(t, "", normalizeEmail("john"))
+
+	platform, login := parseNoreplyEmail("123+JDoe@users.noreply.github.com")
+	assert.Equal(t, "github", platform)
+	assert.Equal(t, "jdoe", login)
+	platform, login = parseNoreplyEmail("jdoe@users.noreply.github.com")
+	assert.Equal(t, "github", platform)
+	assert.Equal(t, "jdoe", login)
+	platform, login = parseNoreplyEmail("42-jane.roe@users.noreply.gitlab.com")
+	assert.Equal(t, "gitlab", platform)
+	assert.Equal(t, "jane.roe", login)
+	_, login = parseNoreplyEmail("jdoe@example.com")
+	assert.Equal(t, "", login)
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, "doe john", normalizeName("Doe, John"))
+	assert.Equal(t, normalizeName("john doe"), normalizeName("Doe, John"))
+	assert.InDelta(t, 0.961, jaroWinkler("martha", "marhta"), 0.001)
+	assert.Equal(t, 1.0, jaroWinkler("john doe", "john doe"))
+	assert.Equal(t, 0.0, jaroWinkler("", "john"))
+	assert.GreaterOrEqual(t, jaroWinkler("doe jonathan", "doe jonathon"), minNameSimilarity)
+	assert.Less(t, jaroWinkler("doe john", "doe jane"), minNameSimilarity)
+}
+
+func TestSuggestUserAccounts(t *testing.T) {
+	g := &identityGraph{}
+	// john is a known user, mapped to his jira account
+	g.linkEmail(userNode("u1"), "john@example.com")
+	g.link(accountNode("jira:1"), userNode("u1"), confidenceMapped, reasonMapped)
+	// his github account has no public email but commits with a noreply address
+	g.link(accountNode("github:1"), loginNode("github", "JDoe"), confidenceNoreply, reasonNoreply)
+	g.linkEmail(emailNode("123+jdoe@users.noreply.github.com"), "123+jdoe@users.noreply.github.com")
+	g.link(emailNode("123+jdoe@users.noreply.github.com"), emailNode("john@example.com"), confidenceCommit, reasonCommit)
+	// his gitlab account uses a tagged address
+	g.linkEmail(accountNode("gitlab:1"), "John+gitlab@example.com")
+	// jane is unknown, her accounts share a similar name only
+	names := []identityName{
+		{accountNode("github:2"), "Jane Roe"},
+		{accountNode("jira:2"), "Roe, Jane"},
+		{accountNode("jira:3"), "Jane"},
+	}
+	g.linkNames(names)
+	// bob's accounts are linked to two different users
+	g.linkEmail(userNode("u2"), "bob@example.com")
+	g.linkEmail(userNode("u3"), "bob@example.com")
+	g.linkEmail(accountNode("github:3"), "bob@example.com")
+
+	suggestions := make(map[string]*crossdomain.UserAccountSuggestion)
+	for _, suggestion := range suggestUserAccounts(g.clusters(), map[string]bool{"jira:1": true}) {
+		suggestions[suggestion.AccountId] = suggestion
+	}
+	assert.Len(t, suggestions, 4)
+
+	assert.Equal(t, "u1", suggestions["gitlab:1"].UserId)
+	assert.Equal(t, confidenceEmail, suggestions["gitlab:1"].Confidence)
+	assert.Equal(t, reasonEmail, suggestions["gitlab:1"].Reasons)
+	assert.Equal(t, "u1", suggestions["github:1"].UserId)
+	assert.Equal(t, confidenceCommit, suggestions["github:1"].Confidence)
+	assert.Equal(t, "commit,email,noreply", suggestions["github:1"].Reasons)
+	assert.Equal(t, crossdomain.USER_ACCOUNT_SUGGESTION_PENDING, suggestions["github:1"].Status)
+
+	assert.Equal(t, "github:2", suggestions["github:2"].UserId)
+	assert.Equal(t, "github:2", suggestions["jira:2"].UserId)
+	assert.Equal(t, confidenceName, suggestions["jira:2"].Confidence)
+	assert.Equal(t, confidenceName, suggestions["github:2"].Confidence)
+}
+
+func TestSuggestUserAccountsWithNamesakes(t *testing.T) {
+	g := &identityGraph{}
+	// two known users with similar names, each with an account of the same email
+	g.linkEmail(userNode("u1"), "jonathan@example.com")
+	g.linkEmail(accountNode("github:1"), "jonathan@example.com")
+	g.linkEmail(userNode("u2"), "jonathon@example.com")
+	g.linkEmail(accountNode("github:2"), "jonathon@example.com")
+	// three unknown accounts sharing a common name, two of them from the same data source
+	g.linkNames([]identityName{
+		{userNode("u1"), "Jonathan Doe"},
+		{userNode("u2"), "Jonathon Doe"},
+		{accountNode("jira:1"), "John Smith"},
+		{accountNode("jira:2"), "John Smith"},
+		{accountNode("gitlab:1"), "John Smith"},
+	})
+
+	suggestions := make(map[string]*crossdomain.UserAccountSuggestion)
+	for _, suggestion := range suggestUserAccounts(g.clusters(), map[string]bool{}) {
+		suggestions[suggestion.AccountId] = suggestion
+	}
+	assert.Len(t, suggestions, 4)
+	assert.Equal(t, "u1", suggestions["github:1"].UserId)
+	assert.Equal(t, confidenceEmail, suggestions["github:1"].Confidence)
+	assert.Equal(t, "u2", suggestions["github:2"].UserId)
+	// the gitlab account joins one of the jira accounts only
+	assert.Equal(t, suggestions["gitlab:1"].UserId, suggestions["jira:1"].UserId)
+	assert.Nil(t, suggestions["jira:2"])
+}
@@ -73,6 +73,8 @@ func (p RefDiff) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.CalculateIssuesDiffMeta,
 		tasks.CalculatePrCherryPickMeta,
 		tasks.CalculateDeploymentCommitsDiffMeta,
+		tasks.CalculateCodeOwnershipMeta,
+		tasks.CalculatePrCodeOwnersMeta,
 	}
 }
 
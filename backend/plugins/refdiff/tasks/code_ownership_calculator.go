@@ -0,0 +1,415 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"path"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+const codeOwnershipBatchSize = 500
+
+var CalculateCodeOwnershipMeta = plugin.SubTaskMeta{
+	Name:             "calculateCodeOwnership",
+	EntryPoint:       CalculateCodeOwnership,
+	EnabledByDefault: true,
+	Description:      "Calculate code ownership, bus factor of components and churn of files from the blame snapshot and line changes collected by gitextractor",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
+}
+
+// blameLines is the number of lines of a file in the snapshot last changed by an author
+type blameLines struct {
+	FilePath   string
+	AuthorId   string
+	AuthorName string
+	LineCount  int
+}
+
+// fileComponent is the component a file belongs to
+type fileComponent struct {
+	FilePath      string
+	ComponentName string
+}
+
+// fileChange is the lines of a file changed by a commit
+type fileChange struct {
+	CommitSha    string
+	AuthorId     string
+	AuthoredDate time.Time
+	FilePath     string
+	Additions    int
+	Deletions    int
+}
+
+type authorLines struct {
+	AuthorId   string
+	AuthorName string
+	LineCount  int
+}
+
+// CalculateCodeOwnership aggregates the repo_snapshot and commit_line_change of the repo, both are only collected
+// by gitextractor when the "Collect DiffLine" subtask is enabled, so it does nothing otherwise.
+func CalculateCodeOwnership(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RefdiffTaskData)
+	repoId := data.Options.RepoId
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+
+	if data.Options.ProjectName != "" || repoId == "" {
+		return nil
+	}
+
+	// repo_snapshot is the blame of the HEAD of the repo when it was cloned, which is the default branch
+	defaultRef := &code.Ref{}
+	err := db.First(defaultRef, dal.Where("repo_id = ? AND is_default = ?", repoId, true))
+	if db.IsErrorNotFound(err) {
+		logger.Info("repo %s has no default branch, skip code ownership", repoId)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	snapshotCommitSha := defaultRef.CommitSha
+
+	var lines []blameLines
+	err = db.All(
+		&lines,
+		dal.Select("rs.file_path, c.author_id, MAX(c.author_name) AS author_name, COUNT(*) AS line_count"),
+		dal.From("repo_snapshot rs"),
+		dal.Join("JOIN commits c ON c.sha = rs.commit_sha"),
+		dal.Where("rs.repo_id = ?", repoId),
+		dal.Groupby("rs.file_path, c.author_id"),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load repo snapshot")
+	}
+	if len(lines) == 0 {
+		logger.Info("repo %s has no snapshot, skip code ownership", repoId)
+		return nil
+	}
+
+	var fileComponents []fileComponent
+	err = db.All(
+		&fileComponents,
+		dal.Select("DISTINCT cf.file_path, cfc.component_name"),
+		dal.From("commit_files cf"),
+		dal.Join("JOIN commit_file_components cfc ON cfc.commit_file_id = cf.id"),
+		dal.Join("JOIN repo_commits rc ON rc.commit_sha = cf.commit_sha"),
+		dal.Where("rc.repo_id = ? AND cfc.component_name != ''", repoId),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load components of files")
+	}
+	components := make(map[string]string, len(fileComponents))
+	for _, fc := range fileComponents {
+		components[fc.FilePath] = fc.ComponentName
+	}
+
+	churns, err := aggregateFileChurns(db, repoId, snapshotCommitSha)
+	if err != nil {
+		return err
+	}
+
+	ownerships := computeCodeOwnerships(repoId, snapshotCommitSha, lines)
+	busFactors := computeComponentBusFactors(repoId, snapshotCommitSha, lines, components)
+	logger.Info(
+		"snapshot %s of repo %s: %d ownerships, %d components, %d file churns",
+		snapshotCommitSha, repoId, len(ownerships), len(busFactors), len(churns),
+	)
+
+	snapshotClause := dal.Where("repo_id = ? AND snapshot_commit_sha = ?", repoId, snapshotCommitSha)
+	for _, table := range []dal.Tabler{&code.CodeOwnership{}, &code.ComponentBusFactor{}, &code.FileChurn{}} {
+		err = db.Delete(table, snapshotClause)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to delete previous "+table.TableName())
+		}
+	}
+	if err = createInBatches(db, ownerships); err != nil {
+		return err
+	}
+	if err = createInBatches(db, busFactors); err != nil {
+		return err
+	}
+	return createInBatches(db, churns)
+}
+
+// aggregateFileChurns sums up the line changes by file and month. commit_line_change is only written by the libgit2
+// implementation of gitextractor, commit_files are used instead when it is empty.
+func aggregateFileChurns(db dal.Dal, repoId, snapshotCommitSha string) ([]*code.FileChurn, errors.Error) {
+	count, err := db.Count(
+		dal.From("commit_line_change clc"),
+		dal.Join("JOIN repo_commits rc ON rc.commit_sha = clc.commit_sha"),
+		dal.Where("rc.repo_id = ?", repoId),
+	)
+	if err != nil {
+		return nil, err
+	}
+	clauses := []dal.Clause{
+		dal.Select(`c.sha AS commit_sha, c.author_id, c.authored_date, cf.file_path, cf.additions, cf.deletions`),
+		dal.From("commit_files cf"),
+		dal.Join("JOIN commits c ON c.sha = cf.commit_sha"),
+		dal.Join("JOIN repo_commits rc ON rc.commit_sha = c.sha"),
+		dal.Where("rc.repo_id = ?", repoId),
+	}
+	if count > 0 {
+		clauses = []dal.Clause{
+			dal.Select(`c.sha AS commit_sha, c.author_id, c.authored_date, clc.new_file_path AS file_path,
+				SUM(CASE WHEN clc.changed_type = 'Addition' THEN 1 ELSE 0 END) AS additions,
+				SUM(CASE WHEN clc.changed_type = 'Deletion' THEN 1 ELSE 0 END) AS deletions`),
+			dal.From("commit_line_change clc"),
+			dal.Join("JOIN commits c ON c.sha = clc.commit_sha"),
+			dal.Join("JOIN repo_commits rc ON rc.commit_sha = c.sha"),
+			dal.Where("rc.repo_id = ?", repoId),
+			dal.Groupby("c.sha, c.author_id, c.authored_date, clc.new_file_path"),
+		}
+	}
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to load file changes")
+	}
+	defer cursor.Close()
+
+	aggregator := newFileChurnAggregator()
+	for cursor.Next() {
+		change := &fileChange{}
+		err = db.Fetch(cursor, change)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "failed to read file change")
+		}
+		aggregator.add(change)
+	}
+	return aggregator.result(repoId, snapshotCommitSha), nil
+}
+
+// computeCodeOwnerships computes the share of each author in every file of the snapshot and in the directories above
+func computeCodeOwnerships(repoId, snapshotCommitSha string, lines []blameLines) []*code.CodeOwnership {
+	type pathKey struct {
+		path     string
+		pathType string
+	}
+	authorsByPath := make(map[pathKey]map[string]*authorLines)
+	addLines := func(key pathKey, line blameLines) {
+		authors, ok := authorsByPath[key]
+		if !ok {
+			authors = make(map[string]*authorLines)
+			authorsByPath[key] = authors
+		}
+		addAuthorLines(authors, line)
+	}
+	for _, line := range lines {
+		addLines(pathKey{line.FilePath, code.CODE_OWNERSHIP_FILE}, line)
+		for dir := path.Dir(line.FilePath); ; dir = path.Dir(dir) {
+			addLines(pathKey{dir, code.CODE_OWNERSHIP_DIRECTORY}, line)
+			if dir == "." || dir == "/" {
+				break
+			}
+		}
+	}
+
+	keys := make([]pathKey, 0, len(authorsByPath))
+	for key := range authorsByPath {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pathType != keys[j].pathType {
+			return keys[i].pathType < keys[j].pathType
+		}
+		return keys[i].path < keys[j].path
+	})
+	ownerships := make([]*code.CodeOwnership, 0, len(lines))
+	for _, key := range keys {
+		authors, total := rankAuthors(authorsByPath[key])
+		for i, author := range authors {
+			ownerships = append(ownerships, &code.CodeOwnership{
+				RepoId:            repoId,
+				SnapshotCommitSha: snapshotCommitSha,
+				Path:              key.path,
+				PathType:          key.pathType,
+				AuthorId:          author.AuthorId,
+				AuthorName:        author.AuthorName,
+				LineCount:         author.LineCount,
+				TotalLineCount:    total,
+				Share:             float64(author.LineCount) / float64(total),
+				Rank:              i + 1,
+			})
+		}
+	}
+	return ownerships
+}
+
+// computeComponentBusFactors computes the knowledge concentration of every component, files without component are
+// left out
+func computeComponentBusFactors(repoId, snapshotCommitSha string, lines []blameLines, components map[string]string) []*code.ComponentBusFactor {
+	authorsByComponent := make(map[string]map[string]*authorLines)
+	filesByComponent := make(map[string]map[string]bool)
+	for _, line := range lines {
+		component, ok := components[line.FilePath]
+		if !ok {
+			continue
+		}
+		if _, ok := authorsByComponent[component]; !ok {
+			authorsByComponent[component] = make(map[string]*authorLines)
+			filesByComponent[component] = make(map[string]bool)
+		}
+		addAuthorLines(authorsByComponent[component], line)
+		filesByComponent[component][line.FilePath] = true
+	}
+
+	names := make([]string, 0, len(authorsByComponent))
+	for name := range authorsByComponent {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	busFactors := make([]*code.ComponentBusFactor, 0, len(names))
+	for _, name := range names {
+		authors, total := rankAuthors(authorsByComponent[name])
+		busFactor, concentration := knowledgeDistribution(authors, total)
+		busFactors = append(busFactors, &code.ComponentBusFactor{
+			RepoId:                 repoId,
+			SnapshotCommitSha:      snapshotCommitSha,
+			ComponentName:          name,
+			FileCount:              len(filesByComponent[name]),
+			LineCount:              total,
+			AuthorCount:            len(authors),
+			BusFactor:              busFactor,
+			KnowledgeConcentration: concentration,
+			TopAuthorId:            authors[0].AuthorId,
+			TopAuthorName:          authors[0].AuthorName,
+			TopAuthorShare:         float64(authors[0].LineCount) / float64(total),
+		})
+	}
+	return busFactors
+}
+
+func addAuthorLines(authors map[string]*authorLines, line blameLines) {
+	author, ok := authors[line.AuthorId]
+	if !ok {
+		author = &authorLines{AuthorId: line.AuthorId, AuthorName: line.AuthorName}
+		authors[line.AuthorId] = author
+	}
+	author.LineCount += line.LineCount
+}
+
+// rankAuthors sorts the authors by the number of lines they own, the most first, and returns the total of lines
+func rankAuthors(authors map[string]*authorLines) ([]*authorLines, int) {
+	ranked := make([]*authorLines, 0, len(authors))
+	total := 0
+	for _, author := range authors {
+		ranked = append(ranked, author)
+		total += author.LineCount
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].LineCount != ranked[j].LineCount {
+			return ranked[i].LineCount > ranked[j].LineCount
+		}
+		return ranked[i].AuthorId < ranked[j].AuthorId
+	})
+	return ranked, total
+}
+
+// knowledgeDistribution returns the smallest number of authors owning together more than half of the lines and the
+// sum of the squared shares of the authors, the authors must be ranked
+func knowledgeDistribution(authors []*authorLines, total int) (int, float64) {
+	busFactor := 0
+	owned := 0
+	concentration := 0.0
+	for _, author := range authors {
+		if owned*2 <= total {
+			owned += author.LineCount
+			busFactor++
+		}
+		share := float64(author.LineCount) / float64(total)
+		concentration += share * share
+	}
+	return busFactor, concentration
+}
+
+type fileChurnKey struct {
+	filePath    string
+	periodStart time.Time
+}
+
+type fileChurnAggregator struct {
+	churns  map[fileChurnKey]*code.FileChurn
+	commits map[fileChurnKey]map[string]bool
+	authors map[fileChurnKey]map[string]bool
+}
+
+func newFileChurnAggregator() *fileChurnAggregator {
+	return &fileChurnAggregator{
+		churns:  make(map[fileChurnKey]*code.FileChurn),
+		commits: make(map[fileChurnKey]map[string]bool),
+		authors: make(map[fileChurnKey]map[string]bool),
+	}
+}
+
+// add counts the change in the month it was authored
+func (a *fileChurnAggregator) add(change *fileChange) {
+	date := change.AuthoredDate.UTC()
+	key := fileChurnKey{change.FilePath, time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)}
+	churn, ok := a.churns[key]
+	if !ok {
+		churn = &code.FileChurn{FilePath: key.filePath, PeriodStart: key.periodStart}
+		a.churns[key] = churn
+		a.commits[key] = make(map[string]bool)
+		a.authors[key] = make(map[string]bool)
+	}
+	churn.Additions += change.Additions
+	churn.Deletions += change.Deletions
+	churn.Churn += change.Additions + change.Deletions
+	a.commits[key][change.CommitSha] = true
+	a.authors[key][change.AuthorId] = true
+}
+
+func (a *fileChurnAggregator) result(repoId, snapshotCommitSha string) []*code.FileChurn {
+	churns := make([]*code.FileChurn, 0, len(a.churns))
+	for key, churn := range a.churns {
+		churn.RepoId = repoId
+		churn.SnapshotCommitSha = snapshotCommitSha
+		churn.CommitCount = len(a.commits[key])
+		churn.AuthorCount = len(a.authors[key])
+		churns = append(churns, churn)
+	}
+	sort.Slice(churns, func(i, j int) bool {
+		if churns[i].FilePath != churns[j].FilePath {
+			return churns[i].FilePath < churns[j].FilePath
+		}
+		return churns[i].PeriodStart.Before(churns[j].PeriodStart)
+	})
+	return churns
+}
+
+func createInBatches[T any](db dal.Dal, rows []*T) errors.Error {
+	for start := 0; start < len(rows); start += codeOwnershipBatchSize {
+		end := start + codeOwnershipBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		err := db.Create(rows[start:end])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBlameLines = []blameLines{
+	{FilePath: "api/user.go", AuthorId: "alice", AuthorName: "Alice", LineCount: 60},
+	{FilePath: "api/user.go", AuthorId: "bob", AuthorName: "Bob", LineCount: 40},
+	{FilePath: "api/team.go", AuthorId: "bob", AuthorName: "Bob", LineCount: 50},
+	{FilePath: "README.md", AuthorId: "carol", AuthorName: "Carol", LineCount: 10},
+}
+
+func TestComputeCodeOwnerships(t *testing.T) {
+	ownerships := computeCodeOwnerships("repo1", "sha1", testBlameLines)
+
+	byPath := make(map[string][]*code.CodeOwnership)
+	for _, o := range ownerships {
+		assert.Equal(t, "repo1", o.RepoId)
+		assert.Equal(t, "sha1", o.SnapshotCommitSha)
+		byPath[o.PathType+":"+o.Path] = append(byPath[o.PathType+":"+o.Path], o)
+	}
+	assert.Len(t, byPath, 5)
+
+	user := byPath["FILE:api/user.go"]
+	assert.Len(t, user, 2)
+	assert.Equal(t, "alice", user[0].AuthorId)
+	assert.Equal(t, 1, user[0].Rank)
+	assert.Equal(t, 100, user[0].TotalLineCount)
+	assert.InDelta(t, 0.6, user[0].Share, 1e-9)
+	assert.Equal(t, "bob", user[1].AuthorId)
+	assert.Equal(t, 2, user[1].Rank)
+
+	api := byPath["DIRECTORY:api"]
+	assert.Len(t, api, 2)
+	assert.Equal(t, "bob", api[0].AuthorId)
+	assert.Equal(t, 90, api[0].LineCount)
+	assert.Equal(t, 150, api[0].TotalLineCount)
+
+	root := byPath["DIRECTORY:."]
+	assert.Len(t, root, 3)
+	assert.Equal(t, 160, root[0].TotalLineCount)
+	assert.Equal(t, "carol", root[2].AuthorId)
+}
+
+func TestComputeComponentBusFactors(t *testing.T) {
+	lines := append(testBlameLines, blameLines{FilePath: "api/team.go", AuthorId: "carol", AuthorName: "Carol", LineCount: 50})
+	components := map[string]string{
+		"api/user.go": "backend",
+		"api/team.go": "backend",
+	}
+	busFactors := computeComponentBusFactors("repo1", "sha1", lines, components)
+
+	assert.Len(t, busFactors, 1)
+	backend := busFactors[0]
+	assert.Equal(t, "backend", backend.ComponentName)
+	assert.Equal(t, 2, backend.FileCount)
+	assert.Equal(t, 200, backend.LineCount)
+	assert.Equal(t, 3, backend.AuthorCount)
+	// bob owns 90 lines, it takes alice's 60 more to own more than half
+	assert.Equal(t, 2, backend.BusFactor)
+	assert.Equal(t, "bob", backend.TopAuthorId)
+	assert.InDelta(t, 0.45, backend.TopAuthorShare, 1e-9)
+	assert.InDelta(t, 0.45*0.45+0.3*0.3+0.25*0.25, backend.KnowledgeConcentration, 1e-9)
+}
+
+func TestKnowledgeDistribution(t *testing.T) {
+	busFactor, concentration := knowledgeDistribution([]*authorLines{{AuthorId: "alice", LineCount: 10}}, 10)
+	assert.Equal(t, 1, busFactor)
+	assert.InDelta(t, 1, concentration, 1e-9)
+
+	// exactly half is not enough
+	busFactor, concentration = knowledgeDistribution([]*authorLines{{LineCount: 5}, {LineCount: 5}}, 10)
+	assert.Equal(t, 2, busFactor)
+	assert.InDelta(t, 0.5, concentration, 1e-9)
+}
+
+func TestFileChurnAggregator(t *testing.T) {
+	jan := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600))
+	aggregator := newFileChurnAggregator()
+	aggregator.add(&fileChange{CommitSha: "c1", AuthorId: "alice", AuthoredDate: jan, FilePath: "a.go", Additions: 10, Deletions: 2})
+	aggregator.add(&fileChange{CommitSha: "c2", AuthorId: "bob", AuthoredDate: jan.Add(24 * time.Hour), FilePath: "a.go", Additions: 1, Deletions: 1})
+	aggregator.add(&fileChange{CommitSha: "c2", AuthorId: "bob", AuthoredDate: jan.Add(24 * time.Hour), FilePath: "b.go", Additions: 3})
+	// authored on the 31st of January in UTC
+	aggregator.add(&fileChange{CommitSha: "c3", AuthorId: "alice", AuthoredDate: feb, FilePath: "a.go", Deletions: 4})
+
+	churns := aggregator.result("repo1", "sha1")
+	assert.Len(t, churns, 2)
+	assert.Equal(t, "a.go", churns[0].FilePath)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), churns[0].PeriodStart)
+	assert.Equal(t, 3, churns[0].CommitCount)
+	assert.Equal(t, 2, churns[0].AuthorCount)
+	assert.Equal(t, 11, churns[0].Additions)
+	assert.Equal(t, 7, churns[0].Deletions)
+	assert.Equal(t, 18, churns[0].Churn)
+	assert.Equal(t, "b.go", churns[1].FilePath)
+	assert.Equal(t, "repo1", churns[1].RepoId)
+	assert.Equal(t, "sha1", churns[1].SnapshotCommitSha)
+}
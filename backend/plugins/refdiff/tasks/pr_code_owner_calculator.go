@@ -0,0 +1,256 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+var CalculatePrCodeOwnersMeta = plugin.SubTaskMeta{
+	Name:             "calculatePrCodeOwners",
+	EntryPoint:       CalculatePrCodeOwners,
+	EnabledByDefault: true,
+	Description:      "Match the files changed by pull requests with the CODEOWNERS rules to find the owners required to review them",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
+}
+
+type prFile struct {
+	PullRequestId string
+	FilePath      string
+}
+
+// prParticipant is a reviewer or an approver of a pull request
+type prParticipant struct {
+	PullRequestId string
+	UserName      string
+	Email         string
+}
+
+type prMerge struct {
+	Id         string
+	MergedDate *time.Time
+}
+
+// CalculatePrCodeOwners finds the owners of the files changed by the pull requests of the repo, which requires the
+// commit files to be collected by gitextractor. Owners are matched with the reviewers by user name or email, teams
+// can not be resolved, so a pull request only owned by teams is never flagged as merged without owner approval.
+func CalculatePrCodeOwners(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RefdiffTaskData)
+	repoId := data.Options.RepoId
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+
+	if data.Options.ProjectName != "" || repoId == "" {
+		return nil
+	}
+
+	err := db.Delete(
+		&code.PullRequestCodeOwner{},
+		dal.Where("pull_request_id IN (SELECT id FROM pull_requests WHERE base_repo_id = ?)", repoId),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to delete previous pull_request_code_owners")
+	}
+	var codeOwners []code.CodeOwner
+	err = db.All(&codeOwners, dal.Where("repo_id = ?", repoId), dal.Orderby("line_no, owner"))
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load code owners")
+	}
+	if len(codeOwners) == 0 {
+		logger.Info("repo %s has no CODEOWNERS, skip", repoId)
+		return nil
+	}
+	owners := helper.NewCodeOwners(codeOwnersRules(codeOwners))
+
+	filesByPr := make(map[string][]string)
+	cursor, err := db.Cursor(
+		dal.Select("DISTINCT prc.pull_request_id, cf.file_path"),
+		dal.From("pull_request_commits prc"),
+		dal.Join("JOIN pull_requests pr ON pr.id = prc.pull_request_id"),
+		dal.Join("JOIN commit_files cf ON cf.commit_sha = prc.commit_sha"),
+		dal.Where("pr.base_repo_id = ?", repoId),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load files of pull requests")
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		file := &prFile{}
+		err = db.Fetch(cursor, file)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to read file of pull request")
+		}
+		filesByPr[file.PullRequestId] = append(filesByPr[file.PullRequestId], file.FilePath)
+	}
+
+	var reviewers []prParticipant
+	err = db.All(
+		&reviewers,
+		dal.Select("prr.pull_request_id, prr.user_name, a.email"),
+		dal.From("pull_request_reviewers prr"),
+		dal.Join("JOIN pull_requests pr ON pr.id = prr.pull_request_id"),
+		dal.Join("LEFT JOIN accounts a ON a.id = prr.reviewer_id"),
+		dal.Where("pr.base_repo_id = ?", repoId),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load reviewers of pull requests")
+	}
+	// the bitbucket, bitbucket_server and azuredevops plugins convert approvals into reviews like github and gitlab
+	var approvers []prParticipant
+	err = db.All(
+		&approvers,
+		dal.Select("c.pull_request_id, a.user_name, a.email"),
+		dal.From("pull_request_comments c"),
+		dal.Join("JOIN pull_requests pr ON pr.id = c.pull_request_id"),
+		dal.Join("JOIN accounts a ON a.id = c.account_id"),
+		dal.Where("pr.base_repo_id = ? AND c.status = ?", repoId, "APPROVED"),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load approvals of pull requests")
+	}
+	reviewersByPr := participantsByPr(reviewers)
+	approversByPr := participantsByPr(approvers)
+
+	var prs []prMerge
+	err = db.All(&prs, dal.Select("id, merged_date"), dal.From(&code.PullRequest{}), dal.Where("base_repo_id = ?", repoId))
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load pull requests")
+	}
+	var prCodeOwners []*code.PullRequestCodeOwner
+	for _, pr := range prs {
+		files, ok := filesByPr[pr.Id]
+		if !ok {
+			continue
+		}
+		prCodeOwners = append(prCodeOwners, computePrCodeOwners(
+			owners, pr.Id, pr.MergedDate != nil, files, reviewersByPr[pr.Id], approversByPr[pr.Id],
+		)...)
+	}
+	logger.Info("%d owners required by %d pull requests of repo %s", len(prCodeOwners), len(prs), repoId)
+	return createInBatches(db, prCodeOwners)
+}
+
+// codeOwnersRules rebuilds the rules of the CODEOWNERS file, the code owners must be ordered by line
+func codeOwnersRules(codeOwners []code.CodeOwner) []*helper.CodeOwnersRule {
+	var rules []*helper.CodeOwnersRule
+	for _, codeOwner := range codeOwners {
+		if len(rules) == 0 || rules[len(rules)-1].LineNo != codeOwner.LineNo {
+			rules = append(rules, &helper.CodeOwnersRule{
+				LineNo:   codeOwner.LineNo,
+				Section:  codeOwner.Section,
+				Optional: codeOwner.Optional,
+				Pattern:  codeOwner.Pattern,
+			})
+		}
+		if codeOwner.Owner != "" {
+			rule := rules[len(rules)-1]
+			rule.Owners = append(rule.Owners, codeOwner.Owner)
+		}
+	}
+	return rules
+}
+
+func participantsByPr(participants []prParticipant) map[string]map[string]bool {
+	byPr := make(map[string]map[string]bool)
+	for _, participant := range participants {
+		if _, ok := byPr[participant.PullRequestId]; !ok {
+			byPr[participant.PullRequestId] = make(map[string]bool)
+		}
+		for _, identity := range []string{participant.UserName, participant.Email} {
+			if identity != "" {
+				byPr[participant.PullRequestId][strings.ToLower(identity)] = true
+			}
+		}
+	}
+	return byPr
+}
+
+// codeOwnerIdentity returns the user name of an "@user" owner or the email of an email owner in lower case, teams
+// ("@org/team" on GitHub and GitLab, "@@group" on Bitbucket) have none
+func codeOwnerIdentity(owner string) string {
+	if strings.HasPrefix(owner, "@@") || strings.Contains(owner, "/") {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(owner, "@"))
+}
+
+// computePrCodeOwners finds the required owners of the files changed by a pull request and whether they reviewed and
+// approved it, reviewers and approvers are the lower cased user names and emails. Every rule matching a changed file,
+// one in each section for GitLab, needs the approval of one of its owners, rules only owned by teams are skipped.
+func computePrCodeOwners(
+	owners *helper.CodeOwners,
+	prId string,
+	merged bool,
+	files []string,
+	reviewers map[string]bool,
+	approvers map[string]bool,
+) []*code.PullRequestCodeOwner {
+	byOwner := make(map[string]*code.PullRequestCodeOwner)
+	matchedRules := make(map[*helper.CodeOwnersRule]bool)
+	for _, file := range files {
+		seen := make(map[string]bool)
+		for _, rule := range owners.Rules(file, false) {
+			matchedRules[rule] = true
+			for _, owner := range rule.Owners {
+				if seen[owner] {
+					continue
+				}
+				seen[owner] = true
+				prCodeOwner, ok := byOwner[owner]
+				if !ok {
+					identity := codeOwnerIdentity(owner)
+					prCodeOwner = &code.PullRequestCodeOwner{
+						PullRequestId: prId,
+						Owner:         owner,
+						Approved:      identity != "" && approvers[identity],
+					}
+					prCodeOwner.Reviewed = prCodeOwner.Approved || (identity != "" && reviewers[identity])
+					byOwner[owner] = prCodeOwner
+				}
+				prCodeOwner.FileCount++
+			}
+		}
+	}
+
+	unapproved := false
+	for rule := range matchedRules {
+		verifiable, approved := false, false
+		for _, owner := range rule.Owners {
+			verifiable = verifiable || codeOwnerIdentity(owner) != ""
+			approved = approved || byOwner[owner].Approved
+		}
+		unapproved = unapproved || (verifiable && !approved)
+	}
+	prCodeOwners := make([]*code.PullRequestCodeOwner, 0, len(byOwner))
+	for _, prCodeOwner := range byOwner {
+		prCodeOwner.MergedWithoutOwnerApproval = merged && unapproved
+		prCodeOwners = append(prCodeOwners, prCodeOwner)
+	}
+	sort.Slice(prCodeOwners, func(i, j int) bool {
+		return prCodeOwners[i].Owner < prCodeOwners[j].Owner
+	})
+	return prCodeOwners
+}
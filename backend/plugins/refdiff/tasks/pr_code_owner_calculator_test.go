@@ -0,0 +1,99 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwnersRules(t *testing.T) {
+	rules := codeOwnersRules([]code.CodeOwner{
+		{LineNo: 1, Pattern: "*", Owner: "@alice"},
+		{LineNo: 1, Pattern: "*", Owner: "@bob"},
+		{LineNo: 3, Pattern: "/docs/", Owner: ""},
+		{LineNo: 5, Pattern: "*.css", Owner: "@carol", Section: "Design", Optional: true},
+	})
+	assert.Equal(t, []*helper.CodeOwnersRule{
+		{LineNo: 1, Pattern: "*", Owners: []string{"@alice", "@bob"}},
+		{LineNo: 3, Pattern: "/docs/"},
+		{LineNo: 5, Pattern: "*.css", Owners: []string{"@carol"}, Section: "Design", Optional: true},
+	}, rules)
+}
+
+func TestComputePrCodeOwners(t *testing.T) {
+	owners := helper.NewCodeOwners(helper.ParseCodeOwners(`
+*        @Alice
+/api/    @bob Carol@example.com
+/web/    @org/frontend
+`))
+
+	prCodeOwners := computePrCodeOwners(
+		owners, "pr1", true,
+		[]string{"api/user.go", "api/team.go", "README.md"},
+		map[string]bool{"alice": true, "bob": true},
+		map[string]bool{"carol@example.com": true},
+	)
+	// the approval of carol does not cover README.md owned by alice
+	assert.Equal(t, []*code.PullRequestCodeOwner{
+		{PullRequestId: "pr1", Owner: "@Alice", FileCount: 1, Reviewed: true, MergedWithoutOwnerApproval: true},
+		{PullRequestId: "pr1", Owner: "@bob", FileCount: 2, Reviewed: true, MergedWithoutOwnerApproval: true},
+		{PullRequestId: "pr1", Owner: "Carol@example.com", FileCount: 2, Reviewed: true, Approved: true, MergedWithoutOwnerApproval: true},
+	}, prCodeOwners)
+
+	prCodeOwners = computePrCodeOwners(
+		owners, "pr1", true,
+		[]string{"api/user.go", "README.md"},
+		nil,
+		map[string]bool{"carol@example.com": true, "alice": true},
+	)
+	for _, prCodeOwner := range prCodeOwners {
+		assert.False(t, prCodeOwner.MergedWithoutOwnerApproval)
+	}
+
+	// reviewed but not approved by any owner
+	prCodeOwners = computePrCodeOwners(owners, "pr2", true, []string{"api/user.go"}, map[string]bool{"bob": true}, nil)
+	assert.Len(t, prCodeOwners, 2)
+	assert.True(t, prCodeOwners[0].Reviewed)
+	assert.True(t, prCodeOwners[0].MergedWithoutOwnerApproval)
+	assert.True(t, prCodeOwners[1].MergedWithoutOwnerApproval)
+
+	// not merged yet
+	prCodeOwners = computePrCodeOwners(owners, "pr3", false, []string{"api/user.go"}, nil, nil)
+	assert.False(t, prCodeOwners[0].MergedWithoutOwnerApproval)
+
+	// teams can not be verified
+	prCodeOwners = computePrCodeOwners(owners, "pr4", true, []string{"web/app.js"}, nil, nil)
+	assert.Equal(t, []*code.PullRequestCodeOwner{{PullRequestId: "pr4", Owner: "@org/frontend", FileCount: 1}}, prCodeOwners)
+
+	// every section of GitLab needs an approval
+	sections := helper.NewCodeOwners(helper.ParseCodeOwners(`
+[Backend]
+*.go     @bob
+[Docs]
+*        @carol
+`))
+	prCodeOwners = computePrCodeOwners(sections, "pr5", true, []string{"main.go"}, nil, map[string]bool{"bob": true})
+	assert.Len(t, prCodeOwners, 2)
+	assert.True(t, prCodeOwners[0].MergedWithoutOwnerApproval)
+	prCodeOwners = computePrCodeOwners(sections, "pr5", true, []string{"main.go"}, nil, map[string]bool{"bob": true, "carol": true})
+	assert.False(t, prCodeOwners[0].MergedWithoutOwnerApproval)
+}
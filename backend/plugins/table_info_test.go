@@ -31,6 +31,7 @@ import (
 	dbt "github.com/apache/incubator-devlake/plugins/dbt/impl"
 	dora "github.com/apache/incubator-devlake/plugins/dora/impl"
 	feishu "github.com/apache/incubator-devlake/plugins/feishu/impl"
+	flowMetrics "github.com/apache/incubator-devlake/plugins/flow_metrics/impl"
 	gitee "github.com/apache/incubator-devlake/plugins/gitee/impl"
 	gitextractor "github.com/apache/incubator-devlake/plugins/gitextractor/impl"
 	github "github.com/apache/incubator-devlake/plugins/github/impl"
@@ -92,6 +93,7 @@ func Test_GetPluginTablesInfo(t *testing.T) {
 	checker.FeedIn("opsgenie/models", opsgenie.Opsgenie{}.GetTablesInfo)
 	checker.FeedIn("linker/models", linker.Linker{}.GetTablesInfo)
 	checker.FeedIn("issue_trace/models", issueTrace.IssueTrace{}.GetTablesInfo)
+	checker.FeedIn("flow_metrics/models", flowMetrics.FlowMetrics{}.GetTablesInfo)
 	err := checker.Verify()
 	if err != nil {
 		t.Error(err)
@@ -99,6 +99,10 @@ func CreateApiServer() *gin.Engine {
 	router.GET("/ready", ping.Ready)
 	router.GET("/health", ping.Health)
 	router.GET("/version", version.Get)
+	// Metrics are exposed to the scrapers without credentials only if explicitly allowed
+	if cfg.GetBool("METRICS_ALLOW_ANONYMOUS") {
+		router.GET("/metrics", ping.Metrics)
+	}
 
 	// Api keys
 	router.Use(RestAuthentication(router, basicRes))
@@ -147,6 +151,16 @@ func SetupApiServer(router *gin.Engine) {
 		}
 	})
 
+	// Authorize requests by the roles bound to the user or api key
+	if basicRes.GetConfigReader().GetBool("ENABLE_RBAC") {
+		router.Use(RbacAuthorization(basicRes))
+	}
+	router.Use(AuditLogging())
+	// Otherwise metrics are authenticated and authorized as any other endpoint
+	if !basicRes.GetConfigReader().GetBool("METRICS_ALLOW_ANONYMOUS") {
+		router.GET("/metrics", ping.Metrics)
+	}
+
 	// Add swagger handlers
 	router.GET("/swagger/*any", modifyBasePath, ginSwagger.WrapHandler(swaggerFiles.Handler))
 	registerExtraOpenApiSpecs(router)
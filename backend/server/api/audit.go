@@ -0,0 +1,167 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// newAuditLog creates an AuditLog of the request with the actor filled, the api key takes precedence over the
+// user from the proxy headers
+func newAuditLog(c *gin.Context) (*models.AuditLog, *common.User) {
+	auditLog := &models.AuditLog{
+		Method:   c.Request.Method,
+		Endpoint: c.Request.URL.Path,
+	}
+	user, _ := shared.GetUser(c)
+	if apiKey := getApiKey(c); apiKey != nil {
+		auditLog.ApiKeyId = apiKey.ID
+		user = &common.User{Name: apiKey.Creator.Creator, Email: apiKey.Creator.CreatorEmail}
+	}
+	if user != nil {
+		auditLog.Actor = user.Name
+		auditLog.ActorEmail = user.Email
+	}
+	return auditLog, user
+}
+
+// getAuditTarget extracts the target entity from the matched route, the last `:param` of the route is taken
+// as the id of the collection before it, e.g. `/plugins/github/connections/:connectionId/test` targets
+// `github/connections` 1
+func getAuditTarget(c *gin.Context) *services.AuditTarget {
+	target := &services.AuditTarget{}
+	path := strings.TrimPrefix(c.FullPath(), "/")
+	if strings.HasPrefix(path, "plugins/") {
+		segments := strings.SplitN(path, "/", 3)
+		if len(segments) < 3 {
+			return target
+		}
+		target.PluginName = segments[1]
+		path = segments[2]
+	}
+	segments := strings.Split(path, "/")
+	collection := ""
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			target.Type = collection
+			target.Id = c.Param(segment[1:])
+			target.ResourcePath = strings.Join(segments[:i+1], "/")
+		} else if !strings.HasPrefix(segment, "*") && target.Id == "" {
+			collection = segment
+		}
+	}
+	if target.Id == "" {
+		target.Type = collection
+	}
+	if target.PluginName != "" {
+		target.Type = target.PluginName + "/" + target.Type
+		target.Params = make(map[string]string)
+		for _, param := range c.Params {
+			target.Params[param.Key] = param.Value
+		}
+	}
+	return target
+}
+
+// isConnectionSecretAccess reports whether the request reads a connection or uses its token
+func isConnectionSecretAccess(c *gin.Context) bool {
+	path := c.FullPath()
+	if !strings.HasPrefix(path, "/plugins/") || c.Param("connectionId") == "" {
+		return false
+	}
+	if strings.HasSuffix(path, "/connections/:connectionId") {
+		return true
+	}
+	for _, suffix := range connectionPrivilegedSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// AuditLogging records all mutating api calls and the connection secret accesses with the redacted changes of
+// the target entity
+func AuditLogging() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mutating := true
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			mutating = false
+		}
+		if c.FullPath() == "" || (!mutating && !isConnectionSecretAccess(c)) {
+			c.Next()
+			return
+		}
+		auditLog, _ := newAuditLog(c)
+		target := getAuditTarget(c)
+		auditLog.TargetType = target.Type
+		auditLog.TargetId = target.Id
+		auditLog.ProjectName = c.Param("projectName")
+		var before map[string]interface{}
+		var writer *auditResponseWriter
+		if mutating {
+			before = target.Snapshot()
+			// the id of the created entity is only known from the response
+			if target.Id == "" && c.Request.Method == http.MethodPost {
+				writer = &auditResponseWriter{ResponseWriter: c.Writer}
+				c.Writer = writer
+			}
+		}
+
+		c.Next()
+
+		auditLog.Outcome = models.AUDIT_ALLOWED
+		auditLog.Status = c.Writer.Status()
+		if mutating && auditLog.Status < http.StatusBadRequest {
+			var after map[string]interface{}
+			if writer != nil {
+				var created interface{}
+				if json.Unmarshal(writer.body.Bytes(), &created) == nil {
+					after = services.RedactAuditSnapshot(created)
+				}
+			} else if c.Request.Method != http.MethodDelete {
+				after = target.Snapshot()
+			}
+			auditLog.Before, auditLog.After = services.DiffAuditSnapshots(before, after)
+		}
+		if len(c.Errors) > 0 {
+			auditLog.Message = c.Errors.String()
+		}
+		services.CreateAuditLog(auditLog)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionkeys
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaginatedEncryptionKeyRotations struct {
+	Rotations []*models.EncryptionKeyRotation `json:"rotations"`
+	Count     int64                           `json:"count"`
+}
+
+type RotationQuery struct {
+	DryRun bool `form:"dryRun"`
+}
+
+// @Summary Get list of encryption key rotations
+// @Description GET /encryption-keys/rotations?keyId=xxx&page=1&pageSize=10
+// @Tags framework/encryption-keys
+// @Param keyId query string false "query"
+// @Param page query int false "query"
+// @Param pageSize query int false "query"
+// @Success 200  {object} PaginatedEncryptionKeyRotations
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /encryption-keys/rotations [get]
+func GetRotations(c *gin.Context) {
+	var query services.EncryptionKeyRotationQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	rotations, count, err := services.GetEncryptionKeyRotations(&query)
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting encryption key rotations"))
+		return
+	}
+	shared.ApiOutputSuccess(c, PaginatedEncryptionKeyRotations{
+		Rotations: rotations,
+		Count:     count,
+	}, http.StatusOK)
+}
+
+// @Summary Rotate the encryption key
+// @Description Re-encrypt all encrypted columns with the key ENCRYPTION_KEY_ID, the last unfinished rotation of the same key would be resumed
+// @Tags framework/encryption-keys
+// @Param dryRun query bool false "report the values to be rotated without writing them"
+// @Success 200  {object} models.EncryptionKeyRotation
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /encryption-keys/rotations [post]
+func PostRotation(c *gin.Context) {
+	var query RotationQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	rotation, err := services.RotateEncryptionKey(query.DryRun)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error rotating the encryption key"))
+		return
+	}
+	shared.ApiOutputSuccess(c, rotation, http.StatusOK)
+}
@@ -150,18 +150,7 @@ func CheckAuthorizationHeader(c *gin.Context, logger log.Logger, db dal.Dal, api
 		return false
 	}
 
-	hashedApiKey, err := apiKeyHelper.DigestToken(apiKeyStr)
-	if err != nil {
-		logger.Error(err, "DigestToken")
-		c.Abort()
-		c.JSON(http.StatusInternalServerError, &apiBody{
-			Success: false,
-			Message: err.Error(),
-		})
-		return false
-	}
-
-	apiKey, err := apiKeyHelper.GetApiKey(nil, dal.Where("api_key = ?", hashedApiKey))
+	apiKey, err := apiKeyHelper.FindApiKeyByToken(apiKeyStr)
 	if err != nil {
 		c.Abort()
 		if db.IsErrorNotFound(err) {
@@ -207,6 +196,7 @@ func CheckAuthorizationHeader(c *gin.Context, logger log.Logger, db dal.Dal, api
 
 	logger.Info("redirect path: %s to: %s", c.Request.URL.Path, path)
 	c.Request.URL.Path = path
+	withApiKey(c, apiKey)
 	c.Set(common.USER, &common.User{
 		Name:  apiKey.Creator.Creator,
 		Email: apiKey.Creator.CreatorEmail,
@@ -0,0 +1,205 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notificationchannels
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaginatedNotificationChannels struct {
+	NotificationChannels []*models.NotificationChannel `json:"notificationChannels"`
+	Count                int64                         `json:"count"`
+}
+
+type PaginatedNotifications struct {
+	Notifications []*models.Notification `json:"notifications"`
+	Count         int64                  `json:"count"`
+}
+
+func getChannelId(c *gin.Context) (uint64, errors.Error) {
+	id, err := strconv.ParseUint(c.Param("channelId"), 10, 64)
+	if err != nil {
+		return 0, errors.BadInput.Wrap(err, "bad channelId format supplied")
+	}
+	return id, nil
+}
+
+// @Summary Get list of notification channels
+// @Description GET /notification-channels?blueprintId=1&projectName=xxx&page=1&pageSize=10
+// @Tags framework/notification-channels
+// @Param blueprintId query int false "query"
+// @Param projectName query string false "query"
+// @Param page query int false "query"
+// @Param pageSize query int false "query"
+// @Success 200  {object} PaginatedNotificationChannels
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /notification-channels [get]
+func Index(c *gin.Context) {
+	var query services.NotificationChannelQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	query.ProjectNames = shared.GetProjectScope(c)
+	channels, count, err := services.GetNotificationChannels(&query)
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting notification channels"))
+		return
+	}
+	shared.ApiOutputSuccess(c, PaginatedNotificationChannels{
+		NotificationChannels: channels,
+		Count:                count,
+	}, http.StatusOK)
+}
+
+// @Summary Get a notification channel
+// @Description Get a notification channel
+// @Tags framework/notification-channels
+// @Param channelId path int true "channelId"
+// @Success 200  {object} models.NotificationChannel
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /notification-channels/{channelId} [get]
+func Get(c *gin.Context) {
+	id, err := getChannelId(c)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	channel, err := services.GetNotificationChannel(id)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting notification channel"))
+		return
+	}
+	shared.ApiOutputSuccess(c, channel, http.StatusOK)
+}
+
+// @Summary Create a notification channel
+// @Description Subscribe a webhook, slack, feishu or email channel to the events of a blueprint or project
+// @Tags framework/notification-channels
+// @Accept application/json
+// @Param channel body models.NotificationChannel true "json"
+// @Success 200  {object} models.NotificationChannel
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /notification-channels [post]
+func Post(c *gin.Context) {
+	channel := &models.NotificationChannel{}
+	err := c.ShouldBind(channel)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	channel, err = services.CreateNotificationChannel(channel)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating notification channel"))
+		return
+	}
+	shared.ApiOutputSuccess(c, channel, http.StatusCreated)
+}
+
+// @Summary Patch a notification channel
+// @Description Patch a notification channel
+// @Tags framework/notification-channels
+// @Accept application/json
+// @Param channelId path int true "channelId"
+// @Success 200  {object} models.NotificationChannel
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /notification-channels/{channelId} [patch]
+func Patch(c *gin.Context) {
+	id, err := getChannelId(c)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	var body map[string]interface{}
+	err = errors.Convert(c.ShouldBind(&body))
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	channel, err := services.PatchNotificationChannel(id, body)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error patching notification channel"))
+		return
+	}
+	shared.ApiOutputSuccess(c, channel, http.StatusOK)
+}
+
+// @Summary Delete a notification channel
+// @Description Delete a notification channel
+// @Tags framework/notification-channels
+// @Param channelId path int true "channelId"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /notification-channels/{channelId} [delete]
+func Delete(c *gin.Context) {
+	id, err := getChannelId(c)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	err = services.DeleteNotificationChannel(id)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting notification channel"))
+		return
+	}
+	shared.ApiOutputSuccess(c, nil, http.StatusOK)
+}
+
+// @Summary Get list of notifications
+// @Description GET /notifications?channelId=1&type=PipelineFailed&status=FAILED&page=1&pageSize=10
+// @Tags framework/notification-channels
+// @Param channelId query int false "query"
+// @Param type query string false "query"
+// @Param status query string false "query"
+// @Param page query int false "query"
+// @Param pageSize query int false "query"
+// @Success 200  {object} PaginatedNotifications
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /notifications [get]
+func GetNotifications(c *gin.Context) {
+	var query services.NotificationQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	query.ProjectNames = shared.GetProjectScope(c)
+	notifications, count, err := services.GetNotifications(&query)
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting notifications"))
+		return
+	}
+	shared.ApiOutputSuccess(c, PaginatedNotifications{
+		Notifications: notifications,
+		Count:         count,
+	}, http.StatusOK)
+}
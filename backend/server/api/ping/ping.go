@@ -20,6 +20,7 @@ package ping
 import (
 	"net/http"
 
+	"github.com/apache/incubator-devlake/core/metrics"
 	"github.com/apache/incubator-devlake/server/api/shared"
 	"github.com/apache/incubator-devlake/server/services"
 	"github.com/gin-gonic/gin"
@@ -67,3 +68,15 @@ func Health(c *gin.Context) {
 	}
 	shared.ApiOutputSuccess(c, shared.ApiBody{Success: true, Message: msg}, http.StatusOK)
 }
+
+var metricsHandler = metrics.Handler()
+
+// @Summary Metrics
+// @Description export the pipeline, task, subtask and api client metrics in the Prometheus/OpenMetrics format
+// @Tags framework/ping
+// @Produce plain
+// @Success 200
+// @Router /metrics [get]
+func Metrics(c *gin.Context) {
+	metricsHandler.ServeHTTP(c.Writer, c.Request)
+}
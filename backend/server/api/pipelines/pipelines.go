@@ -18,14 +18,17 @@ limitations under the License.
 package pipelines
 
 import (
+	"encoding/json"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/server/api/shared"
 	"github.com/apache/incubator-devlake/server/services"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -78,6 +81,7 @@ func Index(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
+	query.ProjectNames = shared.GetProjectScope(c)
 	pipelines, count, err := services.GetPipelines(&query, true)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting pipelines"))
@@ -115,6 +119,29 @@ func Get(c *gin.Context) {
 	shared.ApiOutputSuccess(c, pipeline, http.StatusOK)
 }
 
+// @Summary Get the reprocess report of a pipeline
+// @Description Get the number of domain rows inserted, updated or deleted by a pipeline triggered in reprocess mode
+// @Tags framework/pipelines
+// @Param pipelineId path int true "pipeline ID"
+// @Success 200  {object} []models.ReprocessReport
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /pipelines/{pipelineId}/reprocess-report [get]
+func GetReprocessReport(c *gin.Context) {
+	pipelineId := c.Param("pipelineId")
+	id, err := strconv.ParseUint(pipelineId, 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad pipelineID format supplied"))
+		return
+	}
+	reports, err := services.GetReprocessReports(id)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting reprocess report"))
+		return
+	}
+	shared.ApiOutputSuccess(c, reports, http.StatusOK)
+}
+
 // @Summary Cancel a pending pipeline
 // @Description Cancel a pending pipeline
 // @Tags framework/pipelines
@@ -168,6 +195,141 @@ func DownloadLogs(c *gin.Context) {
 	c.FileAttachment(archive, filepath.Base(archive))
 }
 
+// @Summary search logs of a pipeline
+// @Description GET /pipelines/:pipelineId/logs?task_id=1&subtask=collectIssues&level=warning&text=timeout
+// @Description streams the matching log entries as newline delimited json, the pipeline log first and then the task logs
+// @Tags framework/pipelines
+// @Param pipelineId path int true "pipelineId"
+// @Param task_id query int false "task_id"
+// @Param subtask query string false "subtask"
+// @Param level query string false "minimum level: debug, info, warning or error"
+// @Param text query string false "text in the messages, case-insensitive"
+// @Produce application/x-ndjson
+// @Success 200  {object} services.PipelineLogEntry
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 404  {string} errcode.Error "Pipeline or Log files not found"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /pipelines/{pipelineId}/logs [get]
+func GetLogs(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("pipelineId"), 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad pipeline ID format supplied"))
+		return
+	}
+	var query services.PipelineLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	pipeline, err := services.GetPipeline(id, true)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting pipeline"))
+		return
+	}
+	// entries are flushed in batches as they are found, errors can only be reported before the first one
+	const flushEvery = 100
+	streamed := 0
+	encoder := json.NewEncoder(c.Writer)
+	err = services.SearchPipelineLogs(pipeline, &query, func(entry *services.PipelineLogEntry) errors.Error {
+		if err := c.Request.Context().Err(); err != nil {
+			return errors.Convert(err)
+		}
+		if streamed == 0 {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(http.StatusOK)
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return errors.Convert(err)
+		}
+		streamed++
+		if streamed%flushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil && streamed == 0 {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error searching logs of pipeline"))
+		return
+	}
+	if streamed == 0 {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+	}
+	c.Writer.Flush()
+}
+
+// @Summary stream events of a pipeline
+// @Description GET /pipelines/:pipelineId/events
+// @Description streams the progress and the status transitions of the pipeline, its tasks and subtasks as Server-Sent Events,
+// @Description the current status of the pipeline is sent first and the stream ends once the pipeline finished.
+// @Description In worker mode only the events of the pipelines run by the instance serving the request are streamed.
+// @Tags framework/pipelines
+// @Param pipelineId path int true "pipelineId"
+// @Produce text/event-stream
+// @Success 200  {object} services.PipelineEvent
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 404  {string} errcode.Error "Pipeline not found"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /pipelines/{pipelineId}/events [get]
+func GetEvents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("pipelineId"), 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad pipeline ID format supplied"))
+		return
+	}
+	// subscribe before reading the status, so no transition would be missed in between
+	events, unsubscribe := services.SubscribePipelineEvents(id)
+	defer unsubscribe()
+	pipeline, err := services.GetPipeline(id, false)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting pipeline"))
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent(services.PipelineEventPipelineStatus, &services.PipelineEvent{
+		Type:       services.PipelineEventPipelineStatus,
+		PipelineId: pipeline.ID,
+		Status:     pipeline.Status,
+		Message:    pipeline.Message,
+		Time:       time.Now(),
+	})
+	if services.IsPipelineFinished(pipeline) {
+		return
+	}
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return !event.IsFinished()
+		case <-heartbeat.C:
+			// the pipeline may be run by a worker, whose events are never published on this instance
+			pipeline, err := services.GetPipeline(id, false)
+			if err == nil && services.IsPipelineFinished(pipeline) {
+				c.SSEvent(services.PipelineEventPipelineStatus, &services.PipelineEvent{
+					Type:       services.PipelineEventPipelineStatus,
+					PipelineId: pipeline.ID,
+					Status:     pipeline.Status,
+					Message:    pipeline.Message,
+					Time:       time.Now(),
+				})
+				return false
+			}
+			// a comment keeps the connection open through the proxies
+			_, writeErr := io.WriteString(w, ": heartbeat\n\n")
+			return writeErr == nil
+		}
+	})
+}
+
 // RerunPipeline rerun all failed tasks of the specified pipeline
 // @Summary rerun tasks
 // @Tags framework/pipelines
@@ -92,6 +92,7 @@ func GetProjects(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
+	query.ProjectNames = shared.GetProjectScope(c)
 	projects, count, err := services.GetProjects(&query)
 	if err != nil {
 		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting projects"))
@@ -179,3 +180,21 @@ func DeleteProject(c *gin.Context) {
 	}
 	shared.ApiOutputSuccess(c, nil, http.StatusOK)
 }
+
+// @Summary Reprocess a project
+// @Description Trigger the blueprint of the project in reprocess mode, the extractors, convertors and enrichers are rerun from the raw data already collected without hitting the source APIs
+// @Tags framework/projects
+// @Param projectName path string true "project name"
+// @Success 200  {object} models.Pipeline
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /projects/{projectName}/reprocess [post]
+func PostReprocess(c *gin.Context) {
+	projectName := c.Param("projectName")
+	pipeline, err := services.ReprocessProject(projectName)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error reprocessing project"))
+		return
+	}
+	shared.ApiOutputSuccess(c, pipeline, http.StatusOK)
+}
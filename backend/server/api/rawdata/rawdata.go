@@ -0,0 +1,203 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rawdata
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionQuery struct {
+	DryRun bool `form:"dryRun"`
+}
+
+type ImportArchiveRequest struct {
+	Path string `json:"path" binding:"required"`
+}
+
+type ImportArchiveResponse struct {
+	Imported int64 `json:"imported"`
+}
+
+func getPolicyId(c *gin.Context) (uint64, errors.Error) {
+	id, err := strconv.ParseUint(c.Param("policyId"), 10, 64)
+	if err != nil {
+		return 0, errors.BadInput.Wrap(err, "bad policyId format supplied")
+	}
+	return id, nil
+}
+
+// @Summary Get list of raw data retention policies
+// @Description Get list of raw data retention policies
+// @Tags framework/raw-data
+// @Success 200  {object} []models.RawDataRetentionPolicy
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /raw-data/retention-policies [get]
+func GetPolicies(c *gin.Context) {
+	policies, err := services.GetRawDataRetentionPolicies()
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting raw data retention policies"))
+		return
+	}
+	shared.ApiOutputSuccess(c, policies, http.StatusOK)
+}
+
+// @Summary Create a raw data retention policy
+// @Description Limit the rows kept in the raw tables of a plugin, or of a single raw table
+// @Tags framework/raw-data
+// @Accept application/json
+// @Param policy body models.RawDataRetentionPolicy true "json"
+// @Success 200  {object} models.RawDataRetentionPolicy
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /raw-data/retention-policies [post]
+func PostPolicy(c *gin.Context) {
+	policy := &models.RawDataRetentionPolicy{}
+	err := c.ShouldBind(policy)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	policy, err = services.CreateRawDataRetentionPolicy(policy)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating raw data retention policy"))
+		return
+	}
+	shared.ApiOutputSuccess(c, policy, http.StatusCreated)
+}
+
+// @Summary Patch a raw data retention policy
+// @Description Patch a raw data retention policy
+// @Tags framework/raw-data
+// @Accept application/json
+// @Param policyId path int true "policyId"
+// @Success 200  {object} models.RawDataRetentionPolicy
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /raw-data/retention-policies/{policyId} [patch]
+func PatchPolicy(c *gin.Context) {
+	id, err := getPolicyId(c)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	var body map[string]interface{}
+	err = errors.Convert(c.ShouldBind(&body))
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	policy, err := services.PatchRawDataRetentionPolicy(id, body)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error patching raw data retention policy"))
+		return
+	}
+	shared.ApiOutputSuccess(c, policy, http.StatusOK)
+}
+
+// @Summary Delete a raw data retention policy
+// @Description Delete a raw data retention policy
+// @Tags framework/raw-data
+// @Param policyId path int true "policyId"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /raw-data/retention-policies/{policyId} [delete]
+func DeletePolicy(c *gin.Context) {
+	id, err := getPolicyId(c)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	err = services.DeleteRawDataRetentionPolicy(id)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting raw data retention policy"))
+		return
+	}
+	shared.ApiOutputSuccess(c, nil, http.StatusOK)
+}
+
+// @Summary Apply the raw data retention policies
+// @Description Prune, archive and compress the raw tables by their retention policies now instead of waiting for RAW_DATA_RETENTION_CRON
+// @Tags framework/raw-data
+// @Param dryRun query bool false "report the rows to be pruned or compressed without writing them"
+// @Success 200  {object} []services.RawDataRetentionReport
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /raw-data/retention [post]
+func PostRetention(c *gin.Context) {
+	var query RetentionQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	reports, err := services.ApplyRawDataRetention(query.DryRun)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error applying raw data retention policies"))
+		return
+	}
+	shared.ApiOutputSuccess(c, reports, http.StatusOK)
+}
+
+// @Summary Get list of raw data archives
+// @Description Get list of the archives of the pruned raw rows under RAW_DATA_ARCHIVE_DIR
+// @Tags framework/raw-data
+// @Success 200  {object} []services.RawDataArchive
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /raw-data/archives [get]
+func GetArchives(c *gin.Context) {
+	archives, err := services.GetRawDataArchives()
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting raw data archives"))
+		return
+	}
+	shared.ApiOutputSuccess(c, archives, http.StatusOK)
+}
+
+// @Summary Import a raw data archive
+// @Description Import the archived rows back into their raw table with the original ids, so they could be reprocessed
+// @Tags framework/raw-data
+// @Accept application/json
+// @Param request body ImportArchiveRequest true "json"
+// @Success 200  {object} ImportArchiveResponse
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /raw-data/archives/import [post]
+func PostArchiveImport(c *gin.Context) {
+	request := &ImportArchiveRequest{}
+	err := c.ShouldBind(request)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	imported, err := services.ImportRawDataArchive(request.Path)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error importing raw data archive"))
+		return
+	}
+	shared.ApiOutputSuccess(c, ImportArchiveResponse{Imported: imported}, http.StatusOK)
+}
@@ -0,0 +1,193 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type apiKeyContextKey struct{}
+
+// withApiKey attaches the api key to the request, `gin.Context.Keys` can not be used since they are reset
+// when the `/rest` request is redirected by `router.HandleContext`
+func withApiKey(c *gin.Context, apiKey *models.ApiKey) {
+	c.Request = c.Request.WithContext(gocontext.WithValue(c.Request.Context(), apiKeyContextKey{}, apiKey))
+}
+
+func getApiKey(c *gin.Context) *models.ApiKey {
+	apiKey, _ := c.Request.Context().Value(apiKeyContextKey{}).(*models.ApiKey)
+	return apiKey
+}
+
+// the services used by the authorization, replaced by the tests
+var (
+	getSubjectRoleBindings      = services.GetSubjectRoleBindings
+	getProjectNamesOfConnection = services.GetProjectNamesOfConnection
+	createAuditLog              = services.CreateAuditLog
+)
+
+// adminOnlyPaths can only be accessed by admins no matter the http method
+var adminOnlyPaths = []string{"/api-keys", "/role-bindings", "/audit-logs", "/encryption-keys", "/raw-data", "/push/"}
+
+// connectionPrivilegedSuffixes are connection endpoints using the connection token to talk to the remote
+// service, they require the project-maintainer role even though they are GET requests
+var connectionPrivilegedSuffixes = []string{"/test", "/remote-scopes", "/search-remote-scopes", "/proxy/rest/*path"}
+
+// getRequiredRole returns the role required by the request and the projects it touches, an empty project list
+// means the request is not scoped to any project.
+func getRequiredRole(c *gin.Context) (models.Role, []string, errors.Error) {
+	path := c.FullPath()
+	for _, prefix := range adminOnlyPaths {
+		if strings.HasPrefix(path, prefix) {
+			return models.ROLE_ADMIN, nil, nil
+		}
+	}
+	required := models.ROLE_PROJECT_MAINTAINER
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		required = models.ROLE_VIEWER
+	}
+	projectName, projectNames, err := getRequestProjects(c)
+	if err != nil {
+		return "", nil, err
+	}
+	if projectName != "" {
+		projectNames = append(projectNames, projectName)
+	}
+	if strings.HasPrefix(path, "/plugins/") && c.Param("connectionId") != "" {
+		for _, suffix := range connectionPrivilegedSuffixes {
+			if strings.HasSuffix(path, suffix) {
+				required = models.ROLE_PROJECT_MAINTAINER
+			}
+		}
+		// a connection shared by projects of multiple teams could only be modified by admins
+		if required != models.ROLE_VIEWER && len(projectNames) > 1 {
+			required = models.ROLE_ADMIN
+		}
+	}
+	return required, projectNames, nil
+}
+
+func getRequestProjects(c *gin.Context) (string, []string, errors.Error) {
+	if projectName := c.Param("projectName"); projectName != "" {
+		return projectName, nil, nil
+	}
+	id := func(key string) (uint64, errors.Error) {
+		value, err := strconv.ParseUint(c.Param(key), 10, 64)
+		if err != nil {
+			return 0, errors.BadInput.Wrap(err, fmt.Sprintf("bad %s format supplied", key))
+		}
+		return value, nil
+	}
+	var projectName string
+	var projectNames []string
+	var err errors.Error
+	switch path := c.FullPath(); {
+	case c.Param("blueprintId") != "":
+		var blueprintId uint64
+		if blueprintId, err = id("blueprintId"); err == nil {
+			projectName, err = services.GetProjectNameOfBlueprint(blueprintId)
+		}
+	case c.Param("pipelineId") != "":
+		var pipelineId uint64
+		if pipelineId, err = id("pipelineId"); err == nil {
+			projectName, err = services.GetProjectNameOfPipeline(pipelineId)
+		}
+	case c.Param("taskId") != "":
+		var taskId uint64
+		if taskId, err = id("taskId"); err == nil {
+			projectName, err = services.GetProjectNameOfTask(taskId)
+		}
+	case c.Param("channelId") != "":
+		var channelId uint64
+		if channelId, err = id("channelId"); err == nil {
+			projectName, err = services.GetProjectNameOfNotificationChannel(channelId)
+		}
+	case strings.HasPrefix(path, "/plugins/") && c.Param("connectionId") != "":
+		var connectionId uint64
+		if connectionId, err = id("connectionId"); err == nil {
+			pluginName := strings.Split(path, "/")[2]
+			projectNames, err = getProjectNamesOfConnection(pluginName, connectionId)
+		}
+	}
+	return projectName, projectNames, err
+}
+
+// RbacAuthorization checks the roles bound to the user from the proxy headers or to the api key against the
+// role required by the request, denied requests are recorded in the audit log. Reads not scoped to a project,
+// e.g. listings, are restricted to the projects the viewer is bound to unless the viewer is bound globally.
+func RbacAuthorization(basicRes context.BasicRes) gin.HandlerFunc {
+	logger := basicRes.GetLogger()
+	return func(c *gin.Context) {
+		// unknown routes would be responded with 404 by gin
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		auditLog, user := newAuditLog(c)
+		auditLog.Outcome = models.AUDIT_DENIED
+		target := getAuditTarget(c)
+		auditLog.TargetType = target.Type
+		auditLog.TargetId = target.Id
+		if user == nil || (user.Name == "" && auditLog.ApiKeyId == 0) {
+			auditLog.Message = "authentication is required"
+			createAuditLog(auditLog)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, &apiBody{Success: false, Message: auditLog.Message})
+			return
+		}
+		required, projectNames, err := getRequiredRole(c)
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			c.Abort()
+			return
+		}
+		bindings, err := getSubjectRoleBindings(user, auditLog.ApiKeyId)
+		if err != nil {
+			shared.ApiOutputAbort(c, err)
+			return
+		}
+		if !bindings.Allows(required, projectNames...) {
+			auditLog.ProjectName = strings.Join(projectNames, ",")
+			auditLog.Message = fmt.Sprintf("role %s is required", required)
+			if len(projectNames) > 0 {
+				auditLog.Message += fmt.Sprintf(" on project %s", auditLog.ProjectName)
+			}
+			logger.Info("denied %s %s for %s: %s", auditLog.Method, auditLog.Endpoint, auditLog.Actor, auditLog.Message)
+			createAuditLog(auditLog)
+			c.AbortWithStatusJSON(http.StatusForbidden, &apiBody{Success: false, Message: auditLog.Message})
+			return
+		}
+		if required == models.ROLE_VIEWER && len(projectNames) == 0 {
+			if scope, all := bindings.ProjectScope(models.ROLE_VIEWER); !all {
+				shared.WithProjectScope(c, scope)
+			}
+		}
+		c.Next()
+	}
+}
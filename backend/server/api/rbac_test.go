@@ -0,0 +1,248 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRbacAuthorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var bindings models.RoleBindings
+	var connectionProjects []string
+	var auditLogs []*models.AuditLog
+	bindingsOf, projectsOf, create := getSubjectRoleBindings, getProjectNamesOfConnection, createAuditLog
+	defer func() {
+		getSubjectRoleBindings, getProjectNamesOfConnection, createAuditLog = bindingsOf, projectsOf, create
+	}()
+	getSubjectRoleBindings = func(*common.User, uint64) (models.RoleBindings, errors.Error) {
+		return bindings, nil
+	}
+	getProjectNamesOfConnection = func(string, uint64) ([]string, errors.Error) {
+		return connectionProjects, nil
+	}
+	createAuditLog = func(auditLog *models.AuditLog) {
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if name := c.GetHeader("X-User"); name != "" {
+			c.Set(common.USER, &common.User{Name: name})
+		}
+	})
+	router.Use(RbacAuthorization(unithelper.DummyBasicRes(func(mockDal *mockdal.Dal) {})))
+	var scope []string
+	handler := func(c *gin.Context) {
+		scope = shared.GetProjectScope(c)
+		c.Status(http.StatusOK)
+	}
+	router.GET("/projects", handler)
+	router.POST("/projects", handler)
+	router.GET("/projects/:projectName", handler)
+	router.PATCH("/projects/:projectName", handler)
+	router.GET("/api-keys", handler)
+	router.GET("/plugins/github/connections/:connectionId", handler)
+	router.PATCH("/plugins/github/connections/:connectionId", handler)
+	router.GET("/plugins/github/connections/:connectionId/test", handler)
+
+	viewerOfFoo := models.RoleBindings{{Role: models.ROLE_VIEWER, ProjectName: "foo"}}
+	maintainerOfFoo := models.RoleBindings{{Role: models.ROLE_PROJECT_MAINTAINER, ProjectName: "foo"}}
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		user               string
+		bindings           models.RoleBindings
+		connectionProjects []string
+		wantStatus         int
+		wantMessage        string
+		wantScope          []string
+	}{
+		{
+			name:        "anonymous",
+			method:      http.MethodGet,
+			path:        "/projects/foo",
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "authentication is required",
+		},
+		{
+			name:       "viewer reads the project",
+			method:     http.MethodGet,
+			path:       "/projects/foo",
+			user:       "alice",
+			bindings:   viewerOfFoo,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "viewer reads another project",
+			method:      http.MethodGet,
+			path:        "/projects/bar",
+			user:        "alice",
+			bindings:    viewerOfFoo,
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "role viewer is required on project bar",
+		},
+		{
+			name:        "viewer modifies the project",
+			method:      http.MethodPatch,
+			path:        "/projects/foo",
+			user:        "alice",
+			bindings:    viewerOfFoo,
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "role project-maintainer is required on project foo",
+		},
+		{
+			name:       "maintainer modifies the project",
+			method:     http.MethodPatch,
+			path:       "/projects/foo",
+			user:       "alice",
+			bindings:   maintainerOfFoo,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "project maintainer creates a project",
+			method:      http.MethodPost,
+			path:        "/projects",
+			user:        "alice",
+			bindings:    maintainerOfFoo,
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "role project-maintainer is required",
+		},
+		{
+			name:        "global maintainer lists api keys",
+			method:      http.MethodGet,
+			path:        "/api-keys",
+			user:        "alice",
+			bindings:    models.RoleBindings{{Role: models.ROLE_PROJECT_MAINTAINER}},
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "role admin is required",
+		},
+		{
+			name:       "admin lists api keys",
+			method:     http.MethodGet,
+			path:       "/api-keys",
+			user:       "alice",
+			bindings:   models.RoleBindings{{Role: models.ROLE_ADMIN}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:               "viewer reads a connection of the project",
+			method:             http.MethodGet,
+			path:               "/plugins/github/connections/1",
+			user:               "alice",
+			bindings:           viewerOfFoo,
+			connectionProjects: []string{"foo"},
+			wantStatus:         http.StatusOK,
+		},
+		{
+			name:               "viewer tests a connection of the project",
+			method:             http.MethodGet,
+			path:               "/plugins/github/connections/1/test",
+			user:               "alice",
+			bindings:           viewerOfFoo,
+			connectionProjects: []string{"foo"},
+			wantStatus:         http.StatusForbidden,
+			wantMessage:        "role project-maintainer is required on project foo",
+		},
+		{
+			name:               "maintainer tests a connection of the project",
+			method:             http.MethodGet,
+			path:               "/plugins/github/connections/1/test",
+			user:               "alice",
+			bindings:           maintainerOfFoo,
+			connectionProjects: []string{"foo"},
+			wantStatus:         http.StatusOK,
+		},
+		{
+			name:   "maintainer of every project modifies a shared connection",
+			method: http.MethodPatch,
+			path:   "/plugins/github/connections/1",
+			user:   "alice",
+			bindings: models.RoleBindings{
+				{Role: models.ROLE_PROJECT_MAINTAINER, ProjectName: "foo"},
+				{Role: models.ROLE_PROJECT_MAINTAINER, ProjectName: "bar"},
+			},
+			connectionProjects: []string{"foo", "bar"},
+			wantStatus:         http.StatusForbidden,
+			wantMessage:        "role admin is required on project foo,bar",
+		},
+		{
+			name:        "unbound user lists projects",
+			method:      http.MethodGet,
+			path:        "/projects",
+			user:        "alice",
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "role viewer is required",
+		},
+		{
+			name:   "viewer lists projects",
+			method: http.MethodGet,
+			path:   "/projects",
+			user:   "alice",
+			bindings: models.RoleBindings{
+				{Role: models.ROLE_VIEWER, ProjectName: "foo"},
+				{Role: models.ROLE_PROJECT_MAINTAINER, ProjectName: "bar"},
+			},
+			wantStatus: http.StatusOK,
+			wantScope:  []string{"foo", "bar"},
+		},
+		{
+			name:       "global viewer lists projects",
+			method:     http.MethodGet,
+			path:       "/projects",
+			user:       "alice",
+			bindings:   models.RoleBindings{{Role: models.ROLE_VIEWER}},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bindings, connectionProjects, auditLogs, scope = tt.bindings, tt.connectionProjects, nil, nil
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-User", tt.user)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantScope, scope)
+			if tt.wantMessage == "" {
+				assert.Empty(t, auditLogs)
+				return
+			}
+			assert.Contains(t, w.Body.String(), tt.wantMessage)
+			if assert.Len(t, auditLogs, 1) {
+				assert.Equal(t, models.AUDIT_DENIED, auditLogs[0].Outcome)
+				assert.Equal(t, tt.wantMessage, auditLogs[0].Message)
+			}
+		})
+	}
+}
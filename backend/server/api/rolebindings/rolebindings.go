@@ -0,0 +1,111 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolebindings
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaginatedRoleBindings struct {
+	RoleBindings []*models.RoleBinding `json:"roleBindings"`
+	Count        int64                 `json:"count"`
+}
+
+// @Summary Get list of role bindings
+// @Description GET /role-bindings?subjectType=user&subject=xxx&projectName=xxx&page=1&pageSize=10
+// @Tags framework/role-bindings
+// @Param subjectType query string false "user or apikey"
+// @Param subject query string false "query"
+// @Param projectName query string false "query"
+// @Param page query int false "query"
+// @Param pageSize query int false "query"
+// @Success 200  {object} PaginatedRoleBindings
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /role-bindings [get]
+func Index(c *gin.Context) {
+	var query services.RoleBindingQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	bindings, count, err := services.GetRoleBindings(&query)
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting role bindings"))
+		return
+	}
+	shared.ApiOutputSuccess(c, PaginatedRoleBindings{
+		RoleBindings: bindings,
+		Count:        count,
+	}, http.StatusOK)
+}
+
+// @Summary Create a role binding
+// @Description Grant the viewer, project-maintainer or admin role to a user or an api key, on a single project or on all projects when projectName is empty
+// @Tags framework/role-bindings
+// @Accept application/json
+// @Param binding body models.RoleBinding true "json"
+// @Success 200  {object} models.RoleBinding
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /role-bindings [post]
+func Post(c *gin.Context) {
+	binding := &models.RoleBinding{}
+	err := c.ShouldBind(binding)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	user, _ := shared.GetUser(c)
+	binding, err = services.CreateRoleBinding(binding, user)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating role binding"))
+		return
+	}
+	shared.ApiOutputSuccess(c, binding, http.StatusCreated)
+}
+
+// @Summary Delete a role binding
+// @Description Delete a role binding
+// @Tags framework/role-bindings
+// @Param roleBindingId path int true "roleBindingId"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /role-bindings/{roleBindingId} [delete]
+func Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("roleBindingId"), 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad roleBindingId format supplied"))
+		return
+	}
+	err = services.DeleteRoleBinding(id)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting role binding"))
+		return
+	}
+	shared.ApiOutputSuccess(c, nil, http.StatusOK)
+}
@@ -26,6 +26,10 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/impls/logruslog"
 	"github.com/apache/incubator-devlake/server/api/apikeys"
+	"github.com/apache/incubator-devlake/server/api/auditlogs"
+	"github.com/apache/incubator-devlake/server/api/encryptionkeys"
+	"github.com/apache/incubator-devlake/server/api/notificationchannels"
+	"github.com/apache/incubator-devlake/server/api/rolebindings"
 	"github.com/apache/incubator-devlake/server/api/store"
 
 	"github.com/apache/incubator-devlake/core/plugin"
@@ -35,6 +39,7 @@ import (
 	"github.com/apache/incubator-devlake/server/api/plugininfo"
 	"github.com/apache/incubator-devlake/server/api/project"
 	"github.com/apache/incubator-devlake/server/api/push"
+	"github.com/apache/incubator-devlake/server/api/rawdata"
 	"github.com/apache/incubator-devlake/server/api/shared"
 	"github.com/apache/incubator-devlake/server/api/task"
 	"github.com/apache/incubator-devlake/server/services"
@@ -51,6 +56,9 @@ func RegisterRouter(r *gin.Engine, basicRes context.BasicRes) {
 	r.GET("/pipelines/:pipelineId/subtasks", task.GetSubtaskByPipeline)
 	r.POST("/pipelines/:pipelineId/rerun", pipelines.PostRerun)
 	r.GET("/pipelines/:pipelineId/logging.tar.gz", pipelines.DownloadLogs)
+	r.GET("/pipelines/:pipelineId/logs", pipelines.GetLogs)
+	r.GET("/pipelines/:pipelineId/events", pipelines.GetEvents)
+	r.GET("/pipelines/:pipelineId/reprocess-report", pipelines.GetReprocessReport)
 
 	r.GET("/blueprints", blueprints.Index)
 	r.POST("/blueprints", blueprints.Post)
@@ -74,6 +82,7 @@ func RegisterRouter(r *gin.Engine, basicRes context.BasicRes) {
 	r.GET("/projects/:projectName/check", project.GetProjectCheck)
 	r.PATCH("/projects/:projectName", project.PatchProject)
 	r.DELETE("/projects/:projectName", project.DeleteProject)
+	r.POST("/projects/:projectName/reprocess", project.PostReprocess)
 	r.POST("/projects", project.PostProject)
 	r.GET("/projects", project.GetProjects)
 	// on board api
@@ -86,6 +95,33 @@ func RegisterRouter(r *gin.Engine, basicRes context.BasicRes) {
 	r.PUT("/api-keys/:apiKeyId", apikeys.PutApiKey)
 	r.DELETE("/api-keys/:apiKeyId", apikeys.DeleteApiKey)
 
+	// notification api
+	r.GET("/notification-channels", notificationchannels.Index)
+	r.POST("/notification-channels", notificationchannels.Post)
+	r.GET("/notification-channels/:channelId", notificationchannels.Get)
+	r.PATCH("/notification-channels/:channelId", notificationchannels.Patch)
+	r.DELETE("/notification-channels/:channelId", notificationchannels.Delete)
+	r.GET("/notifications", notificationchannels.GetNotifications)
+
+	// role bindings api
+	r.GET("/role-bindings", rolebindings.Index)
+	r.POST("/role-bindings", rolebindings.Post)
+	r.DELETE("/role-bindings/:roleBindingId", rolebindings.Delete)
+	r.GET("/audit-logs", auditlogs.Index)
+
+	// encryption key rotation api
+	r.GET("/encryption-keys/rotations", encryptionkeys.GetRotations)
+	r.POST("/encryption-keys/rotations", encryptionkeys.PostRotation)
+
+	// raw data retention api
+	r.GET("/raw-data/retention-policies", rawdata.GetPolicies)
+	r.POST("/raw-data/retention-policies", rawdata.PostPolicy)
+	r.PATCH("/raw-data/retention-policies/:policyId", rawdata.PatchPolicy)
+	r.DELETE("/raw-data/retention-policies/:policyId", rawdata.DeletePolicy)
+	r.POST("/raw-data/retention", rawdata.PostRetention)
+	r.GET("/raw-data/archives", rawdata.GetArchives)
+	r.POST("/raw-data/archives/import", rawdata.PostArchiveImport)
+
 	// mount all api resources for all plugins
 	resources, err := services.GetPluginsApiResources()
 	if err != nil {
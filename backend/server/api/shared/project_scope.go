@@ -0,0 +1,42 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+type projectScopeContextKey struct{}
+
+// WithProjectScope restricts the listings of the request to the projects, it is kept in the request context
+// since `gin.Context.Keys` are reset when the `/rest` request is redirected
+func WithProjectScope(c *gin.Context, projectNames []string) {
+	if projectNames == nil {
+		projectNames = make([]string, 0)
+	}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), projectScopeContextKey{}, projectNames))
+}
+
+// GetProjectScope returns the projects the listings of the request are restricted to, nil when they are not
+// restricted
+func GetProjectScope(c *gin.Context) []string {
+	projectNames, _ := c.Request.Context().Value(projectScopeContextKey{}).([]string)
+	return projectNames
+}
@@ -18,10 +18,18 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/runner"
 	_ "github.com/apache/incubator-devlake/core/version"
 	"github.com/apache/incubator-devlake/server/api"
+	"github.com/apache/incubator-devlake/server/services"
 )
 
 func main() {
@@ -30,5 +38,27 @@ func main() {
 	if encryptionSecret == "" {
 		panic("ENCRYPTION_SECRET must be set in environment variable or .env file")
 	}
+	if len(os.Args) > 1 && os.Args[1] == "rotate-encryption-key" {
+		rotateEncryptionKey(os.Args[2:])
+		return
+	}
 	api.CreateAndRunApiServer()
 }
+
+// rotateEncryptionKey re-encrypts all encrypted columns with ENCRYPTION_KEY_ID without starting the api server,
+// the database must have been migrated by the server already: `lake rotate-encryption-key [-dry-run]`
+func rotateEncryptionKey(args []string) {
+	flags := flag.NewFlagSet("rotate-encryption-key", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report the values to be rotated without writing them")
+	_ = flags.Parse(args)
+	services.InitResources()
+	errors.Must(runner.LoadPlugins(services.GetBasicRes()))
+	rotation, err := services.RotateEncryptionKey(*dryRun)
+	if rotation != nil {
+		output, _ := json.MarshalIndent(rotation, "", "  ")
+		fmt.Println(string(output))
+	}
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// AuditLogQuery is a query for GetAuditLogs
+type AuditLogQuery struct {
+	Pagination
+	Actor       string     `form:"actor"`
+	ApiKeyId    uint64     `form:"apiKeyId"`
+	Method      string     `form:"method"`
+	TargetType  string     `form:"targetType"`
+	TargetId    string     `form:"targetId"`
+	ProjectName string     `form:"projectName"`
+	Outcome     string     `form:"outcome"`
+	StartTime   *time.Time `form:"startTime"`
+	EndTime     *time.Time `form:"endTime"`
+}
+
+// GetAuditLogs returns a paginated list of AuditLogs based on `query`
+func GetAuditLogs(query *AuditLogQuery) ([]*models.AuditLog, int64, errors.Error) {
+	clauses := []dal.Clause{dal.From(&models.AuditLog{})}
+	if query.Actor != "" {
+		clauses = append(clauses, dal.Where("actor = ? OR actor_email = ?", query.Actor, query.Actor))
+	}
+	if query.ApiKeyId != 0 {
+		clauses = append(clauses, dal.Where("api_key_id = ?", query.ApiKeyId))
+	}
+	if query.Method != "" {
+		clauses = append(clauses, dal.Where("method = ?", strings.ToUpper(query.Method)))
+	}
+	if query.TargetType != "" {
+		clauses = append(clauses, dal.Where("target_type = ?", query.TargetType))
+	}
+	if query.TargetId != "" {
+		clauses = append(clauses, dal.Where("target_id = ?", query.TargetId))
+	}
+	if query.ProjectName != "" {
+		clauses = append(clauses, dal.Where("project_name = ?", query.ProjectName))
+	}
+	if query.Outcome != "" {
+		clauses = append(clauses, dal.Where("outcome = ?", query.Outcome))
+	}
+	if query.StartTime != nil {
+		clauses = append(clauses, dal.Where("created_at >= ?", query.StartTime))
+	}
+	if query.EndTime != nil {
+		clauses = append(clauses, dal.Where("created_at < ?", query.EndTime))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of audit logs")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	auditLogs := make([]*models.AuditLog, 0)
+	err = db.All(&auditLogs, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB audit logs")
+	}
+	return auditLogs, count, nil
+}
+
+// CreateAuditLog saves the AuditLog, failures are logged but not returned since they must not break the api call
+func CreateAuditLog(auditLog *models.AuditLog) {
+	if err := db.Create(auditLog); err != nil {
+		logger.Error(err, "failed to save audit log of %s %s", auditLog.Method, auditLog.Endpoint)
+	}
+}
+
+// AuditTarget is the entity an api call operates on
+type AuditTarget struct {
+	// Type is the collection in the endpoint, e.g. `blueprints`, `projects` or `github/connections` for plugins
+	Type string
+	// Id is the identifier in the endpoint following the collection, empty for creation
+	Id string
+	// PluginName and ResourcePath locate the plugin api resource returning the entity, e.g. `connections/:connectionId`
+	PluginName   string
+	ResourcePath string
+	Params       map[string]string
+}
+
+// Snapshot returns the current state of the target with secrets redacted, nil if the target doesn't exist or
+// is not a known entity
+func (target *AuditTarget) Snapshot() map[string]interface{} {
+	if target.Id == "" {
+		return nil
+	}
+	entity, err := target.getEntity()
+	if err != nil || entity == nil {
+		return nil
+	}
+	return RedactAuditSnapshot(entity)
+}
+
+func (target *AuditTarget) getEntity() (interface{}, errors.Error) {
+	if target.PluginName != "" {
+		pluginMeta, err := plugin.GetPlugin(target.PluginName)
+		if err != nil {
+			return nil, err
+		}
+		pluginApi, ok := pluginMeta.(plugin.PluginApi)
+		if !ok {
+			return nil, nil
+		}
+		handler := pluginApi.ApiResources()[target.ResourcePath]["GET"]
+		if handler == nil {
+			return nil, nil
+		}
+		params := map[string]string{"plugin": target.PluginName}
+		for k, v := range target.Params {
+			params[k] = v
+		}
+		output, err := handler(&plugin.ApiResourceInput{Params: params})
+		if err != nil || output == nil {
+			return nil, err
+		}
+		return output.Body, nil
+	}
+	if target.Type == "projects" {
+		return GetProject(target.Id)
+	}
+	if target.Type == "store" {
+		return GetStore(target.Id)
+	}
+	id, parseErr := strconv.ParseUint(target.Id, 10, 64)
+	if parseErr != nil {
+		return nil, nil
+	}
+	switch target.Type {
+	case "blueprints":
+		return GetBlueprint(id, true)
+	case "notification-channels":
+		return GetNotificationChannel(id)
+	case "api-keys":
+		apiKey := &models.ApiKey{}
+		if err := db.First(apiKey, dal.Where("id = ?", id)); err != nil {
+			return nil, err
+		}
+		apiKey.RemoveHashedApiKey()
+		return apiKey, nil
+	case "role-bindings":
+		binding := &models.RoleBinding{}
+		if err := db.First(binding, dal.Where("id = ?", id)); err != nil {
+			return nil, err
+		}
+		return binding, nil
+	case "retention-policies":
+		return getRawDataRetentionPolicy(id)
+	}
+	return nil, nil
+}
+
+var auditSecretKeyPattern = regexp.MustCompile(`(?i)(token|password|secret|apikey|privatekey|credential|passphrase)`)
+
+// RedactAuditSnapshot converts the entity to a map and masks the values of the fields looking like secrets
+func RedactAuditSnapshot(entity interface{}) map[string]interface{} {
+	var snapshot map[string]interface{}
+	blob, err := json.Marshal(entity)
+	if err != nil || json.Unmarshal(blob, &snapshot) != nil {
+		return nil
+	}
+	return redactAuditValue(snapshot).(map[string]interface{})
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			normalizedKey := strings.NewReplacer("_", "", "-", "").Replace(key)
+			if s, ok := child.(string); ok && s != "" && auditSecretKeyPattern.MatchString(normalizedKey) {
+				v[key] = "******"
+			} else {
+				v[key] = redactAuditValue(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactAuditValue(child)
+		}
+	}
+	return value
+}
+
+// DiffAuditSnapshots keeps only the top level fields changed between `before` and `after`, fields bookkept
+// by the database like `updatedAt` are ignored
+func DiffAuditSnapshots(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	if before == nil || after == nil {
+		return before, after
+	}
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+	for key, value := range before {
+		if key != "updatedAt" && !reflect.DeepEqual(value, after[key]) {
+			changedBefore[key] = value
+		}
+	}
+	for key, value := range after {
+		if key != "updatedAt" && !reflect.DeepEqual(value, before[key]) {
+			changedAfter[key] = value
+		}
+	}
+	if len(changedBefore) == 0 && len(changedAfter) == 0 {
+		return nil, nil
+	}
+	return changedBefore, changedAfter
+}
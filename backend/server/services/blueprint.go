@@ -46,6 +46,8 @@ type BlueprintQuery struct {
 	Label    string `form:"label"`
 	// isManual must be omitted or `null` for type to take effect
 	Type string `form:"type" enums:"ALL,MANUAL,DAILY,WEEKLY,MONTHLY,CUSTOM" validate:"oneof=ALL MANUAL DAILY WEEKLY MONTHLY CUSTOM"`
+	// ProjectNames restricts the results to the projects, nil for all projects
+	ProjectNames []string `form:"-"`
 }
 
 type BlueprintJob struct {
@@ -75,12 +77,13 @@ func CreateBlueprint(blueprint *models.Blueprint) errors.Error {
 // GetBlueprints returns a paginated list of Blueprints based on `query`
 func GetBlueprints(query *BlueprintQuery, shouldSanitize bool) ([]*models.Blueprint, int64, errors.Error) {
 	blueprints, count, err := bpManager.GetDbBlueprints(&services.GetBlueprintQuery{
-		Enable:      query.Enable,
-		IsManual:    query.IsManual,
-		Label:       query.Label,
-		SkipRecords: query.GetSkip(),
-		PageSize:    query.GetPageSize(),
-		Type:        query.Type,
+		Enable:       query.Enable,
+		IsManual:     query.IsManual,
+		Label:        query.Label,
+		SkipRecords:  query.GetSkip(),
+		PageSize:     query.GetPageSize(),
+		Type:         query.Type,
+		ProjectNames: query.ProjectNames,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -248,6 +251,10 @@ func DeleteBlueprint(id uint64) errors.Error {
 var blueprintReloadLock sync.Mutex
 var bpCronIdMap map[uint64]cron.EntryID
 
+// scheduleBlueprints would be false for the workers that only consume pipelines, otherwise
+// every worker would create a pipeline for the same blueprint
+var scheduleBlueprints = true
+
 // ReloadBlueprints reloades cronjobs based on blueprints
 func ReloadBlueprints() (err errors.Error) {
 	enable := true
@@ -287,7 +294,7 @@ func reloadBlueprint(blueprint *models.Blueprint) errors.Error {
 		delete(bpCronIdMap, blueprint.ID)
 		logger.Info("removed blueprint %d from cronjobs, cron id: %v", blueprint.ID, cronId)
 	}
-	if blueprint.Enable && !blueprint.IsManual {
+	if blueprint.Enable && !blueprint.IsManual && scheduleBlueprints {
 		if cronId, err := cronManager.AddJob(blueprint.CronConfig, &BlueprintJob{blueprint}); err != nil {
 			blueprintLog.Error(err, failToCreateCronJob)
 			return errors.Default.Wrap(err, "created cron job failed")
@@ -360,7 +367,7 @@ func MakePlanForBlueprint(blueprint *models.Blueprint, syncPolicy *models.SyncPo
 		}
 	}
 	skipCollectors := false
-	if syncPolicy != nil && syncPolicy.SkipCollectors {
+	if syncPolicy != nil && (syncPolicy.SkipCollectors || syncPolicy.Reprocess) {
 		skipCollectors = true
 	}
 	plan, err := GeneratePlanJsonV200(blueprint.ProjectName, blueprint.Connections, metrics, skipCollectors)
@@ -412,6 +419,7 @@ func TriggerBlueprint(id uint64, syncPolicy *models.SyncPolicy, shouldSanitize b
 	}
 	blueprint.SkipCollectors = syncPolicy.SkipCollectors
 	blueprint.FullSync = syncPolicy.FullSync
+	blueprint.Reprocess = syncPolicy.Reprocess
 	pipeline, err := createPipelineByBlueprint(blueprint, syncPolicy)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,287 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/runner"
+	"github.com/apache/incubator-devlake/impls/dalgorm"
+)
+
+const encryptionKeyRotationBatchSize = 500
+
+var encryptionKeyRotationLock sync.Mutex
+
+// EncryptionKeyRotationQuery is a query for GetEncryptionKeyRotations
+type EncryptionKeyRotationQuery struct {
+	Pagination
+	KeyId string `form:"keyId"`
+}
+
+// GetEncryptionKeyRotations returns a paginated list of EncryptionKeyRotations based on `query`
+func GetEncryptionKeyRotations(query *EncryptionKeyRotationQuery) ([]*models.EncryptionKeyRotation, int64, errors.Error) {
+	clauses := []dal.Clause{dal.From(&models.EncryptionKeyRotation{})}
+	if query.KeyId != "" {
+		clauses = append(clauses, dal.Where("key_id = ?", query.KeyId))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of encryption key rotations")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	rotations := make([]*models.EncryptionKeyRotation, 0)
+	err = db.All(&rotations, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB encryption key rotations")
+	}
+	return rotations, count, nil
+}
+
+// getEncDecTables returns all tables of the framework and the loaded plugins containing `serializer:encdec` columns
+func getEncDecTables() ([]*dalgorm.EncDecTable, errors.Error) {
+	tablers := []dal.Tabler{
+		&models.Blueprint{},
+		&models.Pipeline{},
+		&models.Task{},
+		&models.NotificationChannel{},
+	}
+	pluginNames := make([]string, 0)
+	for pluginName := range plugin.AllPlugins() {
+		pluginNames = append(pluginNames, pluginName)
+	}
+	sort.Strings(pluginNames)
+	for _, pluginName := range pluginNames {
+		if pluginModel, ok := plugin.AllPlugins()[pluginName].(plugin.PluginModel); ok {
+			tablers = append(tablers, pluginModel.GetTablesInfo()...)
+		}
+	}
+	tables := make([]*dalgorm.EncDecTable, 0)
+	seen := make(map[string]bool)
+	for _, tabler := range tablers {
+		if seen[tabler.TableName()] || !db.HasTable(tabler.TableName()) {
+			continue
+		}
+		seen[tabler.TableName()] = true
+		table, err := dalgorm.GetEncDecTable(tabler)
+		if err != nil {
+			return nil, err
+		}
+		if table != nil {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// RotateEncryptionKey re-encrypts all `serializer:encdec` columns with the current key `ENCRYPTION_KEY_ID`, values
+// are decrypted by the key they were tagged with or, for untagged ones, by any key of `ENCRYPTION_PREVIOUS_SECRETS`.
+// Each table is rotated in a transaction, the last unfinished rotation of the same key would be resumed from the
+// tables not completed yet. Nothing would be written in dry-run mode, the number of values to be rotated and the
+// ones could not be decrypted are reported instead.
+func RotateEncryptionKey(dryRun bool) (*models.EncryptionKeyRotation, errors.Error) {
+	if !encryptionKeyRotationLock.TryLock() {
+		return nil, errors.Conflict.New("another encryption key rotation is running")
+	}
+	defer encryptionKeyRotationLock.Unlock()
+
+	keyRing, err := runner.NewEncryptionKeyRing(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if keyRing.CurrentKeyId() == "" {
+		return nil, errors.BadInput.New(fmt.Sprintf("%s must be set to rotate the encryption key", plugin.EncryptionKeyIdEnvStr))
+	}
+	tables, err := getEncDecTables()
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &models.EncryptionKeyRotation{}
+	if !dryRun {
+		err = db.First(
+			rotation,
+			dal.Where("key_id = ? AND dry_run = ? AND status != ?", keyRing.CurrentKeyId(), false, models.ROTATION_DONE),
+			dal.Orderby("id DESC"),
+		)
+		if err != nil && !db.IsErrorNotFound(err) {
+			return nil, errors.Default.Wrap(err, "error getting the unfinished encryption key rotation")
+		}
+	}
+	if rotation.ID != 0 {
+		logger.Info("resuming encryption key rotation %d, completed tables: %v", rotation.ID, rotation.CompletedTables)
+	} else {
+		rotation.KeyId = keyRing.CurrentKeyId()
+		rotation.DryRun = dryRun
+	}
+	rotation.Status = models.ROTATION_RUNNING
+	rotation.Message = ""
+	if err = db.CreateOrUpdate(rotation); err != nil {
+		return nil, errors.Default.Wrap(err, "error saving the encryption key rotation")
+	}
+
+	completed := make(map[string]bool)
+	for _, table := range rotation.CompletedTables {
+		completed[table] = true
+	}
+	for _, table := range tables {
+		if completed[table.Name] {
+			continue
+		}
+		report, rotateErr := rotateEncDecTable(keyRing, table, dryRun)
+		rotation.Tables = append(rotation.Tables, report)
+		if rotateErr != nil {
+			rotation.Status = models.ROTATION_FAILED
+			rotation.Message = rotateErr.Error()
+			if err = db.Update(rotation); err != nil {
+				logger.Error(err, "failed to save the encryption key rotation %d", rotation.ID)
+			}
+			return rotation, rotateErr
+		}
+		if !dryRun {
+			rotation.CompletedTables = append(rotation.CompletedTables, table.Name)
+		}
+		if err = db.Update(rotation); err != nil {
+			return nil, errors.Default.Wrap(err, "error saving the encryption key rotation")
+		}
+		logger.Info("table %s rotated: %d scanned, %d rotated", table.Name, report.Scanned, report.Rotated)
+	}
+	now := time.Now()
+	rotation.Status = models.ROTATION_DONE
+	rotation.FinishedAt = &now
+	if err = db.Update(rotation); err != nil {
+		return nil, errors.Default.Wrap(err, "error saving the encryption key rotation")
+	}
+	return rotation, nil
+}
+
+func rotateEncDecTable(keyRing *plugin.EncryptionKeyRing, table *dalgorm.EncDecTable, dryRun bool) (report *models.EncryptionKeyRotationTable, err errors.Error) {
+	report = &models.EncryptionKeyRotationTable{Table: table.Name, Columns: table.Columns}
+	tx := db
+	if !dryRun {
+		txn := db.Begin()
+		defer func() {
+			if err != nil {
+				report.Error = err.Error()
+				if rollbackErr := txn.Rollback(); rollbackErr != nil {
+					logger.Error(rollbackErr, "failed to rollback the rotation of table %s", table.Name)
+				}
+				return
+			}
+			err = txn.Commit()
+		}()
+		tx = txn
+	}
+
+	columns := append(append([]string{}, table.PrimaryKeys...), table.Columns...)
+	pkConditions := make([]string, len(table.PrimaryKeys))
+	for i, pk := range table.PrimaryKeys {
+		pkConditions[i] = pk + " = ?"
+	}
+	for offset := 0; ; offset += encryptionKeyRotationBatchSize {
+		rows, err := fetchEncDecRows(tx, table, columns, offset)
+		if err != nil {
+			return report, err
+		}
+		for _, row := range rows {
+			sets := make([]dal.DalSet, 0)
+			for i, column := range table.Columns {
+				value := row[len(table.PrimaryKeys)+i]
+				if !value.Valid || value.String == "" {
+					continue
+				}
+				report.Scanned++
+				if keyRing.IsCurrent(value.String) {
+					continue
+				}
+				decrypted, err := keyRing.Decrypt(value.String)
+				if err != nil {
+					report.Failed++
+					continue
+				}
+				report.Rotated++
+				if dryRun {
+					continue
+				}
+				encrypted, err := keyRing.Encrypt(decrypted)
+				if err != nil {
+					return report, err
+				}
+				sets = append(sets, dal.DalSet{ColumnName: column, Value: encrypted})
+			}
+			if len(sets) == 0 {
+				continue
+			}
+			pkValues := make([]interface{}, len(table.PrimaryKeys))
+			for i := range table.PrimaryKeys {
+				pkValues[i] = row[i].String
+			}
+			err = tx.UpdateColumns(table.Name, sets, dal.Where(strings.Join(pkConditions, " AND "), pkValues...))
+			if err != nil {
+				return report, errors.Default.Wrap(err, fmt.Sprintf("failed to update the encrypted columns of table %s", table.Name))
+			}
+		}
+		if len(rows) < encryptionKeyRotationBatchSize {
+			break
+		}
+	}
+	if report.Failed > 0 && !dryRun {
+		return report, errors.Default.New(fmt.Sprintf("%d values of table %s could not be decrypted by any key in the ring", report.Failed, table.Name))
+	}
+	return report, nil
+}
+
+func fetchEncDecRows(tx dal.Dal, table *dalgorm.EncDecTable, columns []string, offset int) ([][]sql.NullString, errors.Error) {
+	cursor, err := tx.Cursor(
+		dal.Select(strings.Join(columns, ", ")),
+		dal.From(table.Name),
+		dal.Orderby(strings.Join(table.PrimaryKeys, ", ")),
+		dal.Offset(offset),
+		dal.Limit(encryptionKeyRotationBatchSize),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to read table %s", table.Name))
+	}
+	defer cursor.Close()
+	rows := make([][]sql.NullString, 0)
+	for cursor.Next() {
+		row := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := cursor.Scan(dest...); err != nil {
+			return nil, errors.Convert(err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
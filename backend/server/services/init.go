@@ -89,8 +89,11 @@ func GetMigrator() plugin.Migrator {
 func Init() {
 	InitResources()
 
-	// lock the database to avoid multiple devlake instances from sharing the same one
-	lockDatabase()
+	// lock the database to avoid multiple devlake instances from sharing the same one,
+	// unless they are meant to do so as workers
+	if !cfg.GetBool("WORKER_MODE") {
+		lockDatabase()
+	}
 
 	// now, load the plugins
 	errors.Must(runner.LoadPlugins(basicRes))
@@ -18,31 +18,52 @@ limitations under the License.
 package services
 
 import (
+	"bytes"
+	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/smtp"
 	"strings"
 	"time"
 
+	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/utils"
 )
 
-// NotificationService FIXME ...
+const notificationRetryInterval = 30 * time.Second
+
+const notificationTimeout = 30 * time.Second
+
+var notificationHttpClient = &http.Client{Timeout: notificationTimeout}
+
+// NotificationService delivers the notification events to the subscribed channels and records every delivery
+// into `_devlake_notifications`, failed deliveries would be retried with exponential backoff
 type NotificationService struct {
-	EndPoint string
-	Secret   string
+	// EndPoint and Secret come from `NOTIFICATION_ENDPOINT` and `NOTIFICATION_SECRET`, which is treated as a
+	// webhook channel subscribing PipelineStatusChanged of all pipelines
+	EndPoint    string
+	Secret      string
+	MaxAttempts int
 }
 
 // NewNotificationService FIXME ...
-func NewNotificationService(endpoint, secret string) *NotificationService {
+func NewNotificationService(endpoint, secret string, maxAttempts int) *NotificationService {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
 	return &NotificationService{
-		EndPoint: endpoint,
-		Secret:   secret,
+		EndPoint:    endpoint,
+		Secret:      secret,
+		MaxAttempts: maxAttempts,
 	}
 }
 
@@ -56,50 +77,393 @@ type PipelineNotification struct {
 	Status     string
 }
 
+// NotificationEvent is something happened to a pipeline that channels might subscribe to
+type NotificationEvent struct {
+	Type        models.NotificationType `json:"type"`
+	PipelineId  uint64                  `json:"pipelineId"`
+	BlueprintId uint64                  `json:"blueprintId"`
+	ProjectName string                  `json:"projectName"`
+	TaskId      uint64                  `json:"taskId,omitempty"`
+	Plugin      string                  `json:"plugin,omitempty"`
+	Subtask     string                  `json:"subtask,omitempty"`
+	Status      string                  `json:"status,omitempty"`
+	Message     string                  `json:"message"`
+	// Data would be sent to webhooks as the body if provided, the event itself would be sent otherwise
+	Data interface{} `json:"-"`
+}
+
+// Summary returns the human-readable text for the chat and email channels
+func (e *NotificationEvent) Summary() string {
+	summary := fmt.Sprintf("[DevLake] %s: pipeline #%d", e.Type, e.PipelineId)
+	if e.ProjectName != "" {
+		summary += fmt.Sprintf(" of project %s", e.ProjectName)
+	}
+	if e.TaskId != 0 {
+		summary += fmt.Sprintf(", task #%d (%s)", e.TaskId, e.Plugin)
+	}
+	if e.Subtask != "" {
+		summary += fmt.Sprintf(", subtask %s", e.Subtask)
+	}
+	if e.Status != "" {
+		summary += fmt.Sprintf(", status %s", e.Status)
+	}
+	if e.Message != "" {
+		summary += fmt.Sprintf("\n%s", e.Message)
+	}
+	return summary
+}
+
 // PipelineStatusChanged FIXME ...
 func (n *NotificationService) PipelineStatusChanged(params PipelineNotification) errors.Error {
-	return n.sendNotification(models.NotificationPipelineStatusChanged, params)
+	return n.Notify(&NotificationEvent{
+		Type:       models.NotificationPipelineStatusChanged,
+		PipelineId: params.PipelineID,
+		Status:     params.Status,
+		Data:       params,
+	})
 }
 
-func (n *NotificationService) sendNotification(notificationType models.NotificationType, data interface{}) errors.Error {
-	var dataJson, err = json.Marshal(data)
+// Notify sends the event to all channels subscribing it
+func (n *NotificationService) Notify(event *NotificationEvent) errors.Error {
+	if event.BlueprintId == 0 && event.PipelineId != 0 {
+		pipeline := &models.Pipeline{}
+		if err := db.First(pipeline, dal.Where("id = ?", event.PipelineId)); err != nil {
+			return err
+		}
+		event.BlueprintId = pipeline.BlueprintId
+	}
+	if event.ProjectName == "" && event.BlueprintId != 0 {
+		blueprint := &models.Blueprint{}
+		if err := db.First(blueprint, dal.Where("id = ?", event.BlueprintId)); err == nil {
+			event.ProjectName = blueprint.ProjectName
+		}
+	}
+	channels, err := n.getSubscribedChannels(event)
 	if err != nil {
-		return errors.Convert(err)
+		return err
+	}
+	var errs []error
+	for _, channel := range channels {
+		if err := n.deliver(channel, event); err != nil {
+			globalPipelineLog.Error(err, "failed to notify channel %s", channel.Name)
+			errs = append(errs, errors.Default.Wrap(err, fmt.Sprintf("failed to notify channel %s", channel.Name)))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Default.Combine(errs)
+	}
+	return nil
+}
+
+func (n *NotificationService) legacyChannel() *models.NotificationChannel {
+	if n.EndPoint == "" {
+		return nil
+	}
+	return &models.NotificationChannel{
+		Name:     "NOTIFICATION_ENDPOINT",
+		Type:     models.NotificationChannelWebhook,
+		Endpoint: n.EndPoint,
+		Secret:   n.Secret,
+		Events:   []string{string(models.NotificationPipelineStatusChanged)},
+		Enable:   true,
+	}
+}
+
+func (n *NotificationService) getSubscribedChannels(event *NotificationEvent) ([]*models.NotificationChannel, errors.Error) {
+	var channels []*models.NotificationChannel
+	if err := db.All(&channels, dal.Where("enable = ?", true)); err != nil {
+		return nil, err
+	}
+	if legacy := n.legacyChannel(); legacy != nil {
+		channels = append(channels, legacy)
+	}
+	subscribed := make([]*models.NotificationChannel, 0, len(channels))
+	for _, channel := range channels {
+		if channel.Subscribes(event.Type, event.BlueprintId, event.ProjectName) {
+			subscribed = append(subscribed, channel)
+		}
 	}
+	return subscribed, nil
+}
 
-	var notification models.Notification
-	notification.Data = string(dataJson)
-	notification.Type = notificationType
-	notification.Endpoint = n.EndPoint
+func (n *NotificationService) deliver(channel *models.NotificationChannel, event *NotificationEvent) errors.Error {
+	data := event.Data
+	if data == nil {
+		data = event
+	}
+	dataJson, err := json.Marshal(data)
+	if err != nil {
+		return errors.Convert(err)
+	}
 	nonce, err1 := utils.RandLetterBytes(16)
 	if err1 != nil {
 		return err1
 	}
-	notification.Nonce = nonce
+	notification := &models.Notification{
+		Type:      event.Type,
+		ChannelId: channel.ID,
+		Endpoint:  channel.Endpoint,
+		Nonce:     nonce,
+		Data:      string(dataJson),
+		Message:   event.Summary(),
+		Status:    models.NOTIFICATION_PENDING,
+	}
+	if err := db.Create(notification); err != nil {
+		return err
+	}
+	if channel.Type == models.NotificationChannelEmail {
+		// smtp servers may take long to respond, the email is sent in the background and retried like others
+		go func() {
+			if err := n.attempt(channel, notification); err != nil {
+				globalPipelineLog.Error(err, "failed to notify channel %s", channel.Name)
+			}
+		}()
+		return nil
+	}
+	return n.attempt(channel, notification)
+}
+
+// attempt sends the notification once and schedules the next retry if it failed
+func (n *NotificationService) attempt(channel *models.NotificationChannel, notification *models.Notification) errors.Error {
+	sender, ok := notificationSenders[channel.Type]
+	var err errors.Error
+	if !ok {
+		err = errors.BadInput.New(fmt.Sprintf("unsupported notification channel type %s", channel.Type))
+	} else {
+		err = sender.Send(channel, notification)
+	}
+	notification.Attempts++
+	if err == nil {
+		notification.Status = models.NOTIFICATION_SENT
+		notification.NextRetryAt = nil
+	} else {
+		notification.Response = err.Error()
+		if notification.Attempts >= n.MaxAttempts {
+			notification.Status = models.NOTIFICATION_FAILED
+			notification.NextRetryAt = nil
+		} else {
+			nextRetryAt := time.Now().Add(notificationBackoff(notification.Attempts))
+			notification.NextRetryAt = &nextRetryAt
+		}
+	}
+	if dbe := db.Update(notification); dbe != nil {
+		return dbe
+	}
+	return err
+}
 
-	err = db.Create(&notification)
-	if err != nil {
-		return errors.Convert(err)
+// notificationBackoff doubles the waiting time for every failed attempt: 1m, 2m, 4m ... up to 1h
+func notificationBackoff(attempts int) time.Duration {
+	backoff := time.Minute
+	for i := 1; i < attempts && backoff < time.Hour; i++ {
+		backoff *= 2
+	}
+	if backoff > time.Hour {
+		backoff = time.Hour
+	}
+	return backoff
+}
+
+// RetryNotifications resends the pending notifications periodically
+func (n *NotificationService) RetryNotifications() {
+	for range time.Tick(notificationRetryInterval) {
+		var notifications []*models.Notification
+		err := db.All(
+			&notifications,
+			dal.Where("status = ? AND next_retry_at <= ?", models.NOTIFICATION_PENDING, time.Now()),
+			dal.Orderby("id"),
+		)
+		if err != nil {
+			globalPipelineLog.Error(err, "failed to load pending notifications")
+			continue
+		}
+		for _, notification := range notifications {
+			channel := n.legacyChannel()
+			if notification.ChannelId != 0 {
+				channel = &models.NotificationChannel{}
+				if err := db.First(channel, dal.Where("id = ?", notification.ChannelId)); err != nil {
+					channel = nil
+				}
+			}
+			if channel == nil {
+				// the channel was deleted
+				notification.Status = models.NOTIFICATION_FAILED
+				notification.NextRetryAt = nil
+				if err := db.Update(notification); err != nil {
+					globalPipelineLog.Error(err, "failed to update notification #%d", notification.ID)
+				}
+				continue
+			}
+			if err := n.attempt(channel, notification); err != nil {
+				globalPipelineLog.Warn(err, "failed to resend notification #%d", notification.ID)
+			}
+		}
 	}
+}
 
-	sign := n.signature(notification.Data, fmt.Sprintf("%d-%s", notification.ID, nonce))
-	url := fmt.Sprintf("%s?nouce=%d-%s&sign=%s", n.EndPoint, notification.ID, nonce, sign)
+// notificationSender delivers notifications through a type of channel
+type notificationSender interface {
+	Send(channel *models.NotificationChannel, notification *models.Notification) errors.Error
+}
+
+var notificationSenders = map[models.NotificationChannelType]notificationSender{
+	models.NotificationChannelWebhook: &webhookSender{},
+	models.NotificationChannelSlack:   &slackSender{},
+	models.NotificationChannelFeishu:  &feishuSender{},
+	models.NotificationChannelEmail:   &emailSender{},
+}
 
-	resp, err := http.Post(url, "application/json", strings.NewReader(notification.Data))
+func postJson(url string, body []byte, notification *models.Notification) errors.Error {
+	resp, err := notificationHttpClient.Post(url, "application/json", bytes.NewReader(body))
 	if err != nil {
 		return errors.Convert(err)
 	}
-
+	defer resp.Body.Close()
 	notification.ResponseCode = resp.StatusCode
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return errors.Convert(err)
 	}
 	notification.Response = string(respBody)
-	return db.Update(notification)
+	if resp.StatusCode >= 300 {
+		return errors.HttpStatus(resp.StatusCode).New(fmt.Sprintf("unexpected status code %d: %s", resp.StatusCode, respBody))
+	}
+	return nil
+}
+
+// webhookSender posts the signed data to the endpoint
+type webhookSender struct{}
+
+func (s *webhookSender) Send(channel *models.NotificationChannel, notification *models.Notification) errors.Error {
+	nonce := fmt.Sprintf("%d-%s", notification.ID, notification.Nonce)
+	sign := signature(notification.Data, channel.Secret, nonce)
+	url := fmt.Sprintf("%s?nouce=%s&sign=%s", channel.Endpoint, nonce, sign)
+	return postJson(url, []byte(notification.Data), notification)
 }
 
-func (n *NotificationService) signature(input, nouce string) string {
-	sum := sha256.Sum256([]byte(input + n.Secret + nouce))
+func signature(input, secret, nouce string) string {
+	sum := sha256.Sum256([]byte(input + secret + nouce))
 	return hex.EncodeToString(sum[:])
 }
+
+// slackSender posts the summary to a slack compatible incoming webhook
+type slackSender struct{}
+
+func (s *slackSender) Send(channel *models.NotificationChannel, notification *models.Notification) errors.Error {
+	body, err := json.Marshal(map[string]string{"text": notification.Message})
+	if err != nil {
+		return errors.Convert(err)
+	}
+	return postJson(channel.Endpoint, body, notification)
+}
+
+// feishuSender posts the summary to a feishu custom bot, the request would be signed if the secret was set
+type feishuSender struct{}
+
+func (s *feishuSender) Send(channel *models.NotificationChannel, notification *models.Notification) errors.Error {
+	message := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": notification.Message},
+	}
+	if channel.Secret != "" {
+		timestamp := time.Now().Unix()
+		mac := hmac.New(sha256.New, []byte(fmt.Sprintf("%d\n%s", timestamp, channel.Secret)))
+		message["timestamp"] = fmt.Sprintf("%d", timestamp)
+		message["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return errors.Convert(err)
+	}
+	if err := postJson(channel.Endpoint, body, notification); err != nil {
+		return err
+	}
+	// feishu responds 200 with a non-zero code for failures
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if json.Unmarshal([]byte(notification.Response), &result) == nil && result.Code != 0 {
+		return errors.Default.New(fmt.Sprintf("feishu bot responded code %d: %s", result.Code, result.Msg))
+	}
+	return nil
+}
+
+// emailSender sends the summary to the recipients through the SMTP server configured by `SMTP_*`
+type emailSender struct{}
+
+func (s *emailSender) Send(channel *models.NotificationChannel, notification *models.Notification) errors.Error {
+	host := cfg.GetString("SMTP_HOST")
+	if host == "" {
+		return errors.BadInput.New("SMTP_HOST is not configured")
+	}
+	from := cfg.GetString("SMTP_FROM")
+	var recipients []string
+	for _, recipient := range strings.Split(channel.Endpoint, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	subject := strings.SplitN(notification.Message, "\n", 2)[0]
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, strings.Join(recipients, ", "), subject, notification.Message,
+	)
+	var auth smtp.Auth
+	if username := cfg.GetString("SMTP_USERNAME"); username != "" {
+		auth = smtp.PlainAuth("", username, cfg.GetString("SMTP_PASSWORD"), host)
+	}
+	addr := fmt.Sprintf("%s:%d", host, cfg.GetInt("SMTP_PORT"))
+	if err := sendMail(addr, host, auth, from, recipients, []byte(msg)); err != nil {
+		return errors.Convert(err)
+	}
+	return nil
+}
+
+// sendMail works like smtp.SendMail except that the connection is bounded by `notificationTimeout`, so a stalled
+// smtp server would never block the sender forever
+func sendMail(addr string, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, notificationTimeout)
+	if err != nil {
+		return err
+	}
+	if err = conn.SetDeadline(time.Now().Add(notificationTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err = client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err = client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(msg); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
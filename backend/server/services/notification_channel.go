@@ -0,0 +1,210 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/utils"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// NotificationChannelQuery is a query for GetNotificationChannels
+type NotificationChannelQuery struct {
+	Pagination
+	BlueprintId uint64 `form:"blueprintId"`
+	ProjectName string `form:"projectName"`
+	// ProjectNames restricts the results to the projects, nil for all projects
+	ProjectNames []string `form:"-"`
+}
+
+// NotificationQuery is a query for GetNotifications
+type NotificationQuery struct {
+	Pagination
+	ChannelId uint64 `form:"channelId"`
+	Type      string `form:"type"`
+	Status    string `form:"status"`
+	// ProjectNames restricts the results to the projects, nil for all projects
+	ProjectNames []string `form:"-"`
+}
+
+// notificationChannelsOfProjects matches the channels subscribing to the projects or to their blueprints
+const notificationChannelsOfProjects = "(project_name IN ? OR blueprint_id IN (SELECT id FROM _devlake_blueprints WHERE project_name IN ?))"
+
+// sanitizeNotificationChannel hides the secret from the api output
+func sanitizeNotificationChannel(channel *models.NotificationChannel) {
+	if channel.Secret != "" {
+		channel.Secret = utils.SanitizeString(channel.Secret)
+	}
+}
+
+// GetNotificationChannels returns a paginated list of NotificationChannels based on `query`
+func GetNotificationChannels(query *NotificationChannelQuery) ([]*models.NotificationChannel, int64, errors.Error) {
+	clauses := []dal.Clause{dal.From(&models.NotificationChannel{})}
+	if query.BlueprintId != 0 {
+		clauses = append(clauses, dal.Where("blueprint_id = ?", query.BlueprintId))
+	}
+	if query.ProjectName != "" {
+		clauses = append(clauses, dal.Where("project_name = ?", query.ProjectName))
+	}
+	if query.ProjectNames != nil {
+		clauses = append(clauses, dal.Where(notificationChannelsOfProjects, query.ProjectNames, query.ProjectNames))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of notification channels")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	channels := make([]*models.NotificationChannel, 0)
+	err = db.All(&channels, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB notification channels")
+	}
+	for _, channel := range channels {
+		sanitizeNotificationChannel(channel)
+	}
+	return channels, count, nil
+}
+
+func getNotificationChannel(id uint64) (*models.NotificationChannel, errors.Error) {
+	channel := &models.NotificationChannel{}
+	err := db.First(channel, dal.Where("id = ?", id))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return nil, errors.NotFound.New(fmt.Sprintf("notification channel %d not found", id))
+		}
+		return nil, errors.Default.Wrap(err, "error getting the notification channel from database")
+	}
+	return channel, nil
+}
+
+// GetNotificationChannel returns the detail of the given NotificationChannel
+func GetNotificationChannel(id uint64) (*models.NotificationChannel, errors.Error) {
+	channel, err := getNotificationChannel(id)
+	if err != nil {
+		return nil, err
+	}
+	sanitizeNotificationChannel(channel)
+	return channel, nil
+}
+
+func validateNotificationChannel(channel *models.NotificationChannel) errors.Error {
+	if err := VerifyStruct(channel); err != nil {
+		return err
+	}
+	for _, event := range channel.Events {
+		switch models.NotificationType(event) {
+		case models.NotificationPipelineStatusChanged,
+			models.NotificationPipelineFailed,
+			models.NotificationTaskFailed,
+			models.NotificationSubtaskRetried,
+			models.NotificationRateLimited:
+		default:
+			return errors.BadInput.New(fmt.Sprintf("unknown notification event %s", event))
+		}
+	}
+	return nil
+}
+
+// CreateNotificationChannel accepts a NotificationChannel instance and insert it to database
+func CreateNotificationChannel(channel *models.NotificationChannel) (*models.NotificationChannel, errors.Error) {
+	if err := validateNotificationChannel(channel); err != nil {
+		return nil, err
+	}
+	if err := db.Create(channel); err != nil {
+		return nil, errors.Default.Wrap(err, "error creating the notification channel")
+	}
+	sanitizeNotificationChannel(channel)
+	return channel, nil
+}
+
+// PatchNotificationChannel updates the NotificationChannel with the given fields, the secret would be kept if
+// the sanitized one was sent back
+func PatchNotificationChannel(id uint64, body map[string]interface{}) (*models.NotificationChannel, errors.Error) {
+	channel, err := getNotificationChannel(id)
+	if err != nil {
+		return nil, err
+	}
+	secret := channel.Secret
+	err = helper.DecodeMapStruct(body, channel, true)
+	if err != nil {
+		return nil, err
+	}
+	if channel.Secret == utils.SanitizeString(secret) {
+		channel.Secret = secret
+	}
+	channel.ID = id
+	if err := validateNotificationChannel(channel); err != nil {
+		return nil, err
+	}
+	if err := db.Update(channel); err != nil {
+		return nil, errors.Default.Wrap(err, "error updating the notification channel")
+	}
+	sanitizeNotificationChannel(channel)
+	return channel, nil
+}
+
+// DeleteNotificationChannel deletes the NotificationChannel, its delivery history would be kept
+func DeleteNotificationChannel(id uint64) errors.Error {
+	if _, err := getNotificationChannel(id); err != nil {
+		return err
+	}
+	return db.Delete(&models.NotificationChannel{}, dal.Where("id = ?", id))
+}
+
+// GetNotifications returns a paginated list of delivered Notifications based on `query`
+func GetNotifications(query *NotificationQuery) ([]*models.Notification, int64, errors.Error) {
+	clauses := []dal.Clause{dal.From(&models.Notification{})}
+	if query.ChannelId != 0 {
+		clauses = append(clauses, dal.Where("channel_id = ?", query.ChannelId))
+	}
+	if query.Type != "" {
+		clauses = append(clauses, dal.Where("type = ?", query.Type))
+	}
+	if query.Status != "" {
+		clauses = append(clauses, dal.Where("status = ?", query.Status))
+	}
+	if query.ProjectNames != nil {
+		clauses = append(clauses, dal.Where(
+			"channel_id IN (SELECT id FROM _devlake_notification_channels WHERE "+notificationChannelsOfProjects+")",
+			query.ProjectNames, query.ProjectNames,
+		))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of notifications")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	notifications := make([]*models.Notification, 0)
+	err = db.All(&notifications, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB notifications")
+	}
+	return notifications, count, nil
+}
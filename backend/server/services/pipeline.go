@@ -32,10 +32,14 @@ import (
 
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/metrics"
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/runner"
+	"github.com/apache/incubator-devlake/core/tracing"
 	"github.com/apache/incubator-devlake/core/utils"
 	"github.com/apache/incubator-devlake/helpers/dbhelper"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/apache/incubator-devlake/impls/logruslog"
 	"github.com/google/uuid"
 	"golang.org/x/sync/semaphore"
@@ -75,6 +79,8 @@ type PipelineQuery struct {
 	Pending     int    `form:"pending"`
 	BlueprintId uint64 `uri:"blueprintId" form:"blueprint_id"`
 	Label       string `form:"label"`
+	// ProjectNames restricts the results to the projects, nil for all projects
+	ProjectNames []string `form:"-"`
 }
 
 func pipelineServiceInit() {
@@ -82,14 +88,28 @@ func pipelineServiceInit() {
 	plugin.InitPlugins(basicRes)
 
 	// notification
-	var notificationEndpoint = cfg.GetString("NOTIFICATION_ENDPOINT")
+	var notificationEndpoint = strings.TrimSpace(cfg.GetString("NOTIFICATION_ENDPOINT"))
 	var notificationSecret = cfg.GetString("NOTIFICATION_SECRET")
-	if strings.TrimSpace(notificationEndpoint) != "" {
-		notificationService = NewNotificationService(notificationEndpoint, notificationSecret)
-	}
-
-	// standalone mode: reset pipeline status
-	if cfg.GetBool("RESUME_PIPELINES") {
+	notificationService = NewNotificationService(notificationEndpoint, notificationSecret, cfg.GetInt("NOTIFICATION_MAX_ATTEMPTS"))
+	helper.SetRateLimitListener(notifyRateLimited)
+	subtaskRetriedThreshold = cfg.GetInt("NOTIFICATION_SUBTASK_RETRIED_THRESHOLD")
+	runner.SetSubtaskRetryListener(notifySubtaskRetried)
+	helper.SetRawDataListener(runner.RecordReprocessedRawData)
+	runner.SetTaskStatusListener(publishTaskStatus)
+	runner.SetSubtaskStatusListener(publishSubtaskStatus)
+	metrics.MustRegister(pipelineCollector{})
+	// tracing
+	if _, err := tracing.Init(cfg); err != nil {
+		globalPipelineLog.Error(err, "failed to initialize tracing, spans would not be exported")
+	}
+
+	workerMode = cfg.GetBool("WORKER_MODE")
+	if workerMode {
+		// worker mode: interrupted pipelines are resumed once their leases expired
+		initWorker()
+		scheduleBlueprints = cfg.GetBool("WORKER_SCHEDULE_BLUEPRINTS")
+	} else if cfg.GetBool("RESUME_PIPELINES") {
+		// standalone mode: reset pipeline status
 		markInterruptedPipelineAs(models.TASK_RESUME)
 	} else {
 		markInterruptedPipelineAs(models.TASK_FAILED)
@@ -98,6 +118,14 @@ func pipelineServiceInit() {
 	// load cronjobs for blueprints
 	errors.Must(ReloadBlueprints())
 
+	// only one of the workers is supposed to resend the failed notifications and prune the raw tables
+	if scheduleBlueprints {
+		go notificationService.RetryNotifications()
+		scheduleRawDataRetention()
+	}
+	// the logs are kept on the local disk of every worker
+	scheduleLogRetention()
+
 	var pipelineMaxParallel = cfg.GetInt64("PIPELINE_MAX_PARALLEL")
 	if pipelineMaxParallel < 0 {
 		panic(errors.BadInput.New(`PIPELINE_MAX_PARALLEL should be a positive integer`))
@@ -115,6 +143,8 @@ func markInterruptedPipelineAs(status string) {
 		&models.Pipeline{},
 		[]dal.DalSet{
 			{ColumnName: "status", Value: status},
+			{ColumnName: "worker_id", Value: ""},
+			{ColumnName: "lease_expires_at", Value: nil},
 		},
 		dal.Where("status = ?", models.TASK_RUNNING),
 	))
@@ -249,15 +279,23 @@ func dequeuePipeline(runningParallelLabels []string) (pipeline *models.Pipeline,
 	txHelper := dbhelper.NewTxHelper(basicRes, &err)
 	defer txHelper.End()
 	tx := txHelper.Begin()
-	// mysql read lock, not sure if it works for postgresql
+	// the write lock is taken upfront, upgrading a read lock would release it on mysql and deadlock on postgresql
 	errors.Must(tx.LockTables(dal.LockTables{
-		{Table: "_devlake_pipelines", Exclusive: false},
+		{Table: "_devlake_pipelines", Exclusive: true},
 		{Table: "_devlake_pipeline_labels", Exclusive: false},
 	}))
+	// pipelines running on other workers have to be taken into account as well
+	if workerMode {
+		runningParallelLabels, err = getClusterParallelLabels(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// prepare query to find an appropriate pipeline to execute
+	queued := []string{models.TASK_CREATED, models.TASK_RERUN, models.TASK_RESUME}
 	pipeline = &models.Pipeline{}
 	err = tx.First(pipeline,
-		dal.Where("status IN ?", []string{models.TASK_CREATED, models.TASK_RERUN, models.TASK_RESUME}),
+		dal.Where("status IN ?", queued),
 		dal.Join(
 			`left join _devlake_pipeline_labels ON
 				_devlake_pipeline_labels.pipeline_id = _devlake_pipelines.id AND
@@ -272,22 +310,32 @@ func dequeuePipeline(runningParallelLabels []string) (pipeline *models.Pipeline,
 		dal.Limit(1),
 	)
 	if err == nil {
-		// mark the pipeline running, now we want a write lock
+		// mark the pipeline running
 		if pipeline.BeganAt == nil {
 			now := time.Now()
 			pipeline.BeganAt = &now
 			globalPipelineLog.Info("resumed pipeline #%d", pipeline.ID)
 		}
-		errors.Must(tx.LockTables(dal.LockTables{{Table: "_devlake_pipelines", Exclusive: true}}))
-		err = tx.UpdateColumns(&models.Pipeline{}, []dal.DalSet{
+		sets := []dal.DalSet{
 			{ColumnName: "status", Value: models.TASK_RUNNING},
 			{ColumnName: "message", Value: ""},
 			{ColumnName: "began_at", Value: pipeline.BeganAt},
-		}, dal.Where("id = ?", pipeline.ID))
+		}
+		if workerMode {
+			sets = append(sets, newPipelineLease()...)
+		}
+		// the pipeline is claimed only if it is still queued and not owned by any worker
+		claimed, err := tx.UpdateColumnsAffected(&models.Pipeline{}, sets, dal.Where(
+			"id = ? AND status IN ? AND (worker_id IS NULL OR worker_id = '')", pipeline.ID, queued,
+		))
 		if err != nil {
 			panic(err)
 		}
-		return
+		if claimed == 0 {
+			globalPipelineLog.Info("pipeline #%d was claimed by another worker", pipeline.ID)
+			return nil, nil
+		}
+		return pipeline, nil
 	}
 	if tx.IsErrorNotFound(err) {
 		pipeline = nil
@@ -362,6 +410,7 @@ func NotifyExternal(pipelineId uint64) errors.Error {
 	if err != nil {
 		return err
 	}
+	var errs []error
 	err = notificationService.PipelineStatusChanged(PipelineNotification{
 		PipelineID: pipeline.ID,
 		CreatedAt:  pipeline.CreatedAt,
@@ -371,12 +420,52 @@ func NotifyExternal(pipelineId uint64) errors.Error {
 		Status:     pipeline.Status,
 	})
 	if err != nil {
+		errs = append(errs, err)
+	}
+	// the failure is notified even if some channels failed to receive the status change
+	if pipeline.Status == models.TASK_FAILED {
+		err = notificationService.Notify(&NotificationEvent{
+			Type:        models.NotificationPipelineFailed,
+			PipelineId:  pipeline.ID,
+			BlueprintId: pipeline.BlueprintId,
+			Status:      pipeline.Status,
+			Message:     pipeline.Message,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		err = errors.Default.Combine(errs)
 		globalPipelineLog.Error(err, "failed to send notification: %v", err)
 		return err
 	}
 	return nil
 }
 
+// notifyTaskFailed sends TaskFailed notification if the task failed
+func notifyTaskFailed(taskId uint64) {
+	if notificationService == nil {
+		return
+	}
+	task, err := GetTask(taskId)
+	if err != nil || task.Status != models.TASK_FAILED {
+		return
+	}
+	err = notificationService.Notify(&NotificationEvent{
+		Type:       models.NotificationTaskFailed,
+		PipelineId: task.PipelineId,
+		TaskId:     task.ID,
+		Plugin:     task.Plugin,
+		Subtask:    task.FailedSubTask,
+		Status:     task.Status,
+		Message:    task.Message,
+	})
+	if err != nil {
+		globalPipelineLog.Error(err, "failed to send notification for task #%d", taskId)
+	}
+}
+
 // CancelPipeline FIXME ...
 func CancelPipeline(pipelineId uint64) errors.Error {
 	// prevent RunPipelineInQueue from consuming pending pipelines
@@ -0,0 +1,216 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// the types of the events of a running pipeline
+const (
+	PipelineEventPipelineStatus = "pipeline_status"
+	PipelineEventTaskStatus     = "task_status"
+	PipelineEventSubtaskStatus  = "subtask_status"
+	PipelineEventProgress       = "progress"
+	pipelineEventBufferSize     = 256
+	pipelineProgressEventMinGap = 500 * time.Millisecond
+)
+
+// finishedPipelineStatus are the statuses a pipeline ends with
+var finishedPipelineStatus = map[string]bool{
+	models.TASK_COMPLETED: true,
+	models.TASK_FAILED:    true,
+	models.TASK_CANCELLED: true,
+	models.TASK_PARTIAL:   true,
+}
+
+// PipelineEvent is a progress update or a status transition of a pipeline, its tasks or their subtasks
+type PipelineEvent struct {
+	Type       string                     `json:"type"`
+	PipelineId uint64                     `json:"pipelineId"`
+	TaskId     uint64                     `json:"taskId,omitempty"`
+	Plugin     string                     `json:"plugin,omitempty"`
+	Subtask    string                     `json:"subtask,omitempty"`
+	Status     string                     `json:"status,omitempty"`
+	Message    string                     `json:"message,omitempty"`
+	Progress   *models.TaskProgressDetail `json:"progress,omitempty"`
+	Time       time.Time                  `json:"time"`
+}
+
+// IsFinished tells whether the event is the last one of the pipeline
+func (e *PipelineEvent) IsFinished() bool {
+	return e.Type == PipelineEventPipelineStatus && finishedPipelineStatus[e.Status]
+}
+
+// pipelineEventHub delivers the events of the pipelines run by this instance to their subscribers, the events are
+// dropped for the subscribers which are too slow to keep up, except the last one of the pipeline after which the
+// channels of the subscribers are closed
+type pipelineEventHub struct {
+	mu          sync.Mutex
+	subscribers map[uint64]map[chan *PipelineEvent]bool
+}
+
+var pipelineEvents = &pipelineEventHub{subscribers: make(map[uint64]map[chan *PipelineEvent]bool)}
+
+func (h *pipelineEventHub) subscribe(pipelineId uint64) (<-chan *PipelineEvent, func()) {
+	ch := make(chan *PipelineEvent, pipelineEventBufferSize)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[pipelineId] == nil {
+		h.subscribers[pipelineId] = make(map[chan *PipelineEvent]bool)
+	}
+	h.subscribers[pipelineId][ch] = true
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers[pipelineId], ch)
+		if len(h.subscribers[pipelineId]) == 0 {
+			delete(h.subscribers, pipelineId)
+		}
+	}
+}
+
+// finish delivers the last event of the pipeline in place of the oldest ones if the channel is full, then closes it
+func finish(ch chan *PipelineEvent, event *PipelineEvent) {
+	for {
+		select {
+		case ch <- event:
+			close(ch)
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+func (h *pipelineEventHub) publish(event *PipelineEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if event.IsFinished() {
+		for ch := range h.subscribers[event.PipelineId] {
+			finish(ch, event)
+		}
+		delete(h.subscribers, event.PipelineId)
+		return
+	}
+	for ch := range h.subscribers[event.PipelineId] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *pipelineEventHub) hasSubscribers(pipelineId uint64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[pipelineId]) > 0
+}
+
+// SubscribePipelineEvents returns the events of the pipeline as they happen on this instance, the channel is closed
+// after the last event of the pipeline. The returned function has to be called to stop receiving them
+func SubscribePipelineEvents(pipelineId uint64) (<-chan *PipelineEvent, func()) {
+	return pipelineEvents.subscribe(pipelineId)
+}
+
+// IsPipelineFinished tells whether the pipeline ended already
+func IsPipelineFinished(pipeline *models.Pipeline) bool {
+	return finishedPipelineStatus[pipeline.Status]
+}
+
+func publishPipelineStatus(pipeline *models.Pipeline) {
+	pipelineEvents.publish(&PipelineEvent{
+		Type:       PipelineEventPipelineStatus,
+		PipelineId: pipeline.ID,
+		Status:     pipeline.Status,
+		Message:    pipeline.Message,
+	})
+}
+
+func publishTaskStatus(task *models.Task, status string, err errors.Error) {
+	event := &PipelineEvent{
+		Type:       PipelineEventTaskStatus,
+		PipelineId: task.PipelineId,
+		TaskId:     task.ID,
+		Plugin:     task.Plugin,
+		Status:     status,
+	}
+	if err != nil {
+		event.Message = err.Error()
+	}
+	pipelineEvents.publish(event)
+}
+
+func publishSubtaskStatus(task *models.Task, subtask string, status string, err errors.Error) {
+	event := &PipelineEvent{
+		Type:       PipelineEventSubtaskStatus,
+		PipelineId: task.PipelineId,
+		TaskId:     task.ID,
+		Plugin:     task.Plugin,
+		Subtask:    subtask,
+		Status:     status,
+	}
+	if err != nil {
+		event.Message = err.Error()
+	}
+	if data := getRunningTaskById(task.ID); data != nil {
+		runningTasks.mu.Lock()
+		progress := *data.ProgressDetail
+		runningTasks.mu.Unlock()
+		event.Progress = &progress
+	}
+	pipelineEvents.publish(event)
+}
+
+// progressPublisher publishes the progress of a task, the updates of the records of a subtask are throttled
+type progressPublisher struct {
+	task     *models.Task
+	lastSent time.Time
+}
+
+func (p *progressPublisher) publish(progress *plugin.RunningProgress, detail *models.TaskProgressDetail) {
+	if p.task == nil || !pipelineEvents.hasSubscribers(p.task.PipelineId) {
+		return
+	}
+	now := time.Now()
+	if progress.Type == plugin.SubTaskIncProgress && now.Sub(p.lastSent) < pipelineProgressEventMinGap {
+		return
+	}
+	p.lastSent = now
+	snapshot := *detail
+	pipelineEvents.publish(&PipelineEvent{
+		Type:       PipelineEventProgress,
+		PipelineId: p.task.PipelineId,
+		TaskId:     p.task.ID,
+		Plugin:     p.task.Plugin,
+		Subtask:    detail.SubTaskName,
+		Progress:   &snapshot,
+		Time:       now,
+	})
+}
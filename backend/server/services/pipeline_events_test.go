@@ -0,0 +1,91 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineEventHub(t *testing.T) {
+	hub := &pipelineEventHub{subscribers: make(map[uint64]map[chan *PipelineEvent]bool)}
+	events, unsubscribe := hub.subscribe(1)
+	others, unsubscribeOthers := hub.subscribe(2)
+	defer unsubscribeOthers()
+
+	hub.publish(&PipelineEvent{Type: PipelineEventTaskStatus, PipelineId: 1, TaskId: 3, Status: models.TASK_RUNNING})
+	event := <-events
+	assert.Equal(t, uint64(3), event.TaskId)
+	assert.False(t, event.Time.IsZero())
+	assert.False(t, event.IsFinished())
+	assert.Len(t, others, 0)
+
+	// the events are dropped rather than blocking the pipeline if the subscriber does not keep up
+	for i := 0; i < pipelineEventBufferSize+10; i++ {
+		hub.publish(&PipelineEvent{Type: PipelineEventProgress, PipelineId: 1})
+	}
+	assert.Len(t, events, pipelineEventBufferSize)
+
+	unsubscribe()
+	assert.False(t, hub.hasSubscribers(1))
+	assert.True(t, hub.hasSubscribers(2))
+	assert.True(t, (&PipelineEvent{Type: PipelineEventPipelineStatus, Status: models.TASK_PARTIAL}).IsFinished())
+	assert.False(t, (&PipelineEvent{Type: PipelineEventTaskStatus, Status: models.TASK_FAILED}).IsFinished())
+}
+
+func TestPipelineEventHubFinish(t *testing.T) {
+	hub := &pipelineEventHub{subscribers: make(map[uint64]map[chan *PipelineEvent]bool)}
+	events, unsubscribe := hub.subscribe(1)
+	defer unsubscribe()
+	for i := 0; i < pipelineEventBufferSize; i++ {
+		hub.publish(&PipelineEvent{Type: PipelineEventProgress, PipelineId: 1})
+	}
+
+	// the last event is never dropped even if the subscriber does not keep up
+	hub.publish(&PipelineEvent{Type: PipelineEventPipelineStatus, PipelineId: 1, Status: models.TASK_COMPLETED})
+	assert.False(t, hub.hasSubscribers(1))
+	var last *PipelineEvent
+	count := 0
+	for event := range events {
+		last = event
+		count++
+	}
+	assert.Equal(t, pipelineEventBufferSize, count)
+	assert.True(t, last.IsFinished())
+}
+
+func TestProgressPublisher(t *testing.T) {
+	events, unsubscribe := SubscribePipelineEvents(5)
+	defer unsubscribe()
+	publisher := &progressPublisher{task: &models.Task{PipelineId: 5, Plugin: "jira"}}
+	detail := &models.TaskProgressDetail{SubTaskName: "collectIssues", FinishedRecords: 10}
+
+	publisher.publish(&plugin.RunningProgress{Type: plugin.SubTaskIncProgress}, detail)
+	detail.FinishedRecords = 20
+	publisher.publish(&plugin.RunningProgress{Type: plugin.SubTaskIncProgress}, detail)
+	publisher.publish(&plugin.RunningProgress{Type: plugin.SetCurrentSubTask}, detail)
+
+	assert.Len(t, events, 2)
+	first := <-events
+	assert.Equal(t, "collectIssues", first.Subtask)
+	assert.Equal(t, 10, first.Progress.FinishedRecords)
+	assert.Equal(t, 20, (<-events).Progress.FinishedRecords)
+}
@@ -121,6 +121,11 @@ func GetDbPipelines(query *PipelineQuery) ([]*models.Pipeline, int64, errors.Err
 	if query.Pending > 0 {
 		clauses = append(clauses, dal.Where("finished_at is null and status IN ?", models.PendingTaskStatus))
 	}
+	if query.ProjectNames != nil {
+		clauses = append(clauses, dal.Where(
+			"blueprint_id IN (SELECT id FROM _devlake_blueprints WHERE project_name IN ?)", query.ProjectNames,
+		))
+	}
 	if query.Label != "" {
 		clauses = append(clauses,
 			dal.Join("LEFT JOIN _devlake_pipeline_labels pl ON pl.pipeline_id = _devlake_pipelines.id"),
@@ -181,3 +186,13 @@ func fillPipelineDetail(pipeline *models.Pipeline) errors.Error {
 	}
 	return nil
 }
+
+// GetReprocessReports returns the domain rows changed by the reprocess pipeline
+func GetReprocessReports(pipelineId uint64) ([]*models.ReprocessReport, errors.Error) {
+	reports := make([]*models.ReprocessReport, 0)
+	err := db.All(&reports, dal.Where("pipeline_id = ?", pipelineId), dal.Orderby("domain_table"))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error getting the reprocess reports")
+	}
+	return reports, nil
+}
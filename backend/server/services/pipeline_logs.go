@@ -0,0 +1,337 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+const pipelineLogFile = "pipeline.log"
+
+var (
+	// taskLogFileRegex matches the log files written by the task loggers, see logruslog.GetTaskLoggerPath
+	taskLogFileRegex = regexp.MustCompile(`^task-(\d+)-\d+-\d+-(.+)\.log$`)
+	// pipelineLogDirRegex matches the log directories of the pipelines, see logruslog.GetPipelineLoggerPath
+	pipelineLogDirRegex = regexp.MustCompile(`^pipeline-\d+-`)
+)
+
+// PipelineLogQuery filters the log entries of a pipeline
+type PipelineLogQuery struct {
+	TaskId  uint64 `form:"task_id"`
+	Subtask string `form:"subtask"`
+	// Level is the minimum level of the entries, i.e. warning returns the warnings and the errors
+	Level string `form:"level"`
+	// Text is searched in the messages case-insensitively
+	Text string `form:"text"`
+}
+
+// PipelineLogEntry is a line of the logs of a pipeline
+type PipelineLogEntry struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	Message    string `json:"message"`
+	PipelineId uint64 `json:"pipelineId,omitempty"`
+	TaskId     uint64 `json:"taskId,omitempty"`
+	Plugin     string `json:"plugin,omitempty"`
+	Subtask    string `json:"subtask,omitempty"`
+	TraceId    string `json:"traceId,omitempty"`
+}
+
+// SearchPipelineLogs passes the log entries of the pipeline matching the query to emit, the pipeline log first and
+// then the logs of the tasks in the order of their ids. Both the text and the json formats are supported.
+func SearchPipelineLogs(pipeline *models.Pipeline, query *PipelineLogQuery, emit func(entry *PipelineLogEntry) errors.Error) errors.Error {
+	minLevel := logrus.TraceLevel
+	if query.Level != "" {
+		level, err := logrus.ParseLevel(query.Level)
+		if err != nil {
+			return errors.BadInput.Wrap(err, fmt.Sprintf("invalid level %s", query.Level))
+		}
+		minLevel = level
+	}
+	logPath, err := getPipelineLogsPath(pipeline)
+	if err != nil {
+		return err
+	}
+	files, err := listPipelineLogFiles(logPath, query.TaskId)
+	if err != nil {
+		return err
+	}
+	text := strings.ToLower(query.Text)
+	for _, file := range files {
+		err = scanLogFile(filepath.Join(logPath, file.name), func(entry *PipelineLogEntry) errors.Error {
+			if entry.TaskId == 0 {
+				entry.TaskId = file.taskId
+			}
+			if entry.Plugin == "" {
+				entry.Plugin = file.plugin
+			}
+			if entry.PipelineId == 0 {
+				entry.PipelineId = pipeline.ID
+			}
+			if query.TaskId != 0 && entry.TaskId != query.TaskId {
+				return nil
+			}
+			if query.Subtask != "" && entry.Subtask != query.Subtask {
+				return nil
+			}
+			if level, err := logrus.ParseLevel(entry.Level); err == nil && level > minLevel {
+				return nil
+			}
+			if text != "" && !strings.Contains(strings.ToLower(entry.Message), text) {
+				return nil
+			}
+			return emit(entry)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type pipelineLogFileInfo struct {
+	name   string
+	taskId uint64
+	plugin string
+}
+
+// listPipelineLogFiles returns the pipeline log and the task logs, or only the logs of the task if taskId is set
+func listPipelineLogFiles(logPath string, taskId uint64) ([]pipelineLogFileInfo, errors.Error) {
+	entries, err := os.ReadDir(logPath)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to list the log files in %s", logPath))
+	}
+	var files []pipelineLogFileInfo
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if entry.Name() == pipelineLogFile {
+			if taskId == 0 {
+				files = append(files, pipelineLogFileInfo{name: entry.Name()})
+			}
+			continue
+		}
+		matches := taskLogFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		id, _ := strconv.ParseUint(matches[1], 10, 64)
+		if taskId == 0 || id == taskId {
+			files = append(files, pipelineLogFileInfo{name: entry.Name(), taskId: id, plugin: matches[2]})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].taskId < files[j].taskId
+	})
+	return files, nil
+}
+
+// scanLogFile parses the log file line by line, the lines that are not log entries are skipped
+func scanLogFile(path string, handle func(entry *PipelineLogEntry) errors.Error) errors.Error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("failed to open the log file %s", path))
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if fields := parseLogLine(strings.TrimSpace(line)); fields != nil {
+			if err := handle(logEntryOf(fields)); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return errors.Default.Wrap(readErr, fmt.Sprintf("failed to read the log file %s", path))
+		}
+	}
+}
+
+func logEntryOf(fields map[string]string) *PipelineLogEntry {
+	return &PipelineLogEntry{
+		Time:       fields["time"],
+		Level:      fields["level"],
+		Message:    strings.TrimSpace(fields["msg"]),
+		PipelineId: cast.ToUint64(fields[logruslog.FieldPipelineId]),
+		TaskId:     cast.ToUint64(fields[logruslog.FieldTaskId]),
+		Plugin:     fields[logruslog.FieldPlugin],
+		Subtask:    fields[logruslog.FieldSubtask],
+		TraceId:    fields["trace_id"],
+	}
+}
+
+// parseLogLine parses a line written by the json or the text formatter of logrus, nil is returned if the line is
+// not a log entry
+func parseLogLine(line string) map[string]string {
+	if line == "" {
+		return nil
+	}
+	var fields map[string]string
+	if strings.HasPrefix(line, "{") {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &values); err != nil {
+			return nil
+		}
+		fields = make(map[string]string, len(values))
+		for k, v := range values {
+			fields[k] = cast.ToString(v)
+		}
+	} else {
+		fields = parseLogfmt(line)
+	}
+	if fields["level"] == "" || fields["time"] == "" {
+		return nil
+	}
+	return fields
+}
+
+// parseLogfmt parses the `key=value key="quoted value"` pairs written by the text formatter of logrus
+func parseLogfmt(line string) map[string]string {
+	fields := make(map[string]string)
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+		eq := strings.IndexAny(line[i:], "= ")
+		if eq < 0 || line[i+eq] != '=' {
+			return fields
+		}
+		key := line[i : i+eq]
+		i += eq + 1
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return fields
+			}
+			value, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				value = line[i+1 : end]
+			}
+			fields[key] = value
+			i = end + 1
+		} else {
+			end := strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				end = len(line) - i
+			}
+			fields[key] = line[i : i+end]
+			i += end
+		}
+	}
+	return fields
+}
+
+// pruneLogs removes the log directories of the pipelines which have not been written for maxAge
+func pruneLogs(baseDir string, maxAge time.Duration, now time.Time) (int, errors.Error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return 0, errors.Default.Wrap(err, fmt.Sprintf("failed to list the log directory %s", baseDir))
+	}
+	pruned := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !pipelineLogDirRegex.MatchString(entry.Name()) {
+			continue
+		}
+		dir := filepath.Join(baseDir, entry.Name())
+		lastWritten, err := lastModifiedIn(dir)
+		if err != nil {
+			return pruned, err
+		}
+		if now.Sub(lastWritten) < maxAge {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return pruned, errors.Default.Wrap(err, fmt.Sprintf("failed to remove the log directory %s", dir))
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// lastModifiedIn returns the time the directory or any file in it was modified the last
+func lastModifiedIn(dir string) (time.Time, errors.Error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}, errors.Convert(err)
+	}
+	last := info.ModTime()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return time.Time{}, errors.Convert(err)
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last, nil
+}
+
+// scheduleLogRetention removes the logs of the pipelines older than `LOGGING_RETENTION_DAYS` daily
+func scheduleLogRetention() {
+	retentionDays := cfg.GetInt("LOGGING_RETENTION_DAYS")
+	if retentionDays <= 0 {
+		return
+	}
+	baseDir := filepath.Dir(logruslog.Global.GetConfig().Path)
+	maxAge := time.Duration(retentionDays) * 24 * time.Hour
+	retentionCron := cron.New(cron.WithLocation(time.UTC))
+	_, err := retentionCron.AddFunc("@daily", func() {
+		pruned, err := pruneLogs(baseDir, maxAge, time.Now())
+		if err != nil {
+			logger.Error(err, "failed to apply the log retention")
+		}
+		logger.Info("log retention: %d pipeline log directories removed", pruned)
+	})
+	if err != nil {
+		panic(errors.Default.Wrap(err, "failed to schedule the log retention"))
+	}
+	retentionCron.Start()
+}
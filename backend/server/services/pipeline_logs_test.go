@@ -0,0 +1,114 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLine(t *testing.T) {
+	for name, formatter := range map[string]logrus.Formatter{
+		"text": &logrus.TextFormatter{TimestampFormat: time.DateTime, FullTimestamp: true},
+		"json": &logrus.JSONFormatter{TimestampFormat: time.DateTime},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			inner := logrus.New()
+			inner.SetFormatter(formatter)
+			inner.SetOutput(&buf)
+			logger, _ := logruslog.NewDefaultLogger(inner)
+			logger = logruslog.WithFields(logger, map[string]interface{}{
+				logruslog.FieldPipelineId: uint64(3),
+				logruslog.FieldTaskId:     uint64(7),
+				logruslog.FieldPlugin:     "jira",
+			})
+			logger = logruslog.WithFields(logger.Nested("collectIssues"), map[string]interface{}{
+				logruslog.FieldSubtask: "collectIssues",
+			})
+			logger.Warn(nil, `request "timed out"`)
+
+			fields := parseLogLine(strings.TrimSpace(buf.String()))
+			assert.NotNil(t, fields)
+			entry := logEntryOf(fields)
+			assert.Equal(t, "warning", entry.Level)
+			assert.Equal(t, `[collectIssues] request "timed out"`, entry.Message)
+			assert.Equal(t, uint64(3), entry.PipelineId)
+			assert.Equal(t, uint64(7), entry.TaskId)
+			assert.Equal(t, "jira", entry.Plugin)
+			assert.Equal(t, "collectIssues", entry.Subtask)
+			assert.NotEmpty(t, entry.Time)
+		})
+	}
+	assert.Nil(t, parseLogLine(""))
+	assert.Nil(t, parseLogLine("panic: runtime error"))
+	assert.Nil(t, parseLogLine("{not json"))
+}
+
+func TestListPipelineLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"task-12-2-1-gitlab.log", pipelineLogFile, "task-9-1-1-jira.log", "notes.txt"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	files, err := listPipelineLogFiles(dir, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []pipelineLogFileInfo{
+		{name: pipelineLogFile},
+		{name: "task-9-1-1-jira.log", taskId: 9, plugin: "jira"},
+		{name: "task-12-2-1-gitlab.log", taskId: 12, plugin: "gitlab"},
+	}, files)
+
+	files, err = listPipelineLogFiles(dir, 12)
+	assert.Nil(t, err)
+	assert.Equal(t, []pipelineLogFileInfo{{name: "task-12-2-1-gitlab.log", taskId: 12, plugin: "gitlab"}}, files)
+}
+
+func TestPruneLogs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-10 * 24 * time.Hour)
+	for name, modTime := range map[string]time.Time{
+		"pipeline-1-20240101-0000": old,
+		"pipeline-2-20240102-0000": now,
+		"archives":                 old,
+	} {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.Mkdir(path, 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(path, pipelineLogFile), nil, 0644))
+		assert.Nil(t, os.Chtimes(filepath.Join(path, pipelineLogFile), modTime, modTime))
+		assert.Nil(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	pruned, err := pruneLogs(dir, 7*24*time.Hour, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, pruned)
+	_, statErr := os.Stat(filepath.Join(dir, "pipeline-1-20240101-0000"))
+	assert.True(t, os.IsNotExist(statErr))
+	_, statErr = os.Stat(filepath.Join(dir, "pipeline-2-20240102-0000"))
+	assert.Nil(t, statErr)
+	_, statErr = os.Stat(filepath.Join(dir, "archives"))
+	assert.Nil(t, statErr)
+}
@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queuedPipelineStatus are the statuses of the pipelines waiting for a slot to run
+var queuedPipelineStatus = []string{models.TASK_CREATED, models.TASK_RERUN, models.TASK_RESUME}
+
+var (
+	queuedPipelinesDesc = prometheus.NewDesc(
+		"devlake_pipelines_queued",
+		"Number of pipelines waiting to be run.",
+		nil, nil,
+	)
+	runningPipelinesDesc = prometheus.NewDesc(
+		"devlake_pipelines_running",
+		"Number of pipelines being run, by all the workers in worker mode.",
+		nil, nil,
+	)
+)
+
+// pipelineCollector counts the queued and running pipelines from the database when scraped, so the numbers
+// stay correct across restarts and workers
+type pipelineCollector struct{}
+
+func (pipelineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queuedPipelinesDesc
+	ch <- runningPipelinesDesc
+}
+
+func (pipelineCollector) Collect(ch chan<- prometheus.Metric) {
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	err := db.All(
+		&counts,
+		dal.Select("status, count(*) AS count"),
+		dal.From(&models.Pipeline{}),
+		dal.Where("status IN ?", append([]string{models.TASK_RUNNING}, queuedPipelineStatus...)),
+		dal.Groupby("status"),
+	)
+	if err != nil {
+		globalPipelineLog.Error(err, "failed to count pipelines for metrics")
+		return
+	}
+	var queued, running int64
+	for _, c := range counts {
+		if c.Status == models.TASK_RUNNING {
+			running += c.Count
+		} else {
+			queued += c.Count
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(queuedPipelinesDesc, prometheus.GaugeValue, float64(queued))
+	ch <- prometheus.MustNewConstMetric(runningPipelinesDesc, prometheus.GaugeValue, float64(running))
+}
@@ -25,11 +25,14 @@ import (
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/runner"
+	"github.com/apache/incubator-devlake/core/tracing"
 	"github.com/apache/incubator-devlake/impls/logruslog"
+	"go.opentelemetry.io/otel/attribute"
 	"time"
 )
 
 type pipelineRunner struct {
+	ctx      context.Context
 	logger   log.Logger
 	pipeline *models.Pipeline
 }
@@ -39,7 +42,7 @@ func (p *pipelineRunner) runPipelineStandalone() errors.Error {
 		basicRes.ReplaceLogger(p.logger),
 		p.pipeline.ID,
 		func(taskIds []uint64) errors.Error {
-			return RunTasksStandalone(p.logger, taskIds)
+			return RunTasksStandalone(p.ctx, p.logger, taskIds)
 		},
 	)
 }
@@ -59,7 +62,9 @@ func GetPipelineLogger(pipeline *models.Pipeline) log.Logger {
 			Writer: stream,
 		})
 	}
-	return pipelineLogger
+	return logruslog.WithFields(pipelineLogger, map[string]interface{}{
+		logruslog.FieldPipelineId: pipeline.ID,
+	})
 }
 
 // runPipeline start a pipeline actually
@@ -68,12 +73,23 @@ func runPipeline(pipelineId uint64) errors.Error {
 	if err != nil {
 		return err
 	}
+	ctx, span := tracing.Start(context.Background(), "pipeline",
+		attribute.Int64("devlake.pipeline.id", int64(ppl.ID)),
+		attribute.Int64("devlake.blueprint.id", int64(ppl.BlueprintId)),
+		attribute.String("devlake.pipeline.name", ppl.Name),
+	)
 	pipelineRun := pipelineRunner{
-		logger:   GetPipelineLogger(ppl),
+		ctx:      leasePipeline(ctx, ppl.ID),
+		logger:   logruslog.WithTraceContext(GetPipelineLogger(ppl), ctx),
 		pipeline: ppl,
 	}
+	publishPipelineStatus(ppl)
 	// run
 	err = pipelineRun.runPipelineStandalone()
+	tracing.End(span, err)
+	if !releasePipeline(pipelineId) {
+		return errors.Default.New(fmt.Sprintf("pipeline %d was reclaimed by another worker", pipelineId))
+	}
 	isCancelled := errors.Is(err, context.Canceled)
 	if err != nil {
 		err = errors.Default.Wrap(err, fmt.Sprintf("Error running pipeline %d.", pipelineId))
@@ -92,6 +108,9 @@ func runPipeline(pipelineId uint64) errors.Error {
 		dbPipeline.Message = err.Error()
 		dbPipeline.ErrorName = err.Messages().Format()
 	}
+	// the pipeline is no longer leased, so it could be rerun by any worker
+	dbPipeline.WorkerId = ""
+	dbPipeline.LeaseExpiresAt = nil
 	dbPipeline.Status, err = ComputePipelineStatus(dbPipeline, isCancelled)
 	if err != nil {
 		globalPipelineLog.Error(err, "compute pipeline status failed")
@@ -102,6 +121,7 @@ func runPipeline(pipelineId uint64) errors.Error {
 		globalPipelineLog.Error(err, "update pipeline state failed")
 		return err
 	}
+	publishPipelineStatus(dbPipeline)
 	// notify external webhook
 	return NotifyExternal(pipelineId)
 }
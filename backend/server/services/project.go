@@ -32,6 +32,8 @@ import (
 // ProjectQuery used to query projects as the api project input
 type ProjectQuery struct {
 	Pagination
+	// ProjectNames restricts the results to the projects, nil for all projects
+	ProjectNames []string `form:"-"`
 }
 
 // GetProjects returns a paginated list of Projects based on `query`
@@ -43,6 +45,9 @@ func GetProjects(query *ProjectQuery) ([]*models.ApiOutputProject, int64, errors
 	clauses := []dal.Clause{
 		dal.From(&models.Project{}),
 	}
+	if query.ProjectNames != nil {
+		clauses = append(clauses, dal.Where("name IN ?", query.ProjectNames))
+	}
 
 	count, err := db.Count(clauses...)
 	if err != nil {
@@ -443,3 +448,19 @@ func makeProjectOutput(project *models.Project, withLastPipeline bool) (*models.
 	}
 	return projectOutput, err
 }
+
+// ReprocessProject triggers the blueprint of the project in reprocess mode, only the subtasks depending on the
+// raw data already collected would run
+func ReprocessProject(name string) (*models.Pipeline, errors.Error) {
+	blueprint, err := GetBlueprintByProjectName(name)
+	if err != nil {
+		return nil, err
+	}
+	if blueprint == nil {
+		return nil, errors.NotFound.New(fmt.Sprintf("project [%s] has no blueprint", name))
+	}
+	return TriggerBlueprint(blueprint.ID, &models.SyncPolicy{
+		SkipOnFail: blueprint.SkipOnFail,
+		Reprocess:  true,
+	}, true)
+}
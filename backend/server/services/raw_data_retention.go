@@ -0,0 +1,680 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/robfig/cron/v3"
+)
+
+const rawDataRetentionBatchSize = 500
+const defaultRawDataArchiveDir = "raw_data_archive"
+const rawDataArchiveExt = ".jsonl.gz"
+
+var rawDataRetentionLock sync.Mutex
+
+// RawDataRetentionReport is what a retention run did, or would do in dry-run mode, to a raw table
+type RawDataRetentionReport struct {
+	Table    string `json:"table"`
+	PolicyId uint64 `json:"policyId"`
+	// Pruned is the number of rows deleted by the policy
+	Pruned int64 `json:"pruned"`
+	// Compressed is the number of rows whose `data` was compressed
+	Compressed int64  `json:"compressed"`
+	Archive    string `json:"archive,omitempty"`
+	// Skipped is why the table was left untouched, i.e. a pipeline of its plugin is running
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RawDataArchive is a file of the raw rows pruned from `Table`, `Path` is relative to `RAW_DATA_ARCHIVE_DIR`
+type RawDataArchive struct {
+	Path       string    `json:"path"`
+	Table      string    `json:"table"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// rawDataArchiveRow is a line of the archive, `Data` is always stored decompressed
+type rawDataArchiveRow struct {
+	ID          uint64          `json:"id"`
+	Params      string          `json:"params"`
+	Data        []byte          `json:"data"`
+	Url         string          `json:"url"`
+	Input       json.RawMessage `json:"input,omitempty"`
+	CollectedAt *time.Time      `json:"collectedAt,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+type rawDataArchiveWriter struct {
+	path    string
+	file    *os.File
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+}
+
+func getRawDataArchiveDir() string {
+	dir := strings.TrimSpace(cfg.GetString("RAW_DATA_ARCHIVE_DIR"))
+	if dir == "" {
+		return defaultRawDataArchiveDir
+	}
+	return dir
+}
+
+func newRawDataArchiveWriter(table string, now time.Time) (*rawDataArchiveWriter, errors.Error) {
+	dir := filepath.Join(getRawDataArchiveDir(), table)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to create the archive directory %s", dir))
+	}
+	path := filepath.Join(dir, now.UTC().Format("20060102T150405")+rawDataArchiveExt)
+	// gzip readers handle the concatenated members, appending is safe if the file exists
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to create the archive %s", path))
+	}
+	gzipWriter := gzip.NewWriter(file)
+	return &rawDataArchiveWriter{
+		path:    path,
+		file:    file,
+		gzip:    gzipWriter,
+		encoder: json.NewEncoder(gzipWriter),
+	}, nil
+}
+
+func (w *rawDataArchiveWriter) write(row *helper.RawData) errors.Error {
+	data, err := helper.DecompressRawData(row.Data)
+	if err != nil {
+		return err
+	}
+	archived := &rawDataArchiveRow{
+		ID:          row.ID,
+		Params:      row.Params,
+		Data:        data,
+		Url:         row.Url,
+		CollectedAt: row.CollectedAt,
+		CreatedAt:   row.CreatedAt,
+	}
+	if len(row.Input) > 0 {
+		archived.Input = row.Input
+	}
+	return errors.Convert(w.encoder.Encode(archived))
+}
+
+// flush makes sure the rows written so far are persisted before they are deleted from the database
+func (w *rawDataArchiveWriter) flush() errors.Error {
+	if err := w.gzip.Flush(); err != nil {
+		return errors.Convert(err)
+	}
+	return errors.Convert(w.file.Sync())
+}
+
+func (w *rawDataArchiveWriter) close() errors.Error {
+	if err := w.gzip.Close(); err != nil {
+		w.file.Close()
+		return errors.Convert(err)
+	}
+	return errors.Convert(w.file.Close())
+}
+
+// GetRawDataRetentionPolicies returns all RawDataRetentionPolicies
+func GetRawDataRetentionPolicies() ([]*models.RawDataRetentionPolicy, errors.Error) {
+	policies := make([]*models.RawDataRetentionPolicy, 0)
+	err := db.All(&policies, dal.Orderby("id"))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding DB raw data retention policies")
+	}
+	return policies, nil
+}
+
+func getRawDataRetentionPolicy(id uint64) (*models.RawDataRetentionPolicy, errors.Error) {
+	policy := &models.RawDataRetentionPolicy{}
+	err := db.First(policy, dal.Where("id = ?", id))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return nil, errors.NotFound.New(fmt.Sprintf("raw data retention policy %d not found", id))
+		}
+		return nil, errors.Default.Wrap(err, "error getting the raw data retention policy from database")
+	}
+	return policy, nil
+}
+
+func validateRawDataRetentionPolicy(policy *models.RawDataRetentionPolicy) errors.Error {
+	if err := VerifyStruct(policy); err != nil {
+		return err
+	}
+	if policy.Plugin == "" && policy.RawTable == "" {
+		return errors.BadInput.New("either plugin or rawTable is required")
+	}
+	if policy.RawTable != "" {
+		prefix := "_raw_"
+		if policy.Plugin != "" {
+			prefix = fmt.Sprintf("_raw_%s_", policy.Plugin)
+		}
+		if !strings.HasPrefix(policy.RawTable, prefix) {
+			return errors.BadInput.New(fmt.Sprintf("rawTable must start with %s", prefix))
+		}
+	}
+	if policy.KeepCollections == 0 && policy.KeepDays == 0 && !policy.Compress {
+		return errors.BadInput.New("one of keepCollections, keepDays and compress is required")
+	}
+	count, err := db.Count(
+		dal.From(&models.RawDataRetentionPolicy{}),
+		dal.Where("plugin = ? AND raw_table = ? AND id != ?", policy.Plugin, policy.RawTable, policy.ID),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "error checking the duplicated raw data retention policies")
+	}
+	if count > 0 {
+		return errors.Conflict.New("a raw data retention policy for the same plugin and raw table already exists")
+	}
+	return nil
+}
+
+// CreateRawDataRetentionPolicy accepts a RawDataRetentionPolicy instance and insert it to database
+func CreateRawDataRetentionPolicy(policy *models.RawDataRetentionPolicy) (*models.RawDataRetentionPolicy, errors.Error) {
+	policy.ID = 0
+	if err := validateRawDataRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := db.Create(policy); err != nil {
+		return nil, errors.Default.Wrap(err, "error creating the raw data retention policy")
+	}
+	return policy, nil
+}
+
+// PatchRawDataRetentionPolicy updates the RawDataRetentionPolicy with the given fields
+func PatchRawDataRetentionPolicy(id uint64, body map[string]interface{}) (*models.RawDataRetentionPolicy, errors.Error) {
+	policy, err := getRawDataRetentionPolicy(id)
+	if err != nil {
+		return nil, err
+	}
+	err = helper.DecodeMapStruct(body, policy, true)
+	if err != nil {
+		return nil, err
+	}
+	policy.ID = id
+	if err := validateRawDataRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := db.Update(policy); err != nil {
+		return nil, errors.Default.Wrap(err, "error updating the raw data retention policy")
+	}
+	return policy, nil
+}
+
+// DeleteRawDataRetentionPolicy deletes the RawDataRetentionPolicy, the rows pruned would not be restored
+func DeleteRawDataRetentionPolicy(id uint64) errors.Error {
+	if _, err := getRawDataRetentionPolicy(id); err != nil {
+		return err
+	}
+	return db.Delete(&models.RawDataRetentionPolicy{}, dal.Where("id = ?", id))
+}
+
+// matchRawDataRetentionPolicy returns the policy of the `table`, or the policy of the plugin owning the `table`
+// if there is none. Plugin names may prefix each other, i.e. github and github_graphql, the longest one wins.
+func matchRawDataRetentionPolicy(table string, policies []*models.RawDataRetentionPolicy) *models.RawDataRetentionPolicy {
+	var matched *models.RawDataRetentionPolicy
+	for _, policy := range policies {
+		if policy.RawTable != "" {
+			if policy.RawTable == table {
+				return policy
+			}
+			continue
+		}
+		if strings.HasPrefix(table, fmt.Sprintf("_raw_%s_", policy.Plugin)) &&
+			(matched == nil || len(policy.Plugin) > len(matched.Plugin)) {
+			matched = policy
+		}
+	}
+	return matched
+}
+
+// ApplyRawDataRetention prunes the raw tables by their RawDataRetentionPolicies, the pruned rows would be archived
+// if required. Nothing would be written in dry-run mode, the number of rows to be pruned or compressed are
+// reported instead.
+func ApplyRawDataRetention(dryRun bool) ([]*RawDataRetentionReport, errors.Error) {
+	if !rawDataRetentionLock.TryLock() {
+		return nil, errors.Conflict.New("another raw data retention is running")
+	}
+	defer rawDataRetentionLock.Unlock()
+
+	policies, err := GetRawDataRetentionPolicies()
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]*RawDataRetentionReport, 0)
+	if len(policies) == 0 {
+		return reports, nil
+	}
+	tables, err := db.AllTables()
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error listing the raw tables")
+	}
+	sort.Strings(tables)
+	now := time.Now()
+	for _, table := range tables {
+		if !strings.HasPrefix(table, "_raw_") {
+			continue
+		}
+		policy := matchRawDataRetentionPolicy(table, policies)
+		if policy == nil {
+			continue
+		}
+		report := &RawDataRetentionReport{Table: table, PolicyId: policy.ID}
+		reports = append(reports, report)
+		// the running collectors and extractors may be reading the rows, the table is left to the next run
+		plugin, err := getRunningRawDataPlugin(table)
+		if err != nil {
+			logger.Error(err, "failed to check the running tasks of %s", table)
+			report.Error = err.Error()
+			continue
+		}
+		if plugin != "" {
+			report.Skipped = fmt.Sprintf("a task of plugin %s is running", plugin)
+			continue
+		}
+		err = applyRawDataRetention(report, policy, dryRun, now)
+		if err != nil {
+			logger.Error(err, "failed to apply the raw data retention policy %d on %s", policy.ID, table)
+			report.Error = err.Error()
+		}
+	}
+	return reports, nil
+}
+
+// getRunningRawDataPlugin returns the plugin running a task which may write or read the raw `table`, plugin names
+// may prefix each other so the tables of github_graphql are considered to be used by github as well
+func getRunningRawDataPlugin(table string) (string, errors.Error) {
+	var plugins []string
+	err := db.Pluck("DISTINCT plugin", &plugins, dal.From(&models.Task{}), dal.Where("status = ?", models.TASK_RUNNING))
+	if err != nil {
+		return "", errors.Default.Wrap(err, "error listing the running tasks")
+	}
+	sort.Strings(plugins)
+	for _, plugin := range plugins {
+		if strings.HasPrefix(table, fmt.Sprintf("_raw_%s_", plugin)) {
+			return plugin, nil
+		}
+	}
+	return "", nil
+}
+
+func applyRawDataRetention(report *RawDataRetentionReport, policy *models.RawDataRetentionPolicy, dryRun bool, now time.Time) (err errors.Error) {
+	table := report.Table
+	conditions, err := getRawDataPruneConditions(table, policy, now)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		for _, condition := range conditions {
+			count, err := db.Count(dal.From(table), condition)
+			if err != nil {
+				return errors.Default.Wrap(err, fmt.Sprintf("error counting the rows to be pruned from %s", table))
+			}
+			report.Pruned += count
+		}
+	} else if len(conditions) > 0 {
+		var archive *rawDataArchiveWriter
+		if policy.Archive {
+			archive, err = newRawDataArchiveWriter(table, now)
+			if err != nil {
+				return err
+			}
+			report.Archive = archive.path
+			defer func() {
+				closeErr := archive.close()
+				if err == nil {
+					err = closeErr
+				}
+			}()
+		}
+		for _, condition := range conditions {
+			pruned, err := pruneRawData(table, condition, archive)
+			report.Pruned += pruned
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if policy.Compress && cfg.GetBool(helper.RawDataCompressionEnvStr) {
+		report.Compressed, err = compressRawData(table, dryRun)
+	}
+	return err
+}
+
+// getRawDataPruneConditions returns the conditions of the rows to be pruned by `policy`. The collections are
+// compared by `collected_at`, the rows collected before it was introduced are considered as the oldest collection.
+// An incremental collection only holds the changes since the collection before it, so the collections older than
+// the latest N ones are pruned only if they were superseded by a later full collection.
+func getRawDataPruneConditions(table string, policy *models.RawDataRetentionPolicy, now time.Time) ([]dal.Clause, errors.Error) {
+	conditions := make([]dal.Clause, 0)
+	var expiredAt time.Time
+	if policy.KeepDays > 0 {
+		expiredAt = now.AddDate(0, 0, -policy.KeepDays)
+		conditions = append(conditions, dal.Where("created_at < ?", expiredAt))
+	}
+	if policy.KeepCollections == 0 || !db.HasColumn(table, "collected_at") {
+		return conditions, nil
+	}
+	collections := make([]*helper.RawData, 0)
+	err := db.All(&collections, dal.Select("DISTINCT params, collected_at"), dal.From(table))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("error listing the collections of %s", table))
+	}
+	fullCollections, err := getFullRawDataCollections(table)
+	if err != nil {
+		return nil, err
+	}
+	collectedAts := make(map[string][]*time.Time)
+	for _, collection := range collections {
+		collectedAts[collection.Params] = append(collectedAts[collection.Params], collection.CollectedAt)
+	}
+	params := make([]string, 0, len(collectedAts))
+	for p := range collectedAts {
+		params = append(params, p)
+	}
+	sort.Strings(params)
+	for _, p := range params {
+		times := collectedAts[p]
+		if len(times) <= policy.KeepCollections {
+			continue
+		}
+		// latest first, NULL last
+		sort.Slice(times, func(i, j int) bool {
+			if times[i] == nil || times[j] == nil {
+				return times[j] == nil && times[i] != nil
+			}
+			return times[i].After(*times[j])
+		})
+		// the kept collections may be incremental ones, keep the collections back to the full one they are based on
+		var supersededBy *time.Time
+		for _, collectedAt := range times[policy.KeepCollections-1:] {
+			if collectedAt != nil && fullCollections[p][collectedAt.UnixMilli()] {
+				supersededBy = collectedAt
+				break
+			}
+		}
+		if supersededBy == nil {
+			continue
+		}
+		if policy.KeepDays > 0 {
+			// rows expired are pruned by the previous condition already
+			conditions = append(conditions, dal.Where(
+				"params = ? AND (collected_at IS NULL OR collected_at < ?) AND created_at >= ?", p, supersededBy, expiredAt,
+			))
+		} else {
+			conditions = append(conditions, dal.Where(
+				"params = ? AND (collected_at IS NULL OR collected_at < ?)", p, supersededBy,
+			))
+		}
+	}
+	return conditions, nil
+}
+
+// getFullRawDataCollections returns the `collected_at` in milliseconds of the full collections of the `table` by params
+func getFullRawDataCollections(table string) (map[string]map[int64]bool, errors.Error) {
+	collections := make([]*models.RawDataCollection, 0)
+	err := db.All(&collections, dal.Where("raw_table = ? AND incremental = ?", table, false))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("error listing the full collections of %s", table))
+	}
+	fullCollections := make(map[string]map[int64]bool)
+	for _, collection := range collections {
+		if fullCollections[collection.Params] == nil {
+			fullCollections[collection.Params] = make(map[int64]bool)
+		}
+		fullCollections[collection.Params][collection.CollectedAt.UnixMilli()] = true
+	}
+	return fullCollections, nil
+}
+
+// pruneRawData deletes the rows matching the `condition` batch by batch, the rows are archived before deleted
+// if `archive` is not nil
+func pruneRawData(table string, condition dal.Clause, archive *rawDataArchiveWriter) (int64, errors.Error) {
+	var pruned int64
+	for {
+		var ids []uint64
+		if archive != nil {
+			rows := make([]*helper.RawData, 0, rawDataRetentionBatchSize)
+			err := db.All(&rows, dal.From(table), condition, dal.Orderby("id"), dal.Limit(rawDataRetentionBatchSize))
+			if err != nil {
+				return pruned, errors.Default.Wrap(err, fmt.Sprintf("error getting the rows to be archived from %s", table))
+			}
+			for _, row := range rows {
+				if err := archive.write(row); err != nil {
+					return pruned, errors.Default.Wrap(err, fmt.Sprintf("error archiving the rows of %s", table))
+				}
+				ids = append(ids, row.ID)
+			}
+			if err := archive.flush(); err != nil {
+				return pruned, errors.Default.Wrap(err, fmt.Sprintf("error archiving the rows of %s", table))
+			}
+		} else {
+			err := db.Pluck("id", &ids, dal.From(table), condition, dal.Orderby("id"), dal.Limit(rawDataRetentionBatchSize))
+			if err != nil {
+				return pruned, errors.Default.Wrap(err, fmt.Sprintf("error getting the rows to be pruned from %s", table))
+			}
+		}
+		if len(ids) == 0 {
+			return pruned, nil
+		}
+		err := db.Delete(&helper.RawData{}, dal.From(table), dal.Where("id IN ?", ids))
+		if err != nil {
+			return pruned, errors.Default.Wrap(err, fmt.Sprintf("error pruning the rows of %s", table))
+		}
+		pruned += int64(len(ids))
+	}
+}
+
+// compressRawData compresses the `data` of the rows collected before `RAW_DATA_COMPRESSION` was enabled. Only the
+// tables managed by the collector helpers, which have the `collected_at` column, are compressed, the raw tables of
+// the python plugins are read by the python extractors which are not aware of the compression.
+func compressRawData(table string, dryRun bool) (int64, errors.Error) {
+	if !db.HasColumn(table, "collected_at") {
+		return 0, nil
+	}
+	var compressed int64
+	var lastId uint64
+	for {
+		rows := make([]*helper.RawData, 0, rawDataRetentionBatchSize)
+		err := db.All(
+			&rows,
+			dal.Select("id, data"),
+			dal.From(table),
+			dal.Where("id > ? AND data IS NOT NULL AND SUBSTR(data, 1, 2) <> ?", lastId, []byte{0x1f, 0x8b}),
+			dal.Orderby("id"),
+			dal.Limit(rawDataRetentionBatchSize),
+		)
+		if err != nil {
+			return compressed, errors.Default.Wrap(err, fmt.Sprintf("error getting the rows to be compressed from %s", table))
+		}
+		if len(rows) == 0 {
+			return compressed, nil
+		}
+		for _, row := range rows {
+			lastId = row.ID
+			if len(row.Data) == 0 || helper.IsRawDataCompressed(row.Data) {
+				continue
+			}
+			compressed++
+			if dryRun {
+				continue
+			}
+			data, err := helper.CompressRawData(row.Data)
+			if err != nil {
+				return compressed, err
+			}
+			err = db.UpdateColumns(table, []dal.DalSet{{ColumnName: "data", Value: data}}, dal.Where("id = ?", row.ID))
+			if err != nil {
+				return compressed, errors.Default.Wrap(err, fmt.Sprintf("error compressing the rows of %s", table))
+			}
+		}
+	}
+}
+
+// GetRawDataArchives returns all archives under `RAW_DATA_ARCHIVE_DIR`
+func GetRawDataArchives() ([]*RawDataArchive, errors.Error) {
+	archives := make([]*RawDataArchive, 0)
+	dir := getRawDataArchiveDir()
+	matches, err := filepath.Glob(filepath.Join(dir, "_raw_*", "*"+rawDataArchiveExt))
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, errors.Convert(err)
+		}
+		path, err := filepath.Rel(dir, match)
+		if err != nil {
+			return nil, errors.Convert(err)
+		}
+		archives = append(archives, &RawDataArchive{
+			Path:       filepath.ToSlash(path),
+			Table:      filepath.Base(filepath.Dir(match)),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+	}
+	return archives, nil
+}
+
+// ImportRawDataArchive imports the archive back to its raw table with the original ids, so the domain layer
+// records still refer to them by `_raw_data_id` and the table could be reprocessed. Rows with the same id
+// would be overwritten.
+func ImportRawDataArchive(path string) (int64, errors.Error) {
+	dir := getRawDataArchiveDir()
+	// the path is cleaned as an absolute one so it could never escape from the archive directory
+	path = filepath.Join(dir, filepath.Clean("/"+path))
+	table := filepath.Base(filepath.Dir(path))
+	if !strings.HasPrefix(table, "_raw_") || filepath.Dir(filepath.Dir(path)) != filepath.Clean(dir) ||
+		!strings.HasSuffix(path, rawDataArchiveExt) {
+		return 0, errors.BadInput.New("invalid raw data archive path")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, errors.NotFound.New("raw data archive not found")
+		}
+		return 0, errors.Convert(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, errors.BadInput.Wrap(err, "invalid raw data archive")
+	}
+	defer reader.Close()
+
+	err = db.AutoMigrate(&helper.RawData{}, dal.From(table))
+	if err != nil {
+		return 0, errors.Default.Wrap(err, fmt.Sprintf("error migrating the raw table %s", table))
+	}
+	compress := cfg.GetBool(helper.RawDataCompressionEnvStr)
+	decoder := json.NewDecoder(reader)
+	var imported int64
+	rows := make([]*helper.RawData, 0, rawDataRetentionBatchSize)
+	save := func() errors.Error {
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := db.CreateOrUpdate(rows, dal.From(table)); err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("error importing the rows into %s", table))
+		}
+		imported += int64(len(rows))
+		rows = rows[:0]
+		return nil
+	}
+	for {
+		archived := &rawDataArchiveRow{}
+		err := decoder.Decode(archived)
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			// the archiving was interrupted, the rows flushed before are intact
+			logger.Warn(err, "raw data archive %s is truncated", path)
+			break
+		}
+		if err != nil {
+			return imported, errors.BadInput.Wrap(err, "invalid raw data archive")
+		}
+		data := archived.Data
+		if compress {
+			data, err = helper.CompressRawData(data)
+			if err != nil {
+				return imported, errors.Convert(err)
+			}
+		}
+		rows = append(rows, &helper.RawData{
+			ID:          archived.ID,
+			Params:      archived.Params,
+			Data:        data,
+			Url:         archived.Url,
+			Input:       archived.Input,
+			CollectedAt: archived.CollectedAt,
+			CreatedAt:   archived.CreatedAt,
+		})
+		if len(rows) == rawDataRetentionBatchSize {
+			if err := save(); err != nil {
+				return imported, err
+			}
+		}
+	}
+	return imported, save()
+}
+
+// scheduleRawDataRetention applies the raw data retention policies periodically by `RAW_DATA_RETENTION_CRON`
+func scheduleRawDataRetention() {
+	cronConfig := strings.TrimSpace(cfg.GetString("RAW_DATA_RETENTION_CRON"))
+	if cronConfig == "" {
+		return
+	}
+	retentionCron := cron.New(cron.WithLocation(time.UTC))
+	_, err := retentionCron.AddFunc(cronConfig, func() {
+		reports, err := ApplyRawDataRetention(false)
+		if err != nil {
+			logger.Error(err, "failed to apply the raw data retention policies")
+			return
+		}
+		for _, report := range reports {
+			if report.Skipped != "" {
+				logger.Info("raw data retention on %s skipped: %s", report.Table, report.Skipped)
+				continue
+			}
+			logger.Info("raw data retention on %s: %d rows pruned, %d rows compressed", report.Table, report.Pruned, report.Compressed)
+		}
+	})
+	if err != nil {
+		panic(errors.BadInput.Wrap(err, "invalid RAW_DATA_RETENTION_CRON"))
+	}
+	retentionCron.Start()
+}
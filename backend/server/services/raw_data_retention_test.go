@@ -0,0 +1,160 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMatchRawDataRetentionPolicy(t *testing.T) {
+	github := &models.RawDataRetentionPolicy{Model: common.Model{ID: 1}, Plugin: "github", KeepDays: 30}
+	githubGraphql := &models.RawDataRetentionPolicy{Model: common.Model{ID: 2}, Plugin: "github_graphql", KeepDays: 60}
+	githubIssues := &models.RawDataRetentionPolicy{Model: common.Model{ID: 3}, RawTable: "_raw_github_api_issues", KeepCollections: 1}
+	policies := []*models.RawDataRetentionPolicy{github, githubGraphql, githubIssues}
+
+	testCases := []struct {
+		name     string
+		table    string
+		expected *models.RawDataRetentionPolicy
+	}{
+		{"plugin policy", "_raw_github_api_pull_requests", github},
+		{"longest plugin wins", "_raw_github_graphql_issues", githubGraphql},
+		{"table policy wins", "_raw_github_api_issues", githubIssues},
+		{"no policy", "_raw_gitlab_api_issues", nil},
+		{"plugin prefix only", "_raw_githubx_api_issues", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, matchRawDataRetentionPolicy(tc.table, policies))
+		})
+	}
+}
+
+func TestGetRawDataPruneConditions(t *testing.T) {
+	mockDal := new(mockdal.Dal)
+	db = mockDal
+	defer func() { db = nil }()
+
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 10, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	mockDal.On("HasColumn", "_raw_jira_api_issues", "collected_at").Return(true)
+	mockDal.On("All", mock.AnythingOfType("*[]*api.RawData"), mock.Anything).Run(func(args mock.Arguments) {
+		collections := args.Get(0).(*[]*helper.RawData)
+		for params, collectedAts := range map[string][]*time.Time{
+			// incremental collections based on the full one at day 2
+			"chain": {nil, day(1), day(2), day(3), day(4), day(5)},
+			// incremental collections only, the full one was collected before collected_at was introduced
+			"incremental": {nil, day(1), day(2), day(3)},
+			"full":        {day(1), day(2), day(3)},
+		} {
+			for _, collectedAt := range collectedAts {
+				*collections = append(*collections, &helper.RawData{Params: params, CollectedAt: collectedAt})
+			}
+		}
+	}).Return(nil)
+	mockDal.On("All", mock.AnythingOfType("*[]*models.RawDataCollection"), mock.Anything).Run(func(args mock.Arguments) {
+		collections := args.Get(0).(*[]*models.RawDataCollection)
+		*collections = append(*collections,
+			&models.RawDataCollection{Params: "chain", CollectedAt: *day(2)},
+			&models.RawDataCollection{Params: "full", CollectedAt: *day(1)},
+			&models.RawDataCollection{Params: "full", CollectedAt: *day(2)},
+			&models.RawDataCollection{Params: "full", CollectedAt: *day(3)},
+		)
+	}).Return(nil)
+
+	conditions, err := getRawDataPruneConditions(
+		"_raw_jira_api_issues",
+		&models.RawDataRetentionPolicy{KeepCollections: 2},
+		time.Now(),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []dal.Clause{
+		dal.Where("params = ? AND (collected_at IS NULL OR collected_at < ?)", "chain", day(2)),
+		dal.Where("params = ? AND (collected_at IS NULL OR collected_at < ?)", "full", day(2)),
+	}, conditions)
+}
+
+func TestPruneAndImportRawData(t *testing.T) {
+	mockDal := new(mockdal.Dal)
+	db = mockDal
+	cfg = viper.New()
+	defer func() { db, cfg = nil, nil }()
+	cfg.(*viper.Viper).Set("RAW_DATA_ARCHIVE_DIR", t.TempDir())
+
+	collectedAt := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	compressed, err := helper.CompressRawData([]byte(`{"id":2}`))
+	assert.Nil(t, err)
+	rows := []*helper.RawData{
+		{ID: 1, Params: "board", Data: []byte(`{"id":1}`), Url: "issues/1", CollectedAt: &collectedAt, CreatedAt: collectedAt},
+		{ID: 2, Params: "board", Data: compressed, Url: "issues/2", CollectedAt: &collectedAt, CreatedAt: collectedAt},
+	}
+	condition := dal.Where("params = ?", "board")
+	mockDal.On("All", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		dst := args.Get(0).(*[]*helper.RawData)
+		*dst = append(*dst, rows...)
+	}).Return(nil).Once()
+	mockDal.On("All", mock.Anything, mock.Anything).Return(nil).Once()
+	mockDal.On("Delete", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.Contains(t, args.Get(1), dal.Where("id IN ?", []uint64{1, 2}))
+	}).Return(nil).Once()
+
+	archive, err := newRawDataArchiveWriter("_raw_jira_api_issues", collectedAt)
+	assert.Nil(t, err)
+	pruned, err := pruneRawData("_raw_jira_api_issues", condition, archive)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), pruned)
+	assert.Nil(t, archive.close())
+
+	archives, err := GetRawDataArchives()
+	assert.Nil(t, err)
+	if !assert.Len(t, archives, 1) {
+		return
+	}
+	assert.Equal(t, "_raw_jira_api_issues/20241001T000000.jsonl.gz", archives[0].Path)
+	assert.Equal(t, "_raw_jira_api_issues", archives[0].Table)
+
+	var imported []*helper.RawData
+	mockDal.On("AutoMigrate", mock.Anything, mock.Anything).Return(nil).Once()
+	mockDal.On("CreateOrUpdate", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		imported = append(imported, args.Get(0).([]*helper.RawData)...)
+	}).Return(nil).Once()
+	count, err := ImportRawDataArchive(archives[0].Path)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+	if assert.Len(t, imported, 2) {
+		assert.Equal(t, rows[0], imported[0])
+		assert.Equal(t, uint64(2), imported[1].ID)
+		assert.Equal(t, []byte(`{"id":2}`), imported[1].Data)
+	}
+	mockDal.AssertExpectations(t)
+
+	_, err = ImportRawDataArchive("../../etc/passwd")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,218 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// RoleBindingQuery is a query for GetRoleBindings
+type RoleBindingQuery struct {
+	Pagination
+	SubjectType string `form:"subjectType"`
+	Subject     string `form:"subject"`
+	ProjectName string `form:"projectName"`
+}
+
+// GetRoleBindings returns a paginated list of RoleBindings based on `query`
+func GetRoleBindings(query *RoleBindingQuery) ([]*models.RoleBinding, int64, errors.Error) {
+	clauses := []dal.Clause{dal.From(&models.RoleBinding{})}
+	if query.SubjectType != "" {
+		clauses = append(clauses, dal.Where("subject_type = ?", query.SubjectType))
+	}
+	if query.Subject != "" {
+		clauses = append(clauses, dal.Where("subject = ?", query.Subject))
+	}
+	if query.ProjectName != "" {
+		clauses = append(clauses, dal.Where("project_name = ?", query.ProjectName))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of role bindings")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	bindings := make([]*models.RoleBinding, 0)
+	err = db.All(&bindings, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB role bindings")
+	}
+	return bindings, count, nil
+}
+
+// CreateRoleBinding accepts a RoleBinding instance and insert it to database
+func CreateRoleBinding(binding *models.RoleBinding, user *common.User) (*models.RoleBinding, errors.Error) {
+	if err := VerifyStruct(binding); err != nil {
+		return nil, err
+	}
+	if !binding.Role.IsValid() {
+		return nil, errors.BadInput.New(fmt.Sprintf("unknown role %s", binding.Role))
+	}
+	if binding.Role == models.ROLE_ADMIN && binding.ProjectName != "" {
+		return nil, errors.BadInput.New("admin role can not be scoped to a project")
+	}
+	if binding.ProjectName != "" {
+		if _, err := getProjectByName(db, binding.ProjectName); err != nil {
+			return nil, err
+		}
+	}
+	if user != nil {
+		binding.Creator = common.Creator{Creator: user.Name, CreatorEmail: user.Email}
+	}
+	if err := db.Create(binding); err != nil {
+		return nil, errors.Default.Wrap(err, "error creating the role binding")
+	}
+	return binding, nil
+}
+
+// DeleteRoleBinding deletes the RoleBinding
+func DeleteRoleBinding(id uint64) errors.Error {
+	binding := &models.RoleBinding{}
+	err := db.First(binding, dal.Where("id = ?", id))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return errors.NotFound.New(fmt.Sprintf("role binding %d not found", id))
+		}
+		return errors.Default.Wrap(err, "error getting the role binding from database")
+	}
+	return db.Delete(binding)
+}
+
+// GetSubjectRoleBindings returns the RoleBindings of the user from the proxy headers, or of the api key when
+// the request was authenticated by one. Users listed in `RBAC_ADMINS` are always granted the admin role so the
+// very first bindings could be created.
+func GetSubjectRoleBindings(user *common.User, apiKeyId uint64) (models.RoleBindings, errors.Error) {
+	bindings := make(models.RoleBindings, 0)
+	var err errors.Error
+	if apiKeyId != 0 {
+		err = db.All(&bindings,
+			dal.Where("subject_type = ? AND subject = ?", models.ROLE_SUBJECT_APIKEY, strconv.FormatUint(apiKeyId, 10)),
+		)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "error finding role bindings of the api key")
+		}
+		return bindings, nil
+	}
+	if user == nil || user.Name == "" {
+		return bindings, nil
+	}
+	subjects := []string{user.Name}
+	if user.Email != "" {
+		subjects = append(subjects, user.Email)
+	}
+	err = db.All(&bindings, dal.Where("subject_type = ? AND subject IN ?", models.ROLE_SUBJECT_USER, subjects))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding role bindings of the user")
+	}
+	for _, admin := range strings.Split(cfg.GetString("RBAC_ADMINS"), ",") {
+		admin = strings.TrimSpace(admin)
+		if admin != "" && (admin == user.Name || admin == user.Email) {
+			bindings = append(bindings, &models.RoleBinding{
+				SubjectType: models.ROLE_SUBJECT_USER,
+				Subject:     admin,
+				Role:        models.ROLE_ADMIN,
+			})
+			break
+		}
+	}
+	return bindings, nil
+}
+
+// GetProjectNameOfBlueprint returns the project the blueprint belongs to, empty for blueprints created
+// without a project
+func GetProjectNameOfBlueprint(blueprintId uint64) (string, errors.Error) {
+	blueprint := &models.Blueprint{}
+	err := db.First(blueprint, dal.Select("project_name"), dal.Where("id = ?", blueprintId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Default.Wrap(err, "error getting the blueprint from database")
+	}
+	return blueprint.ProjectName, nil
+}
+
+// GetProjectNameOfPipeline returns the project the pipeline was triggered for
+func GetProjectNameOfPipeline(pipelineId uint64) (string, errors.Error) {
+	pipeline := &models.Pipeline{}
+	err := db.First(pipeline, dal.Select("blueprint_id"), dal.Where("id = ?", pipelineId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Default.Wrap(err, "error getting the pipeline from database")
+	}
+	if pipeline.BlueprintId == 0 {
+		return "", nil
+	}
+	return GetProjectNameOfBlueprint(pipeline.BlueprintId)
+}
+
+// GetProjectNameOfTask returns the project the pipeline of the task was triggered for
+func GetProjectNameOfTask(taskId uint64) (string, errors.Error) {
+	task := &models.Task{}
+	err := db.First(task, dal.Select("pipeline_id"), dal.Where("id = ?", taskId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Default.Wrap(err, "error getting the task from database")
+	}
+	return GetProjectNameOfPipeline(task.PipelineId)
+}
+
+// GetProjectNameOfNotificationChannel returns the project the notification channel subscribes to
+func GetProjectNameOfNotificationChannel(channelId uint64) (string, errors.Error) {
+	channel, err := getNotificationChannel(channelId)
+	if err != nil {
+		if err.GetType() == errors.NotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	if channel.ProjectName != "" || channel.BlueprintId == 0 {
+		return channel.ProjectName, nil
+	}
+	return GetProjectNameOfBlueprint(channel.BlueprintId)
+}
+
+// GetProjectNamesOfConnection returns all projects whose blueprints use the connection
+func GetProjectNamesOfConnection(pluginName string, connectionId uint64) ([]string, errors.Error) {
+	var projectNames []string
+	err := db.Pluck(
+		"DISTINCT bp.project_name",
+		&projectNames,
+		dal.From("_devlake_blueprint_connections bc"),
+		dal.Join("LEFT JOIN _devlake_blueprints bp ON bp.id = bc.blueprint_id"),
+		dal.Where("bc.plugin_name = ? AND bc.connection_id = ? AND bp.project_name != ''", pluginName, connectionId),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding projects of the connection")
+	}
+	return projectNames, nil
+}
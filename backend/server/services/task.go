@@ -174,8 +174,8 @@ func CancelTask(taskId uint64) errors.Error {
 	return nil
 }
 
-// RunTasksStandalone run tasks in parallel
-func RunTasksStandalone(parentLogger log.Logger, taskIds []uint64) errors.Error {
+// RunTasksStandalone run tasks in parallel, ctx carries the span of the pipeline the tasks are traced under
+func RunTasksStandalone(ctx context.Context, parentLogger log.Logger, taskIds []uint64) errors.Error {
 	if len(taskIds) == 0 {
 		return nil
 	}
@@ -184,7 +184,7 @@ func RunTasksStandalone(parentLogger log.Logger, taskIds []uint64) errors.Error
 		go func(id uint64) {
 			taskLog.Info("run task #%d in background ", id)
 			var err errors.Error
-			taskErr := runTaskStandalone(parentLogger, id)
+			taskErr := runTaskStandalone(ctx, parentLogger, id)
 			if taskErr != nil {
 				err = errors.Default.Wrap(taskErr, fmt.Sprintf("Error running task %d.", id))
 			}
@@ -301,6 +301,7 @@ func GetSubTasksInfo(pipelineId uint64, shouldSanitize bool, tx dal.Dal) (*model
 				IsCollector:     subtask.IsCollector,
 				IsFailed:        subtask.IsFailed,
 				Message:         subtask.Message,
+				Attempts:        subtask.Attempts,
 			}
 			subTaskResult.SubtaskDetails = append(subTaskResult.SubtaskDetails, t)
 		}
@@ -26,6 +26,7 @@ import (
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/core/runner"
 	"sync"
+	"time"
 )
 
 // RunningTaskData FIXME ...
@@ -91,18 +92,22 @@ func (rt *RunningTask) Remove(taskId uint64) (context.CancelFunc, errors.Error)
 
 var runningTasks RunningTask
 
+// taskIdContextKey carries the id of the running task in its context, so the events raised by the helpers could
+// be traced back to the task
+type taskIdContextKey struct{}
+
 func init() {
 	// set all previous unfinished tasks to status failed
 	runningTasks.tasks = make(map[uint64]*RunningTaskData)
 }
 
-func runTaskStandalone(parentLog log.Logger, taskId uint64) errors.Error {
+func runTaskStandalone(parentCtx context.Context, parentLog log.Logger, taskId uint64) errors.Error {
 	// deferring cleaning up
 	defer func() {
 		_, _ = runningTasks.Remove(taskId)
 	}()
 	// for task cancelling
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.WithValue(parentCtx, taskIdContextKey{}, taskId))
 	err := runningTasks.Add(taskId, cancel)
 	if err != nil {
 		return err
@@ -117,6 +122,7 @@ func runTaskStandalone(parentLog log.Logger, taskId uint64) errors.Error {
 		taskId,
 	)
 	close(progress)
+	notifyTaskFailed(taskId)
 	return err
 }
 
@@ -133,9 +139,69 @@ func updateTaskProgress(taskId uint64, progress chan plugin.RunningProgress) {
 		return
 	}
 	progressDetail := data.ProgressDetail
+	publisher := &progressPublisher{}
+	if task, err := GetTask(taskId); err == nil {
+		publisher.task = task
+	}
 	for p := range progress {
 		runningTasks.mu.Lock()
 		runner.UpdateProgressDetail(basicRes, taskId, progressDetail, &p)
+		publisher.publish(&p, progressDetail)
 		runningTasks.mu.Unlock()
 	}
 }
+
+// notifyRateLimited sends RateLimited notification when the requests of a task are paused by the rate limit
+// of the data source
+func notifyRateLimited(ctx context.Context, endpoint string, pause time.Duration) {
+	taskId, ok := ctx.Value(taskIdContextKey{}).(uint64)
+	if !ok || notificationService == nil {
+		return
+	}
+	go func() {
+		task, err := GetTask(taskId)
+		if err != nil {
+			return
+		}
+		err = notificationService.Notify(&NotificationEvent{
+			Type:       models.NotificationRateLimited,
+			PipelineId: task.PipelineId,
+			TaskId:     task.ID,
+			Plugin:     task.Plugin,
+			Message:    fmt.Sprintf("requests to %s are paused for %s by the rate limit", endpoint, pause.Round(time.Second)),
+		})
+		if err != nil {
+			globalPipelineLog.Error(err, "failed to send rate limited notification for task #%d", taskId)
+		}
+	}()
+}
+
+// subtaskRetriedThreshold is the number of failed attempts of a subtask before SubtaskRetried is sent, 0 disables it
+var subtaskRetriedThreshold int
+
+// notifySubtaskRetried sends SubtaskRetried notification once a subtask failed `subtaskRetriedThreshold` times with
+// transient errors, so flaky data sources retried once in a while do not flood the channels
+func notifySubtaskRetried(_ context.Context, task *models.Task, subtask string, attempt int, subtaskErr errors.Error, backoff time.Duration) {
+	if notificationService == nil || !isSubtaskRetriedTooOften(attempt, subtaskRetriedThreshold) {
+		return
+	}
+	go func() {
+		err := notificationService.Notify(&NotificationEvent{
+			Type:       models.NotificationSubtaskRetried,
+			PipelineId: task.PipelineId,
+			TaskId:     task.ID,
+			Plugin:     task.Plugin,
+			Subtask:    subtask,
+			Message:    fmt.Sprintf("attempt %d failed with %s, retrying in %s", attempt, subtaskErr.Error(), backoff.Round(time.Second)),
+		})
+		if err != nil {
+			globalPipelineLog.Error(err, "failed to send subtask retried notification for task #%d", task.ID)
+		}
+	}()
+}
+
+// isSubtaskRetriedTooOften tells whether the failed attempt is the one reaching the threshold, later attempts are
+// not notified again
+func isSubtaskRetriedTooOften(attempt int, threshold int) bool {
+	return threshold > 0 && attempt == threshold
+}
@@ -0,0 +1,233 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/version"
+	"github.com/apache/incubator-devlake/helpers/dbhelper"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/google/uuid"
+)
+
+// In `WORKER_MODE`, multiple devlake instances share the same database and lease pipelines from it:
+//
+//  1. A worker claims a pending pipeline by setting `worker_id` and `lease_expires_at` while dequeuing
+//  2. The worker extends the leases of its running pipelines every `WORKER_HEARTBEAT_INTERVAL`, a pipeline
+//     whose lease could not be renewed was reclaimed by another worker and its run is aborted
+//  3. Any worker puts the RUNNING pipelines with expired lease back to the queue as TASK_RESUME, so the
+//     tasks/subtasks finished before the crash would be skipped when another worker picks it up
+//  4. The workers whose heartbeat stopped for `staleWorkerTtl` are removed from `_devlake_workers`
+//
+// NOTE: cancelling a pipeline only works on the instance running it, and blueprints should be scheduled
+// by one instance only, check `WORKER_SCHEDULE_BLUEPRINTS` for detail
+var workerMode bool
+var workerId string
+var workerLeaseTtl time.Duration
+var workerLog = logruslog.Global.Nested("worker")
+var worker *models.Worker
+
+const staleWorkerTtl = 24 * time.Hour
+
+// pipelineLease is a pipeline leased by the current worker, `lost` is set once its lease was taken over
+type pipelineLease struct {
+	cancel context.CancelFunc
+	lost   bool
+}
+
+var leasedPipelines = struct {
+	mu        sync.Mutex
+	pipelines map[uint64]*pipelineLease
+}{pipelines: make(map[uint64]*pipelineLease)}
+
+// initWorker registers the current instance to `_devlake_workers` and starts the heartbeat loop
+func initWorker() {
+	workerLeaseTtl = cfg.GetDuration("WORKER_LEASE_TTL")
+	heartbeatInterval := cfg.GetDuration("WORKER_HEARTBEAT_INTERVAL")
+	if heartbeatInterval <= 0 || workerLeaseTtl <= heartbeatInterval {
+		panic(errors.BadInput.New("WORKER_LEASE_TTL should be greater than WORKER_HEARTBEAT_INTERVAL"))
+	}
+	hostName := errors.Must1(os.Hostname())
+	workerId = fmt.Sprintf("%s-%d-%s", hostName, os.Getpid(), uuid.NewString()[:8])
+	worker = &models.Worker{
+		ID:          workerId,
+		HostName:    hostName,
+		Version:     version.Version,
+		HeartbeatAt: time.Now(),
+	}
+	errors.Must(db.Create(worker))
+	workerLog.Info("registered as worker %s", workerId)
+	// pipelines left behind by crashed workers could be resumed right away
+	reclaimExpiredPipelines()
+	go func() {
+		for range time.Tick(heartbeatInterval) {
+			workerHeartbeat()
+		}
+	}()
+}
+
+// newPipelineLease returns the columns to be set when the current worker claims or renews a pipeline
+func newPipelineLease() []dal.DalSet {
+	return []dal.DalSet{
+		{ColumnName: "worker_id", Value: workerId},
+		{ColumnName: "lease_expires_at", Value: time.Now().Add(workerLeaseTtl)},
+	}
+}
+
+// leasePipeline tracks the pipeline claimed by the current worker, the returned context is cancelled once the
+// lease is lost
+func leasePipeline(ctx context.Context, pipelineId uint64) context.Context {
+	if !workerMode {
+		return ctx
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	leasedPipelines.mu.Lock()
+	defer leasedPipelines.mu.Unlock()
+	leasedPipelines.pipelines[pipelineId] = &pipelineLease{cancel: cancel}
+	return ctx
+}
+
+// releasePipeline stops tracking the pipeline and returns whether the current worker still owns it, the result of
+// a pipeline whose lease was lost must not be written since another worker is running it
+func releasePipeline(pipelineId uint64) bool {
+	if !workerMode {
+		return true
+	}
+	leasedPipelines.mu.Lock()
+	defer leasedPipelines.mu.Unlock()
+	lease, ok := leasedPipelines.pipelines[pipelineId]
+	if !ok {
+		return true
+	}
+	delete(leasedPipelines.pipelines, pipelineId)
+	lease.cancel()
+	return !lease.lost
+}
+
+func workerHeartbeat() {
+	worker.HeartbeatAt = time.Now()
+	// the record is recreated if it was removed as stale, i.e. the process was suspended for long
+	err := db.CreateOrUpdate(worker)
+	if err != nil {
+		workerLog.Error(err, "failed to update heartbeat of worker %s", workerId)
+	}
+	renewPipelineLeases()
+	reclaimExpiredPipelines()
+	err = db.Delete(&models.Worker{}, dal.Where("heartbeat_at < ?", time.Now().Add(-staleWorkerTtl)))
+	if err != nil {
+		workerLog.Error(err, "failed to remove stale workers")
+	}
+}
+
+// renewPipelineLeases extends the leases of the pipelines running on the current worker, the pipelines could not
+// be renewed were reclaimed by other workers after their leases expired, their runs are aborted
+func renewPipelineLeases() {
+	leasedPipelines.mu.Lock()
+	defer leasedPipelines.mu.Unlock()
+	for pipelineId, lease := range leasedPipelines.pipelines {
+		if lease.lost {
+			continue
+		}
+		renewed, err := db.UpdateColumnsAffected(
+			&models.Pipeline{},
+			newPipelineLease(),
+			dal.Where("id = ? AND worker_id = ? AND status = ?", pipelineId, workerId, models.TASK_RUNNING),
+		)
+		if err != nil {
+			workerLog.Error(err, "failed to renew the lease of pipeline #%d", pipelineId)
+			continue
+		}
+		if renewed == 0 {
+			workerLog.Error(nil, "lost the lease of pipeline #%d, aborting", pipelineId)
+			lease.lost = true
+			lease.cancel()
+		}
+	}
+}
+
+// reclaimExpiredPipelines puts the RUNNING pipelines with expired (or no) lease back to the queue, the tables are
+// locked as dequeuePipeline does so a pipeline is never renewed or claimed while being reclaimed
+func reclaimExpiredPipelines() {
+	var reclaimed []uint64
+	err := func() (err errors.Error) {
+		txHelper := dbhelper.NewTxHelper(basicRes, &err)
+		defer txHelper.End()
+		tx := txHelper.Begin()
+		errors.Must(tx.LockTables(dal.LockTables{
+			{Table: "_devlake_pipelines", Exclusive: true},
+			{Table: "_devlake_tasks", Exclusive: true},
+		}))
+		expired := dal.Where(
+			"status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)",
+			models.TASK_RUNNING, time.Now(),
+		)
+		err = tx.Pluck("id", &reclaimed, dal.From(&models.Pipeline{}), expired)
+		if err != nil || len(reclaimed) == 0 {
+			return err
+		}
+		err = tx.UpdateColumns(
+			&models.Task{},
+			[]dal.DalSet{{ColumnName: "status", Value: models.TASK_RESUME}},
+			dal.Where("pipeline_id IN ? AND status = ?", reclaimed, models.TASK_RUNNING),
+		)
+		if err != nil {
+			return err
+		}
+		return tx.UpdateColumns(
+			&models.Pipeline{},
+			[]dal.DalSet{
+				{ColumnName: "status", Value: models.TASK_RESUME},
+				{ColumnName: "worker_id", Value: ""},
+				{ColumnName: "lease_expires_at", Value: nil},
+			},
+			dal.Where("id IN ?", reclaimed),
+			expired,
+		)
+	}()
+	if err != nil {
+		workerLog.Error(err, "failed to reclaim pipelines with expired lease")
+		return
+	}
+	if len(reclaimed) > 0 {
+		workerLog.Warn(nil, "pipelines %v lost their workers, requeued for resuming", reclaimed)
+	}
+}
+
+// getClusterParallelLabels returns the `parallel/` labels of pipelines running on all workers
+func getClusterParallelLabels(tx dal.Dal) ([]string, errors.Error) {
+	var labels []string
+	err := tx.Pluck(
+		"_devlake_pipeline_labels.name",
+		&labels,
+		dal.From("_devlake_pipeline_labels"),
+		dal.Join("JOIN _devlake_pipelines ON _devlake_pipelines.id = _devlake_pipeline_labels.pipeline_id"),
+		dal.Where(
+			"_devlake_pipelines.status = ? AND _devlake_pipeline_labels.name LIKE 'parallel/%'",
+			models.TASK_RUNNING,
+		),
+	)
+	return labels, err
+}
@@ -0,0 +1,119 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/helpers/unithelper"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupWorkerTest(t *testing.T) {
+	workerMode, workerId, workerLeaseTtl = true, "worker-1", time.Minute
+	t.Cleanup(func() {
+		workerMode, workerId, workerLeaseTtl = false, "", 0
+		db, basicRes = nil, nil
+	})
+}
+
+func TestRenewPipelineLeases(t *testing.T) {
+	setupWorkerTest(t)
+	mockDal := new(mockdal.Dal)
+	db = mockDal
+	mockDal.On("UpdateColumnsAffected", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ interface{}, _ []dal.DalSet, clauses ...dal.Clause) (int64, errors.Error) {
+			// pipeline 2 was reclaimed and claimed by another worker
+			if clauses[0].Data.(dal.DalClause).Params[0] == uint64(2) {
+				return 0, nil
+			}
+			return 1, nil
+		},
+	)
+
+	kept := leasePipeline(context.Background(), 1)
+	lost := leasePipeline(context.Background(), 2)
+	renewPipelineLeases()
+	assert.Nil(t, kept.Err())
+	assert.Equal(t, context.Canceled, lost.Err())
+	assert.True(t, releasePipeline(1))
+	assert.False(t, releasePipeline(2))
+	assert.Empty(t, leasedPipelines.pipelines)
+}
+
+func TestReclaimExpiredPipelines(t *testing.T) {
+	setupWorkerTest(t)
+	mockTx := new(mockdal.Transaction)
+	basicRes = unithelper.DummyBasicRes(func(mockDal *mockdal.Dal) {
+		mockDal.On("Begin").Return(mockTx)
+	})
+	mockTx.On("LockTables", dal.LockTables{
+		{Table: "_devlake_pipelines", Exclusive: true},
+		{Table: "_devlake_tasks", Exclusive: true},
+	}).Return(nil).Once()
+	mockTx.On("Pluck", "id", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(1).(*[]uint64) = []uint64{3, 5}
+	}).Return(nil).Once()
+	mockTx.On("UpdateColumns", &models.Task{}, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.Equal(t, []dal.DalSet{{ColumnName: "status", Value: models.TASK_RESUME}}, args.Get(1))
+		assert.Equal(t, dal.Where("pipeline_id IN ? AND status = ?", []uint64{3, 5}, models.TASK_RUNNING), args.Get(2).([]dal.Clause)[0])
+	}).Return(nil).Once()
+	mockTx.On("UpdateColumns", &models.Pipeline{}, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		clauses := args.Get(2).([]dal.Clause)
+		assert.Equal(t, dal.Where("id IN ?", []uint64{3, 5}), clauses[0])
+		// the lease is checked again in case it was renewed since the pipelines were found
+		assert.Equal(t, "status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)", clauses[1].Data.(dal.DalClause).Expr)
+	}).Return(nil).Once()
+	mockTx.On("UnlockTables").Return(nil).Once()
+	mockTx.On("Commit").Return(nil).Once()
+
+	reclaimExpiredPipelines()
+	mockTx.AssertExpectations(t)
+}
+
+func TestDequeuePipelineClaimedByAnotherWorker(t *testing.T) {
+	setupWorkerTest(t)
+	mockTx := new(mockdal.Transaction)
+	basicRes = unithelper.DummyBasicRes(func(mockDal *mockdal.Dal) {
+		mockDal.On("Begin").Return(mockTx)
+	})
+	mockTx.On("LockTables", mock.Anything).Return(nil).Once()
+	mockTx.On("Pluck", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	mockTx.On("First", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Pipeline).ID = 7
+	}).Return(nil).Once()
+	mockTx.On("UpdateColumnsAffected", &models.Pipeline{}, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		where := args.Get(2).([]dal.Clause)[0].Data.(dal.DalClause)
+		assert.Equal(t, "id = ? AND status IN ? AND (worker_id IS NULL OR worker_id = '')", where.Expr)
+		assert.Equal(t, uint64(7), where.Params[0])
+	}).Return(int64(0), nil).Once()
+	mockTx.On("UnlockTables").Return(nil).Once()
+	mockTx.On("Commit").Return(nil).Once()
+
+	pipeline, err := dequeuePipeline(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, pipeline)
+	mockTx.AssertExpectations(t)
+}